@@ -2,9 +2,14 @@ module github.com/NuevaNext/secrets-cli
 
 go 1.22.2
 
+require (
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/term v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.10.2 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/sys v0.22.0 // indirect
 )
@@ -0,0 +1,122 @@
+// Package cache stores short-lived decrypted secret values for read-heavy scripts.
+//
+// Entries are plain files with 0600 permissions in a per-user 0700 directory,
+// normally on a RAM-backed tmpfs so values never reach persistent storage.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Dir returns the per-user cache directory, preferring RAM-backed locations
+func Dir() string {
+	name := fmt.Sprintf("secrets-cli-%d", os.Getuid())
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "secrets-cli")
+	}
+	if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+		return filepath.Join("/dev/shm", name)
+	}
+	return filepath.Join(os.TempDir(), name)
+}
+
+// Key derives a cache key from the parts identifying a cached value
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cache is a directory of cached values
+type Cache struct {
+	Dir string
+}
+
+// Open prepares the cache directory. Unless allowDisk is set, it refuses
+// directories that are not on tmpfs.
+func Open(dir string, allowDisk bool) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat cache directory: %w", err)
+	}
+	if info.Mode().Perm() != 0700 {
+		return nil, fmt.Errorf("cache directory %s must have permissions 0700, has %o", dir, info.Mode().Perm())
+	}
+
+	if !allowDisk && !isTmpfs(dir) {
+		return nil, fmt.Errorf("refusing to cache secrets in %s because it is not on tmpfs (use --allow-disk-cache to override)", dir)
+	}
+
+	return &Cache{Dir: dir}, nil
+}
+
+// Get returns a cached value if present and younger than ttl.
+// Expired entries are removed.
+func (c *Cache) Get(key string, ttl time.Duration) (string, bool) {
+	path := filepath.Join(c.Dir, key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	if time.Since(info.ModTime()) > ttl {
+		os.Remove(path)
+		return "", false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Put stores a value, replacing any existing entry atomically
+func (c *Cache) Put(key, value string) error {
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(value); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(c.Dir, key))
+}
+
+// Purge removes every cached value in dir and returns how many were removed
+func Purge(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheGetPut(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c, err := Open(dir, true)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	key := Key("dev", "db/password", "fingerprint")
+	if _, ok := c.Get(key, time.Minute); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	if err := c.Put(key, "s3cret\n"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("cache entry permissions = %o, want 600", info.Mode().Perm())
+	}
+
+	if got, ok := c.Get(key, time.Minute); !ok || got != "s3cret\n" {
+		t.Errorf("Get() = %q, %v; want %q, true", got, ok, "s3cret\n")
+	}
+
+	// Age the entry past the TTL
+	old := time.Now().Add(-2 * time.Minute)
+	os.Chtimes(filepath.Join(dir, key), old, old)
+	if _, ok := c.Get(key, time.Minute); ok {
+		t.Error("expected expired entry to miss")
+	}
+	if _, err := os.Stat(filepath.Join(dir, key)); !os.IsNotExist(err) {
+		t.Error("expected expired entry to be removed")
+	}
+}
+
+func TestOpenRejectsLoosePermissions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, true); err == nil {
+		t.Error("expected Open to reject a 0755 directory")
+	}
+}
+
+func TestPurge(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c, err := Open(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put(Key("a"), "1")
+	c.Put(Key("b"), "2")
+
+	n, err := Purge(dir)
+	if err != nil || n != 2 {
+		t.Errorf("Purge() = %d, %v; want 2, nil", n, err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build linux
+
+package cache
+
+import "syscall"
+
+// tmpfsMagic is TMPFS_MAGIC from linux/magic.h
+const tmpfsMagic = 0x01021994
+
+// isTmpfs reports whether path lives on a RAM-backed tmpfs mount
+func isTmpfs(path string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false
+	}
+	return st.Type == tmpfsMagic
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package cache
+
+// isTmpfs cannot be determined portably, so other platforms must opt in to
+// disk caching explicitly
+func isTmpfs(path string) bool {
+	return false
+}
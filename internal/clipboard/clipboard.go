@@ -0,0 +1,97 @@
+// Package clipboard copies text to the system clipboard.
+//
+// Like the keychain package it shells out to the platform tools: pbcopy on
+// macOS, clip.exe on Windows and WSL, wl-copy on Wayland and xclip or xsel
+// on X11.
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tool is a clipboard command that reads the text to copy from stdin
+type tool struct {
+	name string
+	args []string
+}
+
+// Copy places text on the system clipboard. The text is passed on stdin so
+// it never appears in the process list.
+func Copy(text string) error {
+	t, err := detect(runtime.GOOS, os.Getenv, exec.LookPath)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(t.name, t.args...)
+	cmd.Stdin = strings.NewReader(text)
+	// xclip and xsel keep running to serve the selection, so their output
+	// must not be captured or Run would wait for them to exit
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", t.name, err)
+	}
+	return nil
+}
+
+// ClearAfter starts a background process that empties the clipboard once
+// the timeout has passed. It returns immediately.
+func ClearAfter(timeout time.Duration) error {
+	t, err := detect(runtime.GOOS, os.Getenv, exec.LookPath)
+	if err != nil {
+		return err
+	}
+
+	secs := strconv.Itoa(int(timeout.Round(time.Second) / time.Second))
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", "Start-Sleep -Seconds "+secs+"; Set-Clipboard -Value $null")
+	} else {
+		// The tool name and arguments come from detect, never from user input
+		cmd = exec.Command("sh", "-c", fmt.Sprintf("sleep %s && printf '' | %s", secs, strings.Join(append([]string{t.name}, t.args...), " ")))
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to schedule clipboard clearing: %w", err)
+	}
+	return cmd.Process.Release()
+}
+
+// detect picks the clipboard tool for the platform
+func detect(goos string, getenv func(string) string, lookPath func(string) (string, error)) (tool, error) {
+	var candidates []tool
+	switch goos {
+	case "darwin":
+		candidates = []tool{{name: "pbcopy"}}
+	case "windows":
+		candidates = []tool{{name: "clip.exe"}}
+	default:
+		if getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, tool{name: "wl-copy"})
+		}
+		candidates = append(candidates,
+			tool{name: "xclip", args: []string{"-selection", "clipboard"}},
+			tool{name: "xsel", args: []string{"--clipboard", "--input"}},
+			tool{name: "clip.exe"}, // WSL
+		)
+	}
+
+	for _, t := range candidates {
+		if _, err := lookPath(t.name); err == nil {
+			return t, nil
+		}
+	}
+
+	switch goos {
+	case "darwin":
+		return tool{}, fmt.Errorf("no clipboard tool found: pbcopy is missing from PATH")
+	case "windows":
+		return tool{}, fmt.Errorf("no clipboard tool found: clip.exe is missing from PATH")
+	default:
+		return tool{}, fmt.Errorf("no clipboard tool found. Install wl-clipboard (Wayland) or xclip or xsel (X11)")
+	}
+}
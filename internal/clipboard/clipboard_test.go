@@ -0,0 +1,52 @@
+package clipboard
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		wayland   string
+		installed []string
+		want      string
+		wantErr   bool
+	}{
+		{"macOS", "darwin", "", []string{"pbcopy"}, "pbcopy", false},
+		{"windows", "windows", "", []string{"clip.exe"}, "clip.exe", false},
+		{"wayland", "linux", "wayland-0", []string{"wl-copy", "xclip"}, "wl-copy", false},
+		{"x11 ignores wl-copy", "linux", "", []string{"wl-copy", "xclip"}, "xclip", false},
+		{"xsel fallback", "linux", "", []string{"xsel"}, "xsel", false},
+		{"wsl", "linux", "", []string{"clip.exe"}, "clip.exe", false},
+		{"nothing installed", "linux", "", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string {
+				if key == "WAYLAND_DISPLAY" {
+					return tt.wayland
+				}
+				return ""
+			}
+			lookPath := func(name string) (string, error) {
+				for _, installed := range tt.installed {
+					if installed == name {
+						return "/usr/bin/" + name, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+
+			got, err := detect(tt.goos, getenv, lookPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("detect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.name != tt.want {
+				t.Errorf("detect() = %q, want %q", got.name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
+	"github.com/NuevaNext/secrets-cli/internal/pass"
+)
+
+// adoption describes an existing pass store to register as a vault
+type adoption struct {
+	source     string
+	vault      string
+	gpgIDs     []string // the store's .gpg-id as found
+	members    []string // emails the IDs resolved to
+	unresolved []string // IDs without a key or email in the keyring
+	nested     []string // subdirectories with a .gpg-id of their own
+	secrets    int
+}
+
+// planAdoption reads an existing pass store and maps the recipients in its
+// .gpg-id to emails through the keyring. Nothing is written.
+func planAdoption(g *gpg.GPG, source, vaultName string) (*adoption, error) {
+	source, err := filepath.Abs(source)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(source); err != nil || !info.IsDir() {
+		return nil, notFoundErrorf("password store not found: %s", source)
+	}
+
+	if vaultName == "" {
+		vaultName = adoptedVaultName(source)
+	}
+	if err := validateName(vaultName); err != nil {
+		return nil, err
+	}
+
+	p := pass.New(source)
+	ids, err := p.GetGPGIDs()
+	if err != nil || len(ids) == 0 {
+		return nil, validationErrorf("%s has no .gpg-id; is it a pass store?", source)
+	}
+	secrets, err := p.List()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &adoption{source: source, vault: vaultName, gpgIDs: ids, secrets: len(secrets)}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		email := recipientEmail(g, id)
+		if email == "" {
+			a.unresolved = append(a.unresolved, id)
+			continue
+		}
+		if !seen[strings.ToLower(email)] {
+			seen[strings.ToLower(email)] = true
+			a.members = append(a.members, email)
+		}
+	}
+	if len(a.members) == 0 {
+		return nil, fmt.Errorf("none of the recipients in %s/.gpg-id could be mapped to an email. Import their public keys into your keyring first", source)
+	}
+
+	err = filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == ".gpg-id" && filepath.Dir(path) != source {
+			rel, _ := filepath.Rel(source, filepath.Dir(path))
+			a.nested = append(a.nested, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// adoptedVaultName derives a vault name from a store path, e.g.
+// ~/.password-store becomes password-store
+func adoptedVaultName(source string) string {
+	name := strings.TrimLeft(filepath.Base(source), ".")
+	if validateName(name) != nil {
+		return "default"
+	}
+	return name
+}
+
+// recipientEmail maps a .gpg-id entry (email, key ID or fingerprint) to the
+// email of its key in the keyring, or "" if there is none
+func recipientEmail(g *gpg.GPG, id string) string {
+	keys, err := g.LookupKeys(id)
+	if err != nil {
+		return ""
+	}
+	for _, k := range keys {
+		if k.Email != "" && validateEmail(k.Email) == nil {
+			return k.Email
+		}
+	}
+	return ""
+}
+
+// adoptStore copies an existing pass store into a new vault as it is,
+// without re-encrypting, and records its recipients as the members
+func adoptStore(g *gpg.GPG, secretsDir, email string, a *adoption) error {
+	vaultDir := config.GetVaultDir(secretsDir, a.vault)
+	storeDir := config.GetStoreDir(secretsDir, a.vault)
+
+	// A pass store's own git repository must not end up nested in ours
+	skipGit := func(rel string) bool { return filepath.Base(rel) == ".git" }
+	if err := copyTree(a.source, storeDir, skipGit); err != nil {
+		os.RemoveAll(vaultDir)
+		return fmt.Errorf("failed to copy %s: %w", a.source, err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	vaultCfg := &config.VaultConfig{
+		Version:     config.CurrentVaultConfigVersion,
+		Name:        a.vault,
+		Description: "Adopted from an existing pass store",
+		Owner:       email,
+		Members:     a.members,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+		os.RemoveAll(vaultDir)
+		return fmt.Errorf("failed to create vault config: %w", err)
+	}
+
+	// Teammates need the members' public keys to sync the vault
+	keysDir := config.GetKeysDir(secretsDir)
+	for _, member := range a.members {
+		keyPath := filepath.Join(keysDir, member+".asc")
+		if _, err := os.Stat(keyPath); err == nil {
+			continue
+		}
+		if err := g.ExportPublicKeyToFile(member, keyPath); err != nil {
+			fmt.Printf("⚠ Could not export the public key of %s: %v\n", member, err)
+		}
+	}
+
+	fmt.Printf("✓ Adopted %s as vault %s: %d secret(s), %d member(s), nothing re-encrypted\n", a.source, a.vault, a.secrets, len(a.members))
+	fmt.Printf("  The original store was copied and left untouched\n")
+	for _, id := range a.unresolved {
+		fmt.Printf("⚠ Recipient %s has no key with an email in your keyring and was not added as a member\n", id)
+		fmt.Printf("  Secrets stay encrypted for it until 'secrets-cli sync %s'\n", a.vault)
+	}
+	for _, path := range a.nested {
+		fmt.Printf("⚠ %s/ has its own .gpg-id. Record it with 'secrets-cli vault subvault %s %s --members ...' so verify expects its recipients\n", path, a.vault, path)
+	}
+	if !isVaultMember(vaultCfg, email) {
+		fmt.Printf("⚠ You (%s) are not a recipient of the adopted store, so you cannot read it or manage its members\n", email)
+	}
+	if len(a.unresolved) > 0 || !sameIDs(a.gpgIDs, a.members) {
+		fmt.Printf("  Run 'secrets-cli sync %s' to encrypt for exactly the members listed in vault.yaml\n", a.vault)
+	}
+	return nil
+}
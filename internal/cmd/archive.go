@@ -0,0 +1,484 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var vaultExportArchiveCmd = &cobra.Command{
+	Use:   "export-archive <vault>",
+	Short: "Back up a vault into a single archive file",
+	Long: `Back up a vault's configuration and encrypted password store into a
+single tar archive.
+
+Secrets inside the archive stay encrypted for the vault members, so the
+archive is a plain tar by default. Use --encrypt-to to wrap it in an
+additional GPG layer for one or more recipients.
+
+Examples:
+  secrets-cli vault export-archive dev --out dev.tar
+  secrets-cli vault export-archive prod --out prod.tar.gpg --encrypt-to backup@example.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVaultExportArchive,
+}
+
+var vaultImportArchiveCmd = &cobra.Command{
+	Use:   "import-archive <vault> <archive>",
+	Short: "Restore a vault from an archive file",
+	Long: `Restore a vault from an archive created by 'vault export-archive'.
+
+The archive is restored into a new vault, which must not already exist.
+GPG-encrypted archives are decrypted automatically. After restoring, the
+vault is synchronized so secrets are encrypted for its members' keys as
+known in this environment.
+
+Example:
+  secrets-cli vault import-archive dev-restored dev.tar`,
+	Args: cobra.ExactArgs(2),
+	RunE: runVaultImportArchive,
+}
+
+var vaultExportCmd = &cobra.Command{
+	Use:   "export <vault>",
+	Short: "Hand a vault off to recipients outside its membership",
+	Long: `Export a vault as a password store encrypted only for the given
+recipients, e.g. to hand it to an external consultant.
+
+Unlike export-archive, every secret is decrypted and re-encrypted solely
+for the --encrypt-to recipients, so current members cannot read the bundle
+and the recipients can read it without access to this repository.
+Plaintext is only held in memory and is never written to disk.
+
+The bundle is a tar archive containing <vault>/.gpg-id and the encrypted
+secrets. The recipient can read it with pass:
+
+  tar xf bundle.tar && PASSWORD_STORE_DIR=$PWD/<vault> pass show <secret>
+
+Because this changes who can read the secrets, --force is required.
+
+Example:
+  secrets-cli vault export dev --encrypt-to consultant@example.com --out dev-handoff.tar --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVaultExport,
+}
+
+var (
+	archiveOut       string
+	archiveEncryptTo []string
+	handoffForce     bool
+)
+
+func init() {
+	vaultCmd.AddCommand(vaultExportArchiveCmd)
+	vaultCmd.AddCommand(vaultImportArchiveCmd)
+	vaultCmd.AddCommand(vaultExportCmd)
+
+	vaultExportCmd.Flags().StringVarP(&archiveOut, "out", "o", "", "Path of the bundle file to write")
+	vaultExportCmd.Flags().StringSliceVar(&archiveEncryptTo, "encrypt-to", nil, "Recipient to re-encrypt every secret for (repeatable)")
+	vaultExportCmd.Flags().BoolVarP(&handoffForce, "force", "f", false, "Confirm re-encrypting the vault for the given recipients")
+	_ = vaultExportCmd.MarkFlagRequired("out")
+	_ = vaultExportCmd.MarkFlagRequired("encrypt-to")
+
+	vaultExportArchiveCmd.Flags().StringVarP(&archiveOut, "out", "o", "", "Path of the archive file to write")
+	vaultExportArchiveCmd.Flags().StringSliceVar(&archiveEncryptTo, "encrypt-to", nil, "Add an outer GPG layer for this recipient (repeatable)")
+	_ = vaultExportArchiveCmd.MarkFlagRequired("out")
+}
+
+func runVaultExportArchive(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName := args[0]
+
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+	if usesSharedStore(secretsDir) {
+		return fmt.Errorf("vault archives are not supported with the shared store layout. Run 'secrets-cli migrate-layout per-vault' first")
+	}
+
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+
+	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
+		return accessDeniedErrorf("Access denied: you are not a member of vault %s", vaultName)
+	}
+
+	data, err := buildVaultArchive(vaultDir)
+	if err != nil {
+		return fmt.Errorf("failed to build archive: %w", err)
+	}
+
+	if len(archiveEncryptTo) > 0 {
+		g := newGPG()
+		if err := g.EncryptToFile(data, archiveOut, archiveEncryptTo); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(archiveOut, data, 0600); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	fmt.Printf("✓ Exported vault %s to %s\n", vaultName, archiveOut)
+	if len(archiveEncryptTo) > 0 {
+		fmt.Printf("  Encrypted for: %s\n", strings.Join(archiveEncryptTo, ", "))
+	}
+
+	return nil
+}
+
+func runVaultImportArchive(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	vaultName := args[0]
+	archivePath := args[1]
+
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+	if usesSharedStore(secretsDir) {
+		return fmt.Errorf("vault archives are not supported with the shared store layout. Run 'secrets-cli migrate-layout per-vault' first")
+	}
+
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); !os.IsNotExist(err) {
+		return fmt.Errorf("vault already exists: %s", vaultName)
+	}
+
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	g := newGPG()
+	if !isTarArchive(data) {
+		// Not a plain tar, so it must carry an outer GPG layer
+		data, err = g.Decrypt(archivePath)
+		if err != nil {
+			return err
+		}
+		if !isTarArchive(data) {
+			return fmt.Errorf("%s is not a vault archive", archivePath)
+		}
+	}
+
+	if err := os.MkdirAll(vaultDir, 0755); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	var vaultCfg *config.VaultConfig
+	err = config.WithVaultLock(vaultDir, func() error {
+		if err := extractVaultArchive(data, vaultDir); err != nil {
+			os.RemoveAll(vaultDir)
+			return fmt.Errorf("failed to extract archive: %w", err)
+		}
+
+		vaultCfg, err = config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			os.RemoveAll(vaultDir)
+			return fmt.Errorf("archive does not contain a valid vault config: %w", err)
+		}
+		// The archive is untrusted: its names become key file paths below
+		if err := validateArchivedVaultConfig(vaultCfg); err != nil {
+			os.RemoveAll(vaultDir)
+			return err
+		}
+
+		vaultCfg.Name = vaultName
+		vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to save vault config: %w", err)
+		}
+
+		fmt.Printf("✓ Restored vault %s from %s\n", vaultName, archivePath)
+
+		// Import members' keys so the sync below can encrypt for them
+		keysDir := config.GetKeysDir(secretsDir)
+		for _, member := range vaultCfg.Members {
+			keyPath := filepath.Join(keysDir, member+".asc")
+			if _, err := os.Stat(keyPath); err == nil {
+				_ = g.ImportKey(keyPath)
+			}
+		}
+
+		if err := reencryptVault(newPass(config.GetStoreDir(secretsDir, vaultName)), secretsDir, vaultCfg); err != nil {
+			return fmt.Errorf("vault restored but sync failed: %w\nFix the issue and run: secrets-cli sync %s", err, vaultName)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	p := newPass(config.GetStoreDir(secretsDir, vaultName))
+	fmt.Printf("✓ Synchronized %d secret(s) for %d member(s)\n", countListed(p), len(vaultCfg.Members))
+	recordChange("restore vault %s", vaultName)
+	return nil
+}
+
+// validateArchivedVaultConfig checks every identity named by a vault config
+// read from an archive, rejecting the archive if any is malformed
+func validateArchivedVaultConfig(vaultCfg *config.VaultConfig) error {
+	if vaultCfg.Owner != "" {
+		if err := validateEmail(vaultCfg.Owner); err != nil {
+			return validationErrorf("archive has an invalid owner: %v", err)
+		}
+	}
+	for _, member := range vaultCfg.Members {
+		if err := validateEmail(member); err != nil {
+			return validationErrorf("archive has an invalid member: %v", err)
+		}
+	}
+	for path, members := range vaultCfg.Subvaults {
+		for _, member := range members {
+			if err := validateEmail(member); err != nil {
+				return validationErrorf("archive has an invalid member of subvault %s: %v", path, err)
+			}
+		}
+	}
+	for _, extra := range vaultCfg.RecoveryKeys {
+		if err := validateRecipient(extra); err != nil {
+			return validationErrorf("archive has an invalid recovery key: %v", err)
+		}
+	}
+	return nil
+}
+
+func runVaultExport(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName := args[0]
+
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+	for _, r := range archiveEncryptTo {
+		if err := validateName(r); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+
+	if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	if !handoffForce {
+		return fmt.Errorf("this re-encrypts every secret in %s for %s, who will be able to read all of them. Use --force to confirm",
+			vaultName, strings.Join(archiveEncryptTo, ", "))
+	}
+
+	// Make recipients' stored keys available to gpg
+	g := newGPG()
+	keysDir := config.GetKeysDir(secretsDir)
+	for _, r := range archiveEncryptTo {
+		keyPath := filepath.Join(keysDir, r+".asc")
+		if _, err := os.Stat(keyPath); err == nil {
+			_ = g.ImportKey(keyPath)
+		}
+	}
+
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	p := newPass(storeDir)
+	secrets, err := p.List()
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	gpgID := strings.Join(archiveEncryptTo, "\n") + "\n"
+	if err := writeTarFile(tw, vaultName+"/.gpg-id", []byte(gpgID)); err != nil {
+		return fmt.Errorf("failed to build bundle: %w", err)
+	}
+	for _, secret := range secrets {
+		// Re-encrypt the exact plaintext, including any trailing whitespace
+		// or binary content that Show would alter
+		plaintext, err := g.Decrypt(filepath.Join(storeDir, filepath.FromSlash(secret)+".gpg"))
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", secret, err)
+		}
+		ciphertext, err := g.Encrypt(plaintext, archiveEncryptTo)
+		if err != nil {
+			return fmt.Errorf("failed to re-encrypt %s: %w", secret, err)
+		}
+		if err := writeTarFile(tw, vaultName+"/"+secret+".gpg", ciphertext); err != nil {
+			return fmt.Errorf("failed to build bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to build bundle: %w", err)
+	}
+
+	if err := os.WriteFile(archiveOut, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("✓ Exported %d secret(s) from %s to %s\n", len(secrets), vaultName, archiveOut)
+	fmt.Printf("  Encrypted only for: %s\n", strings.Join(archiveEncryptTo, ", "))
+	return nil
+}
+
+// writeTarFile adds a single regular file entry to a tar archive
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// buildVaultArchive tars a vault's config and password store
+func buildVaultArchive(vaultDir string) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	err := filepath.WalkDir(vaultDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(vaultDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if !isArchiveEntry(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			// Symlinks and special files are never archived
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(tw, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// extractVaultArchive unpacks a vault archive into vaultDir, rejecting any
+// entry that would land outside the vault's config or password store
+func extractVaultArchive(data []byte, vaultDir string) error {
+	tr := tar.NewReader(bytes.NewReader(data))
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(hdr.Name, "/")
+		if name == "" || strings.Contains(name, "..") || strings.HasPrefix(name, "/") || !isArchiveEntry(name) {
+			return fmt.Errorf("unexpected archive entry: %s", hdr.Name)
+		}
+		target := filepath.Join(vaultDir, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported archive entry type for %s", hdr.Name)
+		}
+	}
+}
+
+// isArchiveEntry reports whether a slash-separated path relative to the vault
+// directory belongs in a vault archive
+func isArchiveEntry(rel string) bool {
+	return rel == "vault.yaml" || rel == ".password-store" || strings.HasPrefix(rel, ".password-store/")
+}
+
+// isTarArchive checks for the ustar magic in the first tar header
+func isTarArchive(data []byte) bool {
+	return len(data) > 262 && string(data[257:262]) == "ustar"
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+)
+
+func TestVaultArchiveRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	storeDir := filepath.Join(src, ".password-store")
+	if err := os.MkdirAll(filepath.Join(storeDir, "db"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"vault.yaml":                      "name: dev\n",
+		".password-store/.gpg-id":         "alice@example.com\n",
+		".password-store/db/password.gpg": "ciphertext",
+		"notes.txt":                       "not part of the vault",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := buildVaultArchive(src)
+	if err != nil {
+		t.Fatalf("buildVaultArchive() error = %v", err)
+	}
+	if !isTarArchive(data) {
+		t.Fatal("expected archive to be detected as tar")
+	}
+
+	dst := t.TempDir()
+	if err := extractVaultArchive(data, dst); err != nil {
+		t.Fatalf("extractVaultArchive() error = %v", err)
+	}
+
+	for name, content := range files {
+		got, err := os.ReadFile(filepath.Join(dst, name))
+		if name == "notes.txt" {
+			if err == nil {
+				t.Errorf("%s should not be archived", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("missing %s after extract: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestExtractVaultArchiveRejectsTraversal(t *testing.T) {
+	for _, name := range []string{"../evil", ".password-store/../../evil", "/etc/passwd", "other/file"} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			tw.Write([]byte("x"))
+			tw.Close()
+
+			if err := extractVaultArchive(buf.Bytes(), t.TempDir()); err == nil {
+				t.Errorf("expected entry %q to be rejected", name)
+			}
+		})
+	}
+}
+
+func TestValidateArchivedVaultConfig(t *testing.T) {
+	valid := func() *config.VaultConfig {
+		return &config.VaultConfig{
+			Owner:        "alice@example.com",
+			Members:      []string{"alice@example.com", "bob@example.com"},
+			RecoveryKeys: []string{"escrow@example.com"},
+			Subvaults:    map[string][]string{"prod": {"alice@example.com"}},
+		}
+	}
+	if err := validateArchivedVaultConfig(valid()); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+
+	tests := map[string]func(*config.VaultConfig){
+		"Owner":          func(c *config.VaultConfig) { c.Owner = "../../owner" },
+		"Member":         func(c *config.VaultConfig) { c.Members = append(c.Members, "../../../tmp/evil") },
+		"SubvaultMember": func(c *config.VaultConfig) { c.Subvaults["prod"] = []string{"not-an-email"} },
+		"RecoveryKey":    func(c *config.VaultConfig) { c.RecoveryKeys = []string{"../escrow"} },
+		"RecoveryKeyID":  func(c *config.VaultConfig) { c.RecoveryKeys = []string{"escrow"} },
+	}
+	for name, corrupt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid()
+			corrupt(cfg)
+			if err := validateArchivedVaultConfig(cfg); err == nil {
+				t.Error("expected invalid archive config to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Report who has access to which vaults",
+	Long: `Report every member's access across all vaults, for access reviews.
+
+By default a matrix of members vs vaults is printed, along with the number
+of secrets in each vault; read-only members are marked "r" instead of "✓".
+Backup recipients, which every vault is encrypted for, are listed too.
+Use --by-member for a per-member list instead, or --format json to feed
+the report into a spreadsheet or script.
+
+The report also flags inconsistencies:
+  unused key       a key in keys/ that is not a member or recovery key of any
+                   vault, nor a backup recipient
+  missing key      a vault member without a keys/<email>.asc file
+  not recipient    a member missing from the vault's .gpg-id
+  stale recipient  a .gpg-id entry that is no longer a member
+
+Members without a key file make re-encryption fail. Use --fix to see how
+the inconsistencies would be repaired: such members are removed and each
+affected vault is re-synchronized. A vault's owner is never removed; add
+their key or transfer ownership first. Add --force to apply the fixes.
+
+Examples:
+  secrets-cli audit
+  secrets-cli audit --by-member
+  secrets-cli audit --format json
+  secrets-cli audit --fix --force`,
+	Args: cobra.NoArgs,
+	RunE: runAudit,
+}
+
+var (
+	auditByMember bool
+	auditFormat   string
+	auditFix      bool
+	auditForce    bool
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().BoolVar(&auditByMember, "by-member", false, "List vaults per member instead of a matrix")
+	auditCmd.Flags().StringVar(&auditFormat, "format", "text", "Output format: text, json")
+	auditCmd.Flags().BoolVar(&auditFix, "fix", false, "Show how to repair inconsistencies (remove members without keys and re-sync)")
+	auditCmd.Flags().BoolVarP(&auditForce, "force", "f", false, "Apply the fixes shown by --fix")
+}
+
+// auditVault is one vault's entry in an audit report
+type auditVault struct {
+	Name         string   `json:"name"`
+	Secrets      int      `json:"secrets"`
+	Members      []string `json:"members"`
+	ReadOnly     []string `json:"read_only,omitempty"`
+	RecoveryKeys []string `json:"recovery_keys,omitempty"`
+}
+
+// auditMember is one member's entry in an audit report
+type auditMember struct {
+	Email  string   `json:"email"`
+	Vaults []string `json:"vaults"`
+	HasKey bool     `json:"has_key"`
+}
+
+// auditReport is the full access report across all vaults
+type auditReport struct {
+	Vaults           []auditVault        `json:"vaults"`
+	Members          []auditMember       `json:"members"`
+	BackupRecipients []string            `json:"backup_recipients,omitempty"`
+	UnusedKeys       []string            `json:"unused_keys"`
+	MissingKeys      []string            `json:"missing_keys"`
+	VaultIssues      []config.VaultIssue `json:"vault_issues"`
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+
+	if auditFormat != "text" && auditFormat != "json" {
+		return fmt.Errorf("unknown format: %s (use text or json)", auditFormat)
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	vaultNames, err := config.ListVaults(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	vaults := []auditVault{}
+	for _, name := range vaultNames {
+		vaultDir := config.GetVaultDir(secretsDir, name)
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config for %s: %w", name, err)
+		}
+		var readOnly []string
+		for _, member := range vaultCfg.Members {
+			if vaultCfg.MemberRole(member) == config.RoleRead {
+				readOnly = append(readOnly, member)
+			}
+		}
+		vaults = append(vaults, auditVault{
+			Name:         name,
+			Secrets:      countSecrets(config.GetStoreDir(secretsDir, name)),
+			Members:      nonNil(vaultCfg.Members),
+			ReadOnly:     readOnly,
+			RecoveryKeys: vaultCfg.RecoveryKeys,
+		})
+	}
+
+	var keys []string
+	entries, _ := os.ReadDir(config.GetKeysDir(secretsDir))
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".asc" {
+			keys = append(keys, strings.TrimSuffix(entry.Name(), ".asc"))
+		}
+	}
+
+	var backupRecipients []string
+	if cfg, err := config.LoadConfig(secretsDir); err == nil {
+		backupRecipients = cfg.BackupRecipients
+	}
+
+	report := buildAuditReport(vaults, keys, backupRecipients)
+	report.VaultIssues = []config.VaultIssue{}
+	for _, v := range vaults {
+		issues, err := config.ValidateVault(secretsDir, v.Name)
+		if err != nil {
+			return fmt.Errorf("failed to validate vault %s: %w", v.Name, err)
+		}
+		report.VaultIssues = append(report.VaultIssues, issues...)
+	}
+
+	if auditFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(report.Vaults) == 0 {
+		fmt.Println("No vaults found. Create one with: secrets-cli vault create <name>")
+		return nil
+	}
+
+	fmt.Println("Vaults:")
+	for _, v := range report.Vaults {
+		fmt.Printf("  %s: %d secret(s), %d member(s)\n", v.Name, v.Secrets, len(v.Members))
+	}
+	fmt.Println()
+
+	if len(report.BackupRecipients) > 0 {
+		fmt.Println("Backup recipients (every vault):")
+		for _, email := range report.BackupRecipients {
+			fmt.Printf("  %s\n", email)
+		}
+		fmt.Println()
+	}
+
+	if auditByMember {
+		fmt.Println("Members:")
+		for _, m := range report.Members {
+			fmt.Printf("  %s\n", m.Email)
+			for _, v := range m.Vaults {
+				fmt.Printf("    - %s\n", v)
+			}
+		}
+	} else {
+		fmt.Println("Access:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		header := []string{"  "}
+		for _, v := range report.Vaults {
+			header = append(header, v.Name)
+		}
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, m := range report.Members {
+			row := []string{"  " + m.Email}
+			for _, v := range report.Vaults {
+				cell := "-"
+				for _, mv := range m.Vaults {
+					if mv == v.Name {
+						cell = "✓"
+						break
+					}
+				}
+				for _, ro := range v.ReadOnly {
+					if cell == "✓" && strings.EqualFold(ro, m.Email) {
+						cell = "r"
+						break
+					}
+				}
+				row = append(row, cell)
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		w.Flush()
+	}
+
+	if len(report.UnusedKeys) > 0 || len(report.MissingKeys) > 0 || len(report.VaultIssues) > 0 {
+		fmt.Println()
+		fmt.Println("Issues:")
+		for _, email := range report.MissingKeys {
+			fmt.Printf("  ✗ missing key: %s is a member but keys/%s.asc does not exist\n", email, email)
+		}
+		for _, email := range report.UnusedKeys {
+			fmt.Printf("  - unused key: %s is not a member of any vault\n", email)
+		}
+		for _, issue := range report.VaultIssues {
+			if issue.Kind == config.IssueMissingKey {
+				continue // already listed above
+			}
+			fmt.Printf("  ✗ %s: %s\n", strings.ReplaceAll(issue.Kind, "_", " "), issue.Message)
+		}
+	}
+
+	if auditFix {
+		return fixVaultIssues(secretsDir, report.VaultIssues)
+	}
+
+	return nil
+}
+
+// fixVaultIssues removes members that have no key file and re-synchronizes
+// every vault with issues. Without --force it only prints the plan.
+func fixVaultIssues(secretsDir string, issues []config.VaultIssue) error {
+	email := GetUserEmail()
+
+	var vaults []string
+	remove := map[string][]string{}
+	for _, issue := range issues {
+		if _, seen := remove[issue.Vault]; !seen {
+			vaults = append(vaults, issue.Vault)
+			remove[issue.Vault] = nil
+		}
+		if issue.Kind == config.IssueMissingKey {
+			remove[issue.Vault] = append(remove[issue.Vault], issue.Email)
+		}
+	}
+
+	fmt.Println()
+	if len(vaults) == 0 {
+		fmt.Println("✓ Nothing to fix")
+		return nil
+	}
+
+	fmt.Println("Fixes:")
+	for _, vaultName := range vaults {
+		vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+		if err == nil && len(remove[vaultName]) >= len(vaultCfg.Members) {
+			fmt.Printf("  ✗ %s: every member lacks a key file; add keys with 'secrets-cli key add' instead\n", vaultName)
+			continue
+		}
+		// The owner is never removed here, see 'vault remove-member'
+		if err == nil && ownerLacksKey(vaultCfg, remove[vaultName]) {
+			fmt.Printf("  ✗ %s: owner %s lacks a key file; add it with 'secrets-cli key add %s' or hand the vault over with 'secrets-cli vault transfer-ownership %s <email>'\n", vaultName, vaultCfg.Owner, vaultCfg.Owner, vaultName)
+			continue
+		}
+		for _, member := range remove[vaultName] {
+			fmt.Printf("  - remove %s from %s\n", member, vaultName)
+		}
+		fmt.Printf("  - re-sync %s\n", vaultName)
+	}
+
+	if !auditForce {
+		fmt.Println()
+		fmt.Println("Use --force to apply these fixes")
+		return nil
+	}
+
+	fmt.Println()
+	g := newGPG()
+	keysDir := config.GetKeysDir(secretsDir)
+	var failed []string
+	for _, vaultName := range vaults {
+		if !hasWriteAccess(secretsDir, vaultName, email) {
+			fmt.Printf("✗ Skipped %s: you are not a read-write member\n", vaultName)
+			failed = append(failed, vaultName)
+			continue
+		}
+
+		vaultDir := config.GetVaultDir(secretsDir, vaultName)
+		err := config.WithVaultLock(vaultDir, func() error {
+			vaultCfg, err := config.LoadVaultConfig(vaultDir)
+			if err != nil {
+				return fmt.Errorf("failed to load vault config: %w", err)
+			}
+			if ownerLacksKey(vaultCfg, remove[vaultName]) {
+				return fmt.Errorf("owner %s has no key file and cannot be removed", vaultCfg.Owner)
+			}
+
+			var members []string
+			for _, member := range vaultCfg.Members {
+				keep := true
+				for _, r := range remove[vaultName] {
+					if strings.EqualFold(member, r) {
+						keep = false
+						break
+					}
+				}
+				if keep {
+					members = append(members, member)
+				}
+			}
+			if len(members) == 0 {
+				return fmt.Errorf("no members with keys would remain")
+			}
+			vaultCfg.Members = members
+			if emptied := vaultCfg.PruneSubvaults(); len(emptied) > 0 {
+				return fmt.Errorf("no members with keys would remain in subvault %s", strings.Join(emptied, ", "))
+			}
+
+			for _, recipient := range vaultRecipients(secretsDir, vaultCfg) {
+				keyPath := filepath.Join(keysDir, recipient+".asc")
+				if _, err := os.Stat(keyPath); err == nil {
+					_ = g.ImportKey(keyPath)
+				}
+			}
+
+			p := newPass(config.GetStoreDir(secretsDir, vaultName))
+			if err := reencryptVault(p, secretsDir, vaultCfg); err != nil {
+				return fmt.Errorf("failed to re-encrypt secrets: %w", err)
+			}
+
+			vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+			return config.SaveVaultConfig(vaultDir, vaultCfg)
+		})
+		if err != nil {
+			fmt.Printf("✗ Failed to fix %s: %v\n", vaultName, err)
+			failed = append(failed, vaultName)
+			continue
+		}
+		fmt.Printf("✓ Fixed vault: %s\n", vaultName)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not fix %d vault(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// ownerLacksKey reports whether the vault's owner is among the members
+// without a key file, which audit --fix must not remove
+func ownerLacksKey(vaultCfg *config.VaultConfig, missing []string) bool {
+	for _, member := range missing {
+		if vaultCfg.Owner != "" && strings.EqualFold(member, vaultCfg.Owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildAuditReport groups vault membership by member and flags key files
+// that no vault or backup recipient uses and members that have no key file.
+// Emails are compared case-insensitively; members keep the spelling of their
+// first occurrence.
+func buildAuditReport(vaults []auditVault, keys, backupRecipients []string) auditReport {
+	report := auditReport{
+		Vaults:           vaults,
+		Members:          []auditMember{},
+		BackupRecipients: backupRecipients,
+		UnusedKeys:       []string{},
+		MissingKeys:      []string{},
+	}
+
+	hasKey := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		hasKey[strings.ToLower(k)] = true
+	}
+
+	byEmail := map[string]*auditMember{}
+	used := map[string]bool{}
+	for _, v := range vaults {
+		for _, email := range v.Members {
+			lower := strings.ToLower(email)
+			m, ok := byEmail[lower]
+			if !ok {
+				m = &auditMember{Email: email, Vaults: []string{}, HasKey: hasKey[lower]}
+				byEmail[lower] = m
+			}
+			m.Vaults = append(m.Vaults, v.Name)
+			used[lower] = true
+		}
+		for _, email := range v.RecoveryKeys {
+			used[strings.ToLower(email)] = true
+		}
+	}
+	for _, email := range backupRecipients {
+		used[strings.ToLower(email)] = true
+	}
+
+	for _, m := range byEmail {
+		report.Members = append(report.Members, *m)
+		if !m.HasKey {
+			report.MissingKeys = append(report.MissingKeys, m.Email)
+		}
+	}
+	sort.Slice(report.Members, func(i, j int) bool { return report.Members[i].Email < report.Members[j].Email })
+	sort.Strings(report.MissingKeys)
+
+	for _, k := range keys {
+		if !used[strings.ToLower(k)] {
+			report.UnusedKeys = append(report.UnusedKeys, k)
+		}
+	}
+	sort.Strings(report.UnusedKeys)
+
+	return report
+}
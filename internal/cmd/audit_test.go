@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+)
+
+func TestBuildAuditReport(t *testing.T) {
+	vaults := []auditVault{
+		{Name: "dev", Secrets: 3, Members: []string{"alice@example.com", "bob@example.com"}},
+		{Name: "prod", Secrets: 1, Members: []string{"Alice@example.com", "carol@example.com"}, RecoveryKeys: []string{"escrow@example.com"}},
+	}
+	keys := []string{"alice@example.com", "bob@example.com", "dave@example.com", "escrow@example.com", "offline@example.com"}
+
+	report := buildAuditReport(vaults, keys, []string{"Offline@example.com"})
+
+	wantMembers := []auditMember{
+		{Email: "alice@example.com", Vaults: []string{"dev", "prod"}, HasKey: true},
+		{Email: "bob@example.com", Vaults: []string{"dev"}, HasKey: true},
+		{Email: "carol@example.com", Vaults: []string{"prod"}, HasKey: false},
+	}
+	if !reflect.DeepEqual(report.Members, wantMembers) {
+		t.Errorf("Members = %+v, want %+v", report.Members, wantMembers)
+	}
+	if want := []string{"carol@example.com"}; !reflect.DeepEqual(report.MissingKeys, want) {
+		t.Errorf("MissingKeys = %v, want %v", report.MissingKeys, want)
+	}
+	if want := []string{"dave@example.com"}; !reflect.DeepEqual(report.UnusedKeys, want) {
+		t.Errorf("UnusedKeys = %v, want %v", report.UnusedKeys, want)
+	}
+	if want := []string{"Offline@example.com"}; !reflect.DeepEqual(report.BackupRecipients, want) {
+		t.Errorf("BackupRecipients = %v, want %v", report.BackupRecipients, want)
+	}
+}
+
+func TestOwnerLacksKey(t *testing.T) {
+	vaultCfg := &config.VaultConfig{Owner: "Alice@example.com", Members: []string{"alice@example.com", "bob@example.com"}}
+	if !ownerLacksKey(vaultCfg, []string{"bob@example.com", "alice@example.com"}) {
+		t.Error("owner without a key file should be detected")
+	}
+	if ownerLacksKey(vaultCfg, []string{"bob@example.com"}) {
+		t.Error("only bob lacks a key file")
+	}
+	if ownerLacksKey(&config.VaultConfig{Members: vaultCfg.Members}, []string{"alice@example.com"}) {
+		t.Error("a vault without an owner has no owner to protect")
+	}
+}
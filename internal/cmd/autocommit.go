@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoCommit bool
+	autoPush   bool
+
+	// changes describes what the running command changed, for the commit
+	// message of --commit
+	changes []string
+)
+
+// recordChange notes a successful change for the commit message, e.g.
+// recordChange("set %s/%s", vault, secret). Never pass secret values.
+func recordChange(format string, args ...interface{}) {
+	changes = append(changes, fmt.Sprintf(format, args...))
+}
+
+// commitMessage returns the commit message for the recorded changes
+func commitMessage(changes []string) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	return "secrets: " + strings.Join(changes, ", ")
+}
+
+// commitRequested reports whether changes are committed, and pushed, after
+// the command: the --commit and --push flags win over auto_commit and
+// auto_push in config.yaml
+func commitRequested(cmd *cobra.Command, secretsDir string) (commit, push bool) {
+	commit, push = autoCommit, autoPush
+	flags := cmd.Flags()
+	if !flags.Changed("commit") || !flags.Changed("push") {
+		if cfg, err := config.LoadConfig(secretsDir); err == nil {
+			if !flags.Changed("commit") {
+				commit = cfg.AutoCommit
+			}
+			if !flags.Changed("push") {
+				push = cfg.AutoPush
+			}
+		}
+	}
+	return commit || push, push
+}
+
+// commitAfterRun commits the recorded changes once a command succeeded
+func commitAfterRun(cmd *cobra.Command, args []string) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	secretsDir := GetSecretsDir()
+	commit, push := commitRequested(cmd, secretsDir)
+	if !commit {
+		return nil
+	}
+	if err := commitSecrets(secretsDir, commitMessage(changes), push); err != nil {
+		return fmt.Errorf("the change was made but not committed: %w", err)
+	}
+	return nil
+}
+
+// commitSecrets stages the secrets directory and commits only it, leaving
+// anything else already staged alone. Nothing happens if it has no changes.
+func commitSecrets(secretsDir, message string, push bool) error {
+	abs, err := filepath.Abs(secretsDir)
+	if err != nil {
+		return err
+	}
+	git := func(args ...string) error {
+		var stderr bytes.Buffer
+		cmd := exec.Command("git", append([]string{"-C", abs}, args...)...)
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("git %s: %s", args[0], msg)
+			}
+			return fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(abs); err != nil {
+		return err
+	}
+	if err := git("rev-parse", "--is-inside-work-tree"); err != nil {
+		return fmt.Errorf("%s is not in a git repository; run 'git init' or drop --commit", secretsDir)
+	}
+
+	// Stores created before lock files were ignored get the rule now
+	if err := config.EnsureLockIgnored(abs); err != nil {
+		return fmt.Errorf("failed to update %s: %w", filepath.Join(secretsDir, ".gitignore"), err)
+	}
+	// Everything under the secrets directory is staged, so a decrypted file
+	// left in a store would be committed in the clear
+	plaintext, err := plaintextInStores(abs)
+	if err != nil {
+		return err
+	}
+	if len(plaintext) > 0 {
+		return fmt.Errorf("refusing to commit unencrypted files: %s; remove them or run 'secrets-cli verify'", strings.Join(plaintext, ", "))
+	}
+	if err := git("add", "--all", "--", "."); err != nil {
+		return err
+	}
+	// git diff exits 1 when there are staged changes
+	err = exec.Command("git", "-C", abs, "diff", "--cached", "--quiet", "--", ".").Run()
+	if err == nil {
+		fmt.Println("Nothing to commit")
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		return fmt.Errorf("git diff: %w", err)
+	}
+
+	if err := git("commit", "--quiet", "-m", message, "--", "."); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Committed: %s\n", message)
+
+	if push {
+		if err := git("push", "--quiet"); err != nil {
+			return err
+		}
+		fmt.Println("✓ Pushed")
+	}
+	return nil
+}
+
+// plaintextInStores lists the unencrypted files in the password stores of
+// a secrets directory, relative to it
+func plaintextInStores(secretsDir string) ([]string, error) {
+	var stores []string
+	if cfg, err := config.LoadConfig(secretsDir); err == nil && cfg.StoreLayout == config.StoreLayoutShared {
+		stores = append(stores, config.SharedStoreDir(secretsDir, cfg.SharedStore))
+	} else {
+		matches, err := filepath.Glob(filepath.Join(secretsDir, "vaults", "*", ".password-store"))
+		if err != nil {
+			return nil, err
+		}
+		stores = append(stores, matches...)
+	}
+
+	var found []string
+	for _, store := range stores {
+		files, err := config.FindPlaintextFiles(store)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			path := filepath.Join(store, file)
+			if rel, err := filepath.Rel(secretsDir, path); err == nil {
+				path = rel
+			}
+			found = append(found, path)
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitMessage(t *testing.T) {
+	if got := commitMessage(nil); got != "" {
+		t.Errorf("no changes: got %q", got)
+	}
+	got := commitMessage([]string{"set dev/db/password", "sync dev"})
+	if want := "secrets: set dev/db/password, sync dev"; got != want {
+		t.Errorf("commitMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestCommitSecrets(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	dir := t.TempDir()
+	secretsDir := filepath.Join(dir, ".secrets")
+	if err := os.MkdirAll(secretsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := commitSecrets(secretsDir, "secrets: init", false); err == nil || !strings.Contains(err.Error(), "not in a git repository") {
+		t.Fatalf("outside a repository: err = %v", err)
+	}
+
+	git := func(args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+
+	// A staged file outside the secrets directory must not be committed
+	if err := os.WriteFile(filepath.Join(dir, "other.txt"), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "other.txt")
+	if err := os.WriteFile(filepath.Join(secretsDir, "config.yaml"), []byte("version: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A lock held by another process must not be committed either
+	lockDir := filepath.Join(secretsDir, "vaults", "dev")
+	if err := os.MkdirAll(lockDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(lockDir, ".lock"), []byte("99999\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := commitSecrets(secretsDir, "secrets: init", false); err != nil {
+		t.Fatalf("commitSecrets() error = %v", err)
+	}
+	if got := git("log", "--format=%s"); got != "secrets: init" {
+		t.Errorf("log = %q", got)
+	}
+	if got := git("show", "--name-only", "--format="); got != ".secrets/.gitignore\n.secrets/config.yaml" {
+		t.Errorf("committed files = %q", got)
+	}
+	if got := git("diff", "--cached", "--name-only"); got != "other.txt" {
+		t.Errorf("still staged = %q, want other.txt", got)
+	}
+
+	// Without changes there is nothing to commit
+	if err := commitSecrets(secretsDir, "secrets: again", false); err != nil {
+		t.Fatalf("commitSecrets() without changes error = %v", err)
+	}
+	if got := git("rev-list", "--count", "HEAD"); got != "1" {
+		t.Errorf("commits = %s, want 1", got)
+	}
+
+	// A decrypted file left in a store stops the commit before staging
+	storeDir := filepath.Join(lockDir, ".password-store")
+	if err := os.MkdirAll(storeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "db.txt"), []byte("hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err := commitSecrets(secretsDir, "secrets: leak", false)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join("vaults", "dev", ".password-store", "db.txt")) {
+		t.Fatalf("plaintext in store: err = %v", err)
+	}
+	if got := git("rev-list", "--count", "HEAD"); got != "1" {
+		t.Errorf("commits = %s, want 1", got)
+	}
+	if got := git("diff", "--cached", "--name-only"); got != "other.txt" {
+		t.Errorf("staged = %q, want other.txt", got)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NuevaNext/secrets-cli/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the short-lived decrypted value cache",
+	Long: `Manage the cache used by 'get --cache-ttl'.
+
+Cached values live in a per-user 0700 directory on tmpfs (by default
+$XDG_RUNTIME_DIR/secrets-cli or /dev/shm), one 0600 file per entry.`,
+}
+
+var cachePurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Remove all cached secret values",
+	RunE:  runCachePurge,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cachePurgeCmd)
+}
+
+func runCachePurge(cmd *cobra.Command, args []string) error {
+	removed, err := cache.Purge(cache.Dir())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Purged %d cached value(s)\n", removed)
+	return nil
+}
+
+// secretCacheKey identifies a cached value by vault, secret, the encrypted
+// file's contents (so any change to the secret invalidates it) and the user
+func secretCacheKey(storeDir, vaultName, secretName, email string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(storeDir, secretName+".gpg"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return cache.Key(vaultName, secretName, hex.EncodeToString(sum[:]), email), nil
+}
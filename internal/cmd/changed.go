@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var changedCmd = &cobra.Command{
+	Use:   "changed [vault]",
+	Short: "Show secrets changed recently, according to git",
+	Long: `Show which secrets in a vault changed since a given time, based on the
+git history of the vault's encrypted files. Nothing is decrypted.
+
+--since takes a relative duration (7d, 2w, 24h, 90m) or a date
+(2024-01-01 or RFC 3339). For each secret the date and commit of its last
+change are shown. Only committed changes are considered.
+
+Examples:
+  secrets-cli changed dev
+  secrets-cli changed dev --since 24h
+  secrets-cli changed production --since 2024-01-01`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: runChanged,
+}
+
+var changedSince string
+
+func init() {
+	rootCmd.AddCommand(changedCmd)
+
+	changedCmd.Flags().StringVar(&changedSince, "since", "7d", "Duration (7d, 24h) or date (2024-01-01) to look back to")
+}
+
+// secretChange is the last committed change to a secret
+type secretChange struct {
+	Secret string
+	Commit string
+	Date   time.Time
+}
+
+func runChanged(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName, _, err := resolveVaultArg(secretsDir, args, len(args) == 1)
+	if err != nil {
+		return err
+	}
+
+	since, err := parseSince(changedSince, time.Now())
+	if err != nil {
+		return err
+	}
+
+	gitRoot, err := FindGitRoot()
+	if err != nil {
+		return fmt.Errorf("changed needs the secrets directory to be in a git repository: %w", err)
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	// Check vault exists
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+
+	// Check access
+	if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	storeDir, err := filepath.Abs(config.GetStoreDir(secretsDir, vaultName))
+	if err != nil {
+		return err
+	}
+	relStore, err := filepath.Rel(gitRoot, storeDir)
+	if err != nil || relStore == ".." || strings.HasPrefix(relStore, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("the password store of %s (%s) is not inside the git repository %s", vaultName, storeDir, gitRoot)
+	}
+	relStore = filepath.ToSlash(relStore)
+
+	gitCmd := exec.Command("git", "-C", gitRoot, "-c", "core.quotePath=false", "log",
+		"--since="+since.Format(time.RFC3339), "--name-only", "--format=commit %H %ct", "--", relStore)
+	var stdout, stderr bytes.Buffer
+	gitCmd.Stdout = &stdout
+	gitCmd.Stderr = &stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("git log failed: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	changes := parseGitChanges(stdout.String(), relStore)
+	if len(changes) == 0 {
+		fmt.Printf("No secrets in %s changed since %s\n", vaultName, since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	p := newPass(storeDir)
+	fmt.Printf("Secrets changed in %s since %s:\n", vaultName, since.Format("2006-01-02 15:04"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, c := range changes {
+		note := ""
+		if !p.Exists(c.Secret) {
+			note = "\t(deleted)"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s%s\n", c.Secret, c.Date.Local().Format("2006-01-02 15:04"), c.Commit[:min(7, len(c.Commit))], note)
+	}
+	return w.Flush()
+}
+
+// parseSince parses a relative duration such as 7d, 2w, 24h or 90m, or an
+// absolute date (2006-01-02) or RFC 3339 timestamp
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("--since cannot be empty")
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				break
+			}
+			return now.Add(-time.Duration(count) * unit), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since value: %s (use e.g. 7d, 24h or 2024-01-01)", value)
+}
+
+// parseGitChanges turns 'git log --name-only --format="commit %H %ct"'
+// output into the most recent change of each secret under relStore. git log
+// lists newest commits first, so the first mention of a file wins.
+func parseGitChanges(output, relStore string) []secretChange {
+	prefix := strings.TrimSuffix(relStore, "/") + "/"
+	var changes []secretChange
+	seen := map[string]bool{}
+	var commit string
+	var date time.Time
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "commit "); ok {
+			fields := strings.Fields(rest)
+			if len(fields) == 2 {
+				commit = fields[0]
+				if secs, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+					date = time.Unix(secs, 0)
+				}
+				continue
+			}
+		}
+
+		name, ok := strings.CutPrefix(line, prefix)
+		if !ok || !strings.HasSuffix(name, ".gpg") || commit == "" {
+			continue
+		}
+		secret := strings.TrimSuffix(name, ".gpg")
+		if seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		changes = append(changes, secretChange{Secret: secret, Commit: commit, Date: date})
+	}
+	return changes
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"7d", now.Add(-7 * 24 * time.Hour), false},
+		{"2w", now.Add(-14 * 24 * time.Hour), false},
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"2024-01-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), false},
+		{"2024-01-01T08:00:00Z", time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+		{"-3d", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSince(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGitChanges(t *testing.T) {
+	output := `commit 2222222222222222222222222222222222222222 1710000000
+
+.secrets/vaults/dev/.password-store/db/password.gpg
+.secrets/vaults/dev/.password-store/.gpg-id
+
+commit 1111111111111111111111111111111111111111 1709000000
+
+.secrets/vaults/dev/.password-store/db/password.gpg
+.secrets/vaults/dev/.password-store/api/key.gpg
+.secrets/vaults/dev/vault.yaml
+`
+	got := parseGitChanges(output, ".secrets/vaults/dev/.password-store")
+	want := []secretChange{
+		{Secret: "db/password", Commit: "2222222222222222222222222222222222222222", Date: time.Unix(1710000000, 0)},
+		{Secret: "api/key", Commit: "1111111111111111111111111111111111111111", Date: time.Unix(1709000000, 0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGitChanges() = %+v, want %+v", got, want)
+	}
+}
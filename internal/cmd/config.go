@@ -0,0 +1,422 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage store settings",
+	Long:  `Manage settings stored in the secrets directory's config.yaml.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the store settings",
+	Long: `Print the settings in the secrets directory's config.yaml, as YAML or,
+with --format json, as JSON. Settings left at their default are omitted.
+
+Examples:
+  secrets-cli config show
+  secrets-cli config show --format json`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a store setting",
+	Long: `Change one store setting in config.yaml. Keys are the names shown by
+'config show'; dashes may be used instead of underscores. An empty value
+resets a setting to its default.
+
+Settings:
+  owner           email of the store owner
+  default_vault   vault used when a command's vault argument is omitted
+  access_control  membership (default) or gpg-only
+  trust_model     gpg --trust-model for encrypting: always (default),
+                  direct, pgp, classic, tofu, tofu+pgp or auto
+  auto_commit     true to git commit .secrets after every change (--commit)
+  auto_push       true to also push those commits (--push)
+
+Settings that need more than a config change have their own commands:
+store_layout and shared_store (migrate-layout), recipient_format
+(migrate-recipients) and backup_recipients (add-backup-recipient,
+remove-backup-recipient). Unknown keys are rejected.
+
+Examples:
+  secrets-cli config set default_vault dev
+  secrets-cli config set access-control gpg-only
+  secrets-cli config set default_vault ""`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+var configSetDefaultVaultCmd = &cobra.Command{
+	Use:   "set-default-vault <vault>",
+	Short: "Set the vault used when a command's vault argument is omitted",
+	Long: `Set the default vault for the store.
+
+get, set, list, delete and export then accept the vault argument as
+optional and use the default when it is left out. An explicit vault
+argument always takes precedence. Pass an empty string to clear it.
+
+Examples:
+  secrets-cli config set-default-vault dev
+  secrets-cli get database/password        # same as: get dev database/password
+  secrets-cli config set-default-vault ""  # clear`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigSetDefaultVault,
+}
+
+var configAddBackupRecipientCmd = &cobra.Command{
+	Use:   "add-backup-recipient <email>",
+	Short: "Encrypt every secret for an offline break-glass key",
+	Long: `Add a backup recipient: a key that every secret in every vault is
+encrypted for, without being a member of any vault. Use it for an offline
+break-glass key kept e.g. in a safe, so secrets stay recoverable even if
+every member loses their key.
+
+The key must already be stored with 'secrets-cli key add'. It is imported
+into your keyring now; existing secrets are encrypted for it the next
+time each vault is re-encrypted, so run 'secrets-cli reencrypt-all'
+afterwards.
+
+Security tradeoff: whoever holds the backup key can decrypt every secret in
+the store, and it does not appear in member lists ('vault info' and
+'audit' report it as a recipient). Keep it offline, protect it with a
+strong passphrase and restrict who can reach it.
+
+Examples:
+  secrets-cli config add-backup-recipient breakglass@example.com
+  secrets-cli reencrypt-all`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigAddBackupRecipient,
+}
+
+var configRemoveBackupRecipientCmd = &cobra.Command{
+	Use:   "remove-backup-recipient <email>",
+	Short: "Stop encrypting secrets for a backup recipient",
+	Long: `Remove a backup recipient. Secrets stop being encrypted for it once each
+vault is re-encrypted with 'secrets-cli sync <vault>' or 'secrets-cli
+reencrypt-all'. Copies of the store
+encrypted before that can still be decrypted with the key.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigRemoveBackupRecipient,
+}
+
+var configShowFormat string
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configSetDefaultVaultCmd)
+	configCmd.AddCommand(configAddBackupRecipientCmd)
+	configCmd.AddCommand(configRemoveBackupRecipientCmd)
+
+	configShowCmd.Flags().StringVar(&configShowFormat, "format", "yaml", "Output format: yaml, json")
+}
+
+// configSettings are the store settings 'config set' can change. Each
+// function validates value and stores it in cfg.
+var configSettings = map[string]func(secretsDir string, cfg *config.Config, value string) error{
+	"owner": func(secretsDir string, cfg *config.Config, value string) error {
+		if value != "" {
+			if err := validateEmail(value); err != nil {
+				return err
+			}
+		}
+		cfg.Owner = value
+		return nil
+	},
+	"default_vault": func(secretsDir string, cfg *config.Config, value string) error {
+		if err := checkDefaultVault(secretsDir, value); err != nil {
+			return err
+		}
+		cfg.DefaultVault = value
+		return nil
+	},
+	"trust_model": func(secretsDir string, cfg *config.Config, value string) error {
+		if err := config.ValidateTrustModel(value); err != nil {
+			return validationErrorf("%w", err)
+		}
+		if value == config.TrustModelAlways {
+			value = ""
+		}
+		cfg.TrustModel = value
+		return nil
+	},
+	"auto_commit": func(secretsDir string, cfg *config.Config, value string) error {
+		return parseBoolSetting(value, &cfg.AutoCommit)
+	},
+	"auto_push": func(secretsDir string, cfg *config.Config, value string) error {
+		return parseBoolSetting(value, &cfg.AutoPush)
+	},
+	"access_control": func(secretsDir string, cfg *config.Config, value string) error {
+		if err := config.ValidateAccessControl(value); err != nil {
+			return validationErrorf("%w", err)
+		}
+		if value == config.AccessControlMembership {
+			value = ""
+		}
+		cfg.AccessControl = value
+		return nil
+	},
+}
+
+// configOtherCommands names the command that changes each setting 'config
+// set' does not handle
+var configOtherCommands = map[string]string{
+	"version":           "it is set by secrets-cli itself",
+	"store_layout":      "use 'secrets-cli migrate-layout', which also moves the stores",
+	"shared_store":      "use 'secrets-cli migrate-layout', which also moves the stores",
+	"recipient_format":  "use 'secrets-cli migrate-recipients', which also rewrites .gpg-id files",
+	"backup_recipients": "use 'secrets-cli config add-backup-recipient' or 'remove-backup-recipient'",
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+
+	if configShowFormat != "yaml" && configShowFormat != "json" {
+		return validationErrorf("unknown format: %s (use yaml or json)", configShowFormat)
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	cfg, err := config.LoadConfig(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if configShowFormat == "json" {
+		data, err = json.MarshalIndent(cfg, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	key := strings.ReplaceAll(args[0], "-", "_")
+	value := args[1]
+
+	apply, ok := configSettings[key]
+	if !ok {
+		if hint, ok := configOtherCommands[key]; ok {
+			return validationErrorf("%s cannot be changed with config set: %s", key, hint)
+		}
+		known := make([]string, 0, len(configSettings))
+		for k := range configSettings {
+			known = append(known, k)
+		}
+		sort.Strings(known)
+		return validationErrorf("unknown config key: %s (known: %s)", args[0], strings.Join(known, ", "))
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	cfg, err := config.LoadConfig(secretsDir)
+	if err != nil {
+		return err
+	}
+	if err := apply(secretsDir, cfg, value); err != nil {
+		return err
+	}
+	if err := config.SaveConfig(secretsDir, cfg); err != nil {
+		return err
+	}
+	recordChange("config set %s", key)
+
+	if value == "" {
+		fmt.Printf("✓ Reset %s to its default\n", key)
+	} else {
+		fmt.Printf("✓ Set %s to: %s\n", key, value)
+	}
+	return nil
+}
+
+// parseBoolSetting sets a true/false setting; an empty value is false
+func parseBoolSetting(value string, setting *bool) error {
+	if value == "" {
+		*setting = false
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return validationErrorf("expected true or false, got %q", value)
+	}
+	*setting = b
+	return nil
+}
+
+// checkDefaultVault checks that a vault can be made the default. An empty
+// name clears the default and is always accepted.
+func checkDefaultVault(secretsDir, vaultName string) error {
+	if vaultName == "" {
+		return nil
+	}
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+	if !config.VaultExists(secretsDir, vaultName) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+	return nil
+}
+
+func runConfigSetDefaultVault(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	vaultName := args[0]
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	if err := checkDefaultVault(secretsDir, vaultName); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(secretsDir)
+	if err != nil {
+		return err
+	}
+	cfg.DefaultVault = vaultName
+	if err := config.SaveConfig(secretsDir, cfg); err != nil {
+		return err
+	}
+	recordChange("set default vault %s", vaultName)
+
+	if vaultName == "" {
+		fmt.Println("✓ Cleared the default vault")
+	} else {
+		fmt.Printf("✓ Default vault set to: %s\n", vaultName)
+	}
+	return nil
+}
+
+func runConfigAddBackupRecipient(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := args[0]
+
+	if err := validateEmail(email); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	cfg, err := config.LoadConfig(secretsDir)
+	if err != nil {
+		return err
+	}
+	for _, r := range cfg.BackupRecipients {
+		if strings.EqualFold(r, email) {
+			return fmt.Errorf("%s is already a backup recipient", email)
+		}
+	}
+
+	keyPath := filepath.Join(config.GetKeysDir(secretsDir), email+".asc")
+	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
+		return notFoundErrorf("key not found for %s. Add it with: secrets-cli key add %s", email, email)
+	}
+	if err := newGPG().ImportKey(keyPath); err != nil {
+		return fmt.Errorf("failed to import key for %s: %w", email, err)
+	}
+
+	cfg.BackupRecipients = append(cfg.BackupRecipients, email)
+	if err := config.SaveConfig(secretsDir, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Added backup recipient: %s\n", email)
+	recordChange("add backup recipient %s", email)
+	printSyncAllHint(secretsDir)
+	return nil
+}
+
+func runConfigRemoveBackupRecipient(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := args[0]
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	cfg, err := config.LoadConfig(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	var kept []string
+	for _, r := range cfg.BackupRecipients {
+		if !strings.EqualFold(r, email) {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == len(cfg.BackupRecipients) {
+		return notFoundErrorf("%s is not a backup recipient", email)
+	}
+	cfg.BackupRecipients = kept
+	if err := config.SaveConfig(secretsDir, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Removed backup recipient: %s\n", email)
+	recordChange("remove backup recipient %s", email)
+	printSyncAllHint(secretsDir)
+	return nil
+}
+
+// printSyncAllHint reminds the user that existing secrets only change
+// recipients when their vault is re-encrypted
+func printSyncAllHint(secretsDir string) {
+	vaults, err := config.ListVaults(secretsDir)
+	if err != nil || len(vaults) == 0 {
+		return
+	}
+	fmt.Println("Existing secrets keep their recipients until re-encrypted. Run:")
+	fmt.Println("  secrets-cli reencrypt-all")
+}
+
+// resolveVaultArg splits a command's arguments into the vault and the rest.
+// When explicit is false the vault argument was omitted and the store's
+// default vault is used instead.
+func resolveVaultArg(secretsDir string, args []string, explicit bool) (string, []string, error) {
+	if explicit {
+		return args[0], args[1:], nil
+	}
+
+	cfg, err := config.LoadConfig(secretsDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", nil, err
+	}
+	if cfg == nil || cfg.DefaultVault == "" {
+		return "", nil, fmt.Errorf("no vault given and no default vault is configured. Pass a vault name or run: secrets-cli config set-default-vault <vault>")
+	}
+	return cfg.DefaultVault, args, nil
+}
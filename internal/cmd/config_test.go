@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+)
+
+func TestConfigSettings(t *testing.T) {
+	secretsDir := t.TempDir()
+	cfg := &config.Config{}
+
+	if err := configSettings["access_control"](secretsDir, cfg, config.AccessControlGPGOnly); err != nil || cfg.AccessControl != config.AccessControlGPGOnly {
+		t.Errorf("access_control gpg-only: err = %v, AccessControl = %q", err, cfg.AccessControl)
+	}
+	// The default mode is stored as empty so config.yaml stays minimal
+	if err := configSettings["access_control"](secretsDir, cfg, config.AccessControlMembership); err != nil || cfg.AccessControl != "" {
+		t.Errorf("access_control membership: err = %v, AccessControl = %q", err, cfg.AccessControl)
+	}
+	if err := configSettings["access_control"](secretsDir, cfg, "everyone"); err == nil {
+		t.Error("access_control everyone: expected an error")
+	}
+	if err := configSettings["default_vault"](secretsDir, cfg, "missing"); ExitCode(err) != ExitNotFound {
+		t.Errorf("default_vault missing: err = %v, want a not found error", err)
+	}
+	if err := configSettings["owner"](secretsDir, cfg, "not an email"); err == nil {
+		t.Error("owner with an invalid email: expected an error")
+	}
+
+	for key := range configOtherCommands {
+		if _, ok := configSettings[key]; ok {
+			t.Errorf("%s is both settable and handled by another command", key)
+		}
+	}
+}
+
+func TestResolveVaultArg(t *testing.T) {
+	secretsDir := t.TempDir()
+	if _, _, err := resolveVaultArg(secretsDir, []string{"db/password"}, false); err == nil || !strings.Contains(err.Error(), "no default vault") {
+		t.Errorf("without config.yaml: err = %v", err)
+	}
+
+	// A config that cannot be read is reported as such, not as a missing default
+	if err := os.WriteFile(filepath.Join(secretsDir, "config.yaml"), []byte("default_vault: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := resolveVaultArg(secretsDir, []string{"db/password"}, false); err == nil || !strings.Contains(err.Error(), "failed to parse config") {
+		t.Errorf("broken config.yaml: err = %v", err)
+	}
+
+	if err := config.SaveConfig(secretsDir, &config.Config{DefaultVault: "dev"}); err != nil {
+		t.Fatal(err)
+	}
+	vault, rest, err := resolveVaultArg(secretsDir, []string{"db/password"}, false)
+	if err != nil || vault != "dev" || len(rest) != 1 || rest[0] != "db/password" {
+		t.Errorf("default vault: got %q, %v, %v", vault, rest, err)
+	}
+	vault, rest, err = resolveVaultArg(secretsDir, []string{"prod", "db/password"}, true)
+	if err != nil || vault != "prod" || len(rest) != 1 || rest[0] != "db/password" {
+		t.Errorf("explicit vault: got %q, %v, %v", vault, rest, err)
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env [vault]",
+	Short: "Print a sourceable shell script that exports a vault's secrets",
+	Long: `Print one 'export VAR=value' line per secret, for sourcing in a POSIX
+shell. Variable names follow the export command (database/password becomes
+DATABASE_PASSWORD) and values are always quoted the same way, so values
+with quotes, backticks, $(...) or newlines are set literally.
+
+Use --unset to print the matching 'unset VAR' lines instead, to tear the
+environment down again. --unset does not decrypt anything.
+
+Secrets whose names do not map to a valid variable name are skipped with
+a warning on stderr.
+
+Examples:
+  eval "$(secrets-cli env dev)"
+  eval "$(secrets-cli env dev --unset)"
+  secrets-cli env dev --prefix APP_ > .env.sh`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: runEnv,
+}
+
+var (
+	envPrefix string
+	envUnset  bool
+)
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+
+	envCmd.Flags().StringVar(&envPrefix, "prefix", "", "Prefix for variable names")
+	envCmd.Flags().BoolVar(&envUnset, "unset", false, "Print unset lines for teardown instead of exports")
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName, _, err := resolveVaultArg(secretsDir, args, len(args) == 1)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	// Check vault exists
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+
+	// Check access
+	if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	p := newPass(config.GetStoreDir(secretsDir, vaultName))
+	secrets, err := p.List()
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	for _, secret := range secrets {
+		name := envPrefix + secretToEnvName(secret)
+		if !isShellIdentifier(name) {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %s is not a valid variable name\n", secret, name)
+			continue
+		}
+
+		if envUnset {
+			fmt.Printf("unset %s\n", name)
+			continue
+		}
+
+		value, err := p.Show(secret)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", secret, err)
+			continue
+		}
+		fmt.Printf("export %s=%s\n", name, quoteForShell(value))
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
+)
+
+// Exit codes returned by secrets-cli, so scripts can tell failures apart
+const (
+	ExitOK           = 0
+	ExitError        = 1   // Any other failure
+	ExitNotFound     = 2   // Secrets directory, vault, secret, key or group not found
+	ExitAccessDenied = 3   // Not a member of the vault, or the vault is archived
+	ExitGPG          = 4   // gpg or pass failed or timed out
+	ExitValidation   = 5   // Invalid arguments, flags or names
+	ExitInterrupted  = 130 // Interrupted by SIGINT or SIGTERM while a vault was locked
+)
+
+// exitCodesHelp documents the exit codes in the root command's help
+const exitCodesHelp = `Exit codes:
+  0    success
+  1    other error
+  2    not found (secrets directory, vault, secret, key or group)
+  3    access denied (not a vault member, or vault archived)
+  4    gpg or pass failed or timed out
+  5    invalid arguments, flags or names
+  130  interrupted while a vault was locked`
+
+// codedError is an error with the exit code it should produce
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// Code returns the process exit code for the error
+func (e *codedError) Code() int { return e.code }
+
+func notFoundErrorf(format string, a ...any) error {
+	return &codedError{code: ExitNotFound, err: fmt.Errorf(format, a...)}
+}
+
+func accessDeniedErrorf(format string, a ...any) error {
+	return &codedError{code: ExitAccessDenied, err: fmt.Errorf(format, a...)}
+}
+
+func validationErrorf(format string, a ...any) error {
+	return &codedError{code: ExitValidation, err: fmt.Errorf(format, a...)}
+}
+
+// ExitCode maps an error returned by Execute to a process exit code. Errors
+// carrying a code keep it however deeply they are wrapped, and failures of
+// gpg or pass processes map to ExitGPG.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	var interrupted *config.InterruptedError
+	if errors.As(err, &interrupted) {
+		return ExitInterrupted
+	}
+	var cmdErr *gpg.CommandError
+	if errors.As(err, &cmdErr) {
+		return ExitGPG
+	}
+	return ExitError
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"plain error", errors.New("boom"), ExitError},
+		{"not found", notFoundErrorf("vault not found: %s", "dev"), ExitNotFound},
+		{"wrapped access denied", fmt.Errorf("sync failed: %w", accessDeniedErrorf("Access denied")), ExitAccessDenied},
+		{"validation", validateName("../etc"), ExitValidation},
+		{"gpg failure", fmt.Errorf("failed to get secret: %w", &gpg.CommandError{Err: errors.New("exit status 2")}), ExitGPG},
+		{"interrupted", fmt.Errorf("sync failed: %w", &config.InterruptedError{Signal: os.Interrupt}), ExitInterrupted},
+		{"gpg timeout", &gpg.CommandError{Err: fmt.Errorf("gpg %w", gpg.ErrTimeout)}, ExitGPG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,27 +1,105 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/NuevaNext/secrets-cli/internal/config"
 	"github.com/NuevaNext/secrets-cli/internal/pass"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var exportCmd = &cobra.Command{
-	Use:   "export <vault>",
+	Use:   "export [vault]",
 	Short: "Export secrets as environment variables",
 	Long: `Export secrets from a vault in various formats.
 
 Formats:
   env    - Shell export format: export VAR=value
   dotenv - Dotenv format: VAR=value
-  json   - JSON object: {"key": "value"}`,
-	Args: cobra.ExactArgs(1),
+  json   - JSON object: {"key": "value"}
+  raw    - Original secret path and value separated by a tab, unquoted
+  csv    - name,value rows with CSV quoting
+  k8s    - Kubernetes v1 Secret manifest with base64-encoded data
+  docker - Env file for 'docker run --env-file': VAR=value, never quoted
+
+The raw format does no name transformation or escaping, so values that
+contain tabs or newlines cannot be parsed unambiguously. Use --format json
+for those. JSON output is indented with keys in sorted order; use
+--compact to print it on one line.
+
+Use --only and --exclude (both repeatable) to export a subset. They take
+glob patterns matched against secret paths, where * does not cross '/'.
+Only the selected secrets are decrypted.
+
+Use --vault-prefix to prefix variable names with the vault name, turned
+into a variable name the same way (my-app becomes MY_APP_), so several
+vaults can be exported into one environment without collisions. It is
+added after --prefix: --prefix APP_ --vault-prefix gives APP_DEV_....
+
+Every selected secret is decrypted before anything is written. If one
+cannot be decrypted, export fails and prints nothing, so a partial .env
+never looks complete. Use --keep-going to export the rest anyway; skipped
+secrets are listed on stderr. A summary of exported and skipped counts is
+always printed to stderr.
+
+Use --out to write to a file instead of redirecting stdout. The file is
+created with 0600 permissions and replaced atomically, so it is never left
+truncated, and a warning is printed if git does not ignore it.
+
+Docker reads env files literally: quotes are kept as part of the value and
+a value cannot span lines. The docker format therefore fails on values
+containing a newline unless --base64-multiline is given, which writes them
+base64-encoded instead; the application has to decode them. Variable names
+must be letters, digits and underscores, not starting with a digit. Every
+secret that cannot be represented is reported. With --docker-run a
+ready-to-copy 'docker run' command with one -e flag per secret is printed
+instead of the env file; there values are shell-quoted, so newlines are
+kept. Mind that the command line, including the values, ends up in your
+shell history and the process list.
+
+--template replaces the fixed formats with a Go text/template, given as a
+file or inline. It receives .Vault, .Secrets (a list of .Name, .EnvName
+and .Value, where .EnvName honors --prefix and --vault-prefix) and .Values
+(values by secret name), and can use the functions env (secret name to
+variable name), quote (shell-quote) and b64 (base64-encode). Secrets that
+--keep-going skips are left out as in every other format.
+
+Use --mask to write a redacted example, such as a committable
+.env.example: every value is replaced by CHANGEME, or by the text given
+with --mask-with. Only secret names are needed, so nothing is decrypted,
+and --out then writes an ordinary 0644 file without the git warning.
+
+For k8s, --name sets the Secret's name (default: the vault name) and
+--namespace its namespace. Use --raw-keys to key csv rows and k8s data by
+the secret path instead of the variable name.
+
+Examples:
+  secrets-cli export dev --only 'db/*'
+  secrets-cli export prod --format k8s --name app-secrets --namespace web | kubectl apply -f -
+  secrets-cli export dev --format csv > dev.csv
+  secrets-cli export dev --format docker --out dev.env && docker run --env-file dev.env app
+  secrets-cli export dev --format docker --docker-run
+  secrets-cli export dev --vault-prefix   # DEV_DATABASE_PASSWORD=...
+  secrets-cli export dev --keep-going
+  secrets-cli export dev --format dotenv --out .env
+  secrets-cli export dev --format dotenv --mask --out .env.example
+  secrets-cli export dev --format dotenv --mask-with '<redacted>'
+  secrets-cli export dev --template '{{range .Secrets}}{{.EnvName}}={{b64 .Value}}{{"\n"}}{{end}}'
+  secrets-cli export dev --template nginx.conf.tmpl --out nginx.conf
+  secrets-cli export dev --exclude 'admin/*' --exclude 'legacy/*'`,
+	Args: cobra.RangeArgs(0, 1),
 	RunE: runExport,
 }
 
@@ -30,142 +108,621 @@ var syncCmd = &cobra.Command{
 	Short: "Synchronize and verify vault integrity",
 	Long: `Synchronize a vault by verifying integrity and re-encrypting if needed.
 
-This ensures that all secrets are encrypted for all current members.`,
+This ensures that all secrets are encrypted for all current members, or
+for the members of their subvault (see 'vault subvault').
+Members of groups added with 'vault add-member <vault> @group' are
+updated first to follow changes in groups.yaml.
+Use --dry-run to show the re-encryption plan without changing anything.
+Vaults with more than 50 secrets are re-encrypted four secrets at a time,
+with the same gpg options as pass; use --parallel to do so for smaller
+vaults too.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSync,
 }
 
 var (
-	exportFormat string
-	exportPrefix string
+	exportFormat      string
+	exportPrefix      string
+	exportOnly        []string
+	exportExclude     []string
+	exportRawKeys     bool
+	exportVaultPrefix bool
+	exportKeepGoing   bool
+	exportOut         string
+	k8sName           string
+	k8sNamespace      string
+	dockerRun         bool
+	dockerBase64      bool
+	exportCompact     bool
+	exportTemplate    string
+	exportMask        bool
+	exportMaskWith    string
 )
 
 func init() {
 	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(syncCmd)
 
-	exportCmd.Flags().StringVar(&exportFormat, "format", "env", "Output format: env, dotenv, json")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "env", "Output format: env, dotenv, json, raw, csv, k8s, docker")
 	exportCmd.Flags().StringVar(&exportPrefix, "prefix", "", "Prefix for variable names")
+	exportCmd.Flags().BoolVar(&exportVaultPrefix, "vault-prefix", false, "Prefix variable names with the vault name, e.g. DEV_")
+	exportCmd.Flags().StringSliceVar(&exportOnly, "only", nil, "Only export secrets matching this glob (repeatable)")
+	exportCmd.Flags().StringSliceVar(&exportExclude, "exclude", nil, "Skip secrets matching this glob (repeatable)")
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", "", "Write to this file (created 0600, replaced atomically) instead of stdout")
+	exportCmd.Flags().BoolVar(&exportKeepGoing, "keep-going", false, "Skip secrets that cannot be decrypted instead of failing")
+	exportCmd.Flags().BoolVar(&exportRawKeys, "raw-keys", false, "Key csv and k8s output by secret path instead of variable name")
+	exportCmd.Flags().StringVar(&k8sName, "name", "", "Secret name for --format k8s (default: vault name)")
+	exportCmd.Flags().StringVar(&k8sNamespace, "namespace", "", "Namespace for --format k8s")
+	exportCmd.Flags().BoolVar(&dockerRun, "docker-run", false, "With --format docker, print a 'docker run' command with -e flags instead of an env file")
+	exportCmd.Flags().BoolVar(&dockerBase64, "base64-multiline", false, "With --format docker, base64-encode values containing newlines instead of failing")
+	exportCmd.Flags().StringVar(&exportTemplate, "template", "", "Render this Go template (a file, or inline text) instead of a format")
+	exportCmd.Flags().BoolVar(&exportCompact, "compact", false, "With --format json, print the object on one line")
+	exportCmd.Flags().BoolVar(&exportMask, "mask", false, "Replace every value with a placeholder, without decrypting anything")
+	exportCmd.Flags().StringVar(&exportMaskWith, "mask-with", "CHANGEME", "Placeholder for --mask (implies --mask)")
+	syncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and show the re-encryption plan without changing anything")
+	syncCmd.Flags().BoolVar(&reencryptParallel, "parallel", false, "Re-encrypt four secrets at a time, whatever the vault size")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	email := GetUserEmail()
-	vaultName := args[0]
+	vaultName, _, err := resolveVaultArg(secretsDir, args, len(args) == 1)
+	if err != nil {
+		return err
+	}
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Check vault exists
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
 	// Check access
-	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", vaultName)
+	if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	if (dockerRun || dockerBase64) && exportFormat != "docker" {
+		return validationErrorf("--docker-run and --base64-multiline only apply to --format docker")
+	}
+	if exportCompact && exportFormat != "json" {
+		return validationErrorf("--compact only applies to --format json")
+	}
+
+	// Template errors show before anything is decrypted
+	var tmpl *template.Template
+	if exportTemplate != "" {
+		if cmd.Flags().Changed("format") {
+			return validationErrorf("use either --template or --format")
+		}
+		if tmpl, err = parseExportTemplate(exportTemplate); err != nil {
+			return err
+		}
 	}
 
 	// Get all secrets
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	p := newPass(storeDir)
 	secrets, err := p.List()
 	if err != nil {
 		return fmt.Errorf("failed to list secrets: %w", err)
 	}
 
+	if len(exportOnly) > 0 || len(exportExclude) > 0 {
+		secrets, err = filterSecrets(secrets, exportOnly, exportExclude)
+		if err != nil {
+			return err
+		}
+		if len(secrets) == 0 {
+			return fmt.Errorf("no secrets in %s match the --only/--exclude filters", vaultName)
+		}
+	}
+
+	// --mask only needs the names, so nothing is decrypted
+	mask := exportMask || cmd.Flags().Changed("mask-with")
+	var values map[string]string
+	if mask {
+		values = make(map[string]string, len(secrets))
+		for _, secret := range secrets {
+			values[secret] = exportMaskWith
+		}
+		fmt.Fprintf(os.Stderr, "✓ Masked %d secret(s)\n", len(secrets))
+	} else if values, secrets, err = decryptAll(p, secrets, exportKeepGoing); err != nil {
+		return err
+	}
+
+	// --vault-prefix namespaces names by vault, after --prefix
+	prefix := exportPrefix
+	if exportVaultPrefix {
+		prefix += secretToEnvName(vaultName) + "_"
+	}
+
+	// Keys for the csv and k8s formats
+	exportKey := func(secret string) string {
+		if exportRawKeys {
+			return secret
+		}
+		return prefix + secretToEnvName(secret)
+	}
+
+	// Render everything first so a failure never leaves partial output
+	out := &bytes.Buffer{}
+
 	// Export based on format
-	switch exportFormat {
-	case "json":
-		fmt.Println("{")
-		for i, secret := range secrets {
-			value, err := p.Show(secret)
-			if err != nil {
-				continue
-			}
-			// Escape JSON
-			value = strings.ReplaceAll(value, "\\", "\\\\")
-			value = strings.ReplaceAll(value, "\"", "\\\"")
+	switch {
+	case tmpl != nil:
+		data := exportTemplateData{Vault: vaultName, Values: map[string]string{}}
+		for _, secret := range secrets {
+			data.Secrets = append(data.Secrets, exportSecret{
+				Name:    secret,
+				EnvName: prefix + secretToEnvName(secret),
+				Value:   values[secret],
+			})
+			data.Values[secret] = values[secret]
+		}
+		if err := tmpl.Execute(out, data); err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
 
-			comma := ","
-			if i == len(secrets)-1 {
-				comma = ""
-			}
-			fmt.Printf("  \"%s%s\": \"%s\"%s\n", exportPrefix, secretToEnvName(secret), value, comma)
+	case exportFormat == "csv":
+		var rows [][2]string
+		for _, secret := range secrets {
+			value := values[secret]
+			rows = append(rows, [2]string{exportKey(secret), value})
+		}
+		if err := writeCSV(out, rows); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
 		}
-		fmt.Println("}")
 
-	case "dotenv":
+	case exportFormat == "k8s":
+		name := k8sName
+		if name == "" {
+			name = vaultName
+		}
+		data := map[string]string{}
 		for _, secret := range secrets {
-			value, err := p.Show(secret)
-			if err != nil {
-				continue
-			}
-			fmt.Printf("%s%s=%s\n", exportPrefix, secretToEnvName(secret), value)
+			value := values[secret]
+			data[exportKey(secret)] = value
+		}
+		manifest, err := k8sSecretManifest(name, k8sNamespace, data)
+		if err != nil {
+			return err
+		}
+		out.Write(manifest)
+
+	case exportFormat == "json":
+		data := map[string]string{}
+		for _, secret := range secrets {
+			data[prefix+secretToEnvName(secret)] = values[secret]
+		}
+		if err := writeJSON(out, data, exportCompact); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+
+	case exportFormat == "docker":
+		var rows [][2]string
+		for _, secret := range secrets {
+			rows = append(rows, [2]string{prefix + secretToEnvName(secret), values[secret]})
+		}
+		var rendered []byte
+		if dockerRun {
+			rendered, err = dockerRunCommand(rows)
+		} else {
+			rendered, err = dockerEnvFile(rows, dockerBase64)
+		}
+		if err != nil {
+			return err
+		}
+		out.Write(rendered)
+
+	case exportFormat == "raw":
+		for _, secret := range secrets {
+			value := values[secret]
+			fmt.Fprintf(out, "%s\t%s\n", secret, value)
+		}
+
+	case exportFormat == "dotenv":
+		for _, secret := range secrets {
+			value := values[secret]
+			fmt.Fprintf(out, "%s%s=%s\n", prefix, secretToEnvName(secret), value)
 		}
 
 	default: // env
 		for _, secret := range secrets {
-			value, err := p.Show(secret)
-			if err != nil {
-				continue
-			}
-			fmt.Printf("export %s%s=%s\n", exportPrefix, secretToEnvName(secret), quoteForShell(value))
+			value := values[secret]
+			fmt.Fprintf(out, "export %s%s=%s\n", prefix, secretToEnvName(secret), quoteForShell(value))
 		}
 	}
 
+	if exportOut == "" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	// A masked example holds no secrets and is meant to be committed
+	perm := os.FileMode(0600)
+	if mask {
+		perm = 0644
+	}
+	if err := writeFileAtomic(exportOut, out.Bytes(), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportOut, err)
+	}
+	if ignored, ok := gitIgnored(exportOut); ok && !ignored && !mask {
+		fmt.Fprintf(os.Stderr, "⚠ %s is not ignored by git; add it to .gitignore so it is never committed\n", exportOut)
+	}
+	fmt.Fprintf(os.Stderr, "✓ Wrote %s\n", exportOut)
 	return nil
 }
 
+// decryptAll decrypts secrets in order. Without keepGoing the first failure
+// is returned; with it, failures are reported on stderr and left out of the
+// returned names. A summary line is printed to stderr either way.
+func decryptAll(p *pass.Pass, secrets []string, keepGoing bool) (map[string]string, []string, error) {
+	values := make(map[string]string, len(secrets))
+	var decrypted, skipped []string
+	for _, secret := range secrets {
+		value, err := p.Show(secret)
+		if err != nil {
+			if !keepGoing {
+				return nil, nil, fmt.Errorf("failed to decrypt %s, nothing was exported (use --keep-going to skip it): %w", secret, err)
+			}
+			fmt.Fprintf(os.Stderr, "⚠ Skipped %s: %v\n", secret, err)
+			skipped = append(skipped, secret)
+			continue
+		}
+		values[secret] = value
+		decrypted = append(decrypted, secret)
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Exported %d secret(s), skipped %d\n", len(decrypted), len(skipped))
+	return values, decrypted, nil
+}
+
 func runSync(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	email := GetUserEmail()
 	vaultName := args[0]
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Check vault exists
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
 	// Check access
-	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", vaultName)
+	if err := checkWriteAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	return syncVault(secretsDir, vaultName)
+}
+
+// syncVault re-encrypts a vault's secrets for its current recipients and
+// verifies the result, or with --dry-run prints the plan. Callers check
+// access first.
+func syncVault(secretsDir, vaultName string) error {
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	return config.WithVaultLock(vaultDir, func() error {
+		// Load vault config
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config: %w", err)
+		}
+
+		// Follow changes to the vault's groups since they were expanded
+		var added []string
+		if len(vaultCfg.Groups) > 0 {
+			groups, err := config.LoadGroups(secretsDir)
+			if err != nil {
+				return err
+			}
+			ownerInGroups := vaultCfg.Owner != "" && config.Groups(vaultCfg.Groups).Includes(vaultCfg.Owner)
+			var removed []string
+			added, removed = config.SyncGroupMembers(vaultCfg, groups)
+			if len(vaultCfg.Members) == 0 {
+				return fmt.Errorf("group changes would remove every member of %s", vaultName)
+			}
+			if emptied := vaultCfg.PruneSubvaults(); len(emptied) > 0 {
+				return fmt.Errorf("group changes would remove every member of subvault %s", strings.Join(emptied, ", "))
+			}
+			keysDir := config.GetKeysDir(secretsDir)
+			for _, member := range added {
+				keyFile := filepath.Join(keysDir, member+".asc")
+				if _, err := os.Stat(keyFile); os.IsNotExist(err) {
+					return notFoundErrorf("key not found for group member %s. Add it with: secrets-cli key add %s", member, member)
+				}
+				fmt.Printf("  + %s (added to a group)\n", member)
+			}
+			for _, member := range removed {
+				fmt.Printf("  - %s (removed from a group)\n", member)
+			}
+			if ownerInGroups && !config.Groups(vaultCfg.Groups).Includes(vaultCfg.Owner) {
+				fmt.Printf("  ⚠ %s left the vault's groups but stays a member as the owner. Hand the vault over first with: secrets-cli vault transfer-ownership %s <email>\n", vaultCfg.Owner, vaultName)
+			}
+		}
+
+		// Re-init password store with current members
+		storeDir := config.GetStoreDir(secretsDir, vaultName)
+		p := newPass(storeDir)
+
+		secrets, _ := p.List()
+		fmt.Printf("Synchronizing vault: %s\n", vaultName)
+		fmt.Printf("  Members: %d\n", len(vaultCfg.Members))
+		fmt.Printf("  Secrets: %d\n", len(secrets))
+
+		if dryRun {
+			printReencryptPlan(storeDir, vaultRecipients(secretsDir, vaultCfg))
+			return nil
+		}
+
+		// Keys are imported only now, so a dry run leaves the keyring alone.
+		// New group members must have one; reencryptVault imports those of
+		// backup recipients and recovery keys.
+		g := newGPG()
+		for _, member := range added {
+			if err := g.ImportKey(filepath.Join(config.GetKeysDir(secretsDir), member+".asc")); err != nil {
+				return fmt.Errorf("failed to import key for %s: %w", member, err)
+			}
+		}
+
+		if err := reencryptVault(p, secretsDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to re-encrypt secrets: %w", err)
+		}
+
+		// Update timestamp
+		vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to save vault config: %w", err)
+		}
+
+		fmt.Printf("✓ Synchronized vault: %s\n", vaultName)
+		recordChange("sync %s", vaultName)
+		return nil
+	})
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so path is either replaced completely or left untouched
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
 	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// filterSecrets keeps secrets matching any of the only patterns (or all
+// secrets if none are given) and drops those matching any exclude pattern
+func filterSecrets(secrets, only, exclude []string) ([]string, error) {
+	matchAny := func(patterns []string, name string) (bool, error) {
+		for _, pattern := range patterns {
+			ok, err := filepath.Match(pattern, name)
+			if err != nil {
+				return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	var filtered []string
+	for _, secret := range secrets {
+		if len(only) > 0 {
+			ok, err := matchAny(only, secret)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		skip, err := matchAny(exclude, secret)
+		if err != nil {
+			return nil, err
+		}
+		if !skip {
+			filtered = append(filtered, secret)
+		}
+	}
+	return filtered, nil
+}
 
-	// Load vault config
-	vaultCfg, err := config.LoadVaultConfig(vaultDir)
+// writeCSV writes name,value rows with a header line
+func writeCSV(w io.Writer, rows [][2]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "value"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(row[:]); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// k8sSecretKey matches valid keys for a Kubernetes Secret's data
+var k8sSecretKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// k8sSecret is a Kubernetes v1 Secret manifest
+type k8sSecret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMetadata       `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+}
+
+type k8sMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// exportTemplateData is what --template templates are executed with
+type exportTemplateData struct {
+	Vault   string
+	Secrets []exportSecret
+	Values  map[string]string
+}
+
+// exportSecret is one exported secret as seen by templates
+type exportSecret struct {
+	Name    string
+	EnvName string
+	Value   string
+}
+
+// exportTemplateFuncs are the functions available to --template templates
+var exportTemplateFuncs = template.FuncMap{
+	"env":   secretToEnvName,
+	"quote": quoteForShell,
+	"b64": func(value string) string {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	},
+}
+
+// parseExportTemplate parses --template: the file at arg if there is one,
+// otherwise arg itself as template text
+func parseExportTemplate(arg string) (*template.Template, error) {
+	text := arg
+	if data, err := os.ReadFile(arg); err == nil {
+		text = string(data)
+	} else if !strings.Contains(arg, "{{") {
+		return nil, notFoundErrorf("template not found: %s", arg)
+	}
+
+	tmpl, err := template.New("export").Funcs(exportTemplateFuncs).Option("missingkey=error").Parse(text)
 	if err != nil {
-		return fmt.Errorf("failed to load vault config: %w", err)
+		return nil, validationErrorf("failed to parse template: %w", err)
 	}
+	return tmpl, nil
+}
 
-	// Re-init password store with current members
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
+// writeJSON writes values as one JSON object with sorted keys, indented
+// unless compact. &, < and > are kept as they are rather than escaped.
+func writeJSON(w io.Writer, values map[string]string, compact bool) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if !compact {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(values)
+}
 
-	secrets, _ := p.List()
-	fmt.Printf("Synchronizing vault: %s\n", vaultName)
-	fmt.Printf("  Members: %d\n", len(vaultCfg.Members))
-	fmt.Printf("  Secrets: %d\n", len(secrets))
+// k8sSecretManifest renders an Opaque Secret whose data holds the standard
+// base64 encoding of each value's bytes
+func k8sSecretManifest(name, namespace string, values map[string]string) ([]byte, error) {
+	secret := k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   k8sMetadata{Name: name, Namespace: namespace},
+		Type:       "Opaque",
+		Data:       map[string]string{},
+	}
+	for key, value := range values {
+		if !k8sSecretKey.MatchString(key) {
+			return nil, fmt.Errorf("%q is not a valid Kubernetes Secret key (allowed: letters, digits, '-', '_', '.')", key)
+		}
+		secret.Data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
 
-	if err := p.ReInit(vaultCfg.Members); err != nil {
-		return fmt.Errorf("failed to re-encrypt secrets: %w", err)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(secret); err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
 	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// dockerEnvName matches variable names docker accepts without surprises
+var dockerEnvName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
-	// Update timestamp
-	vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-	if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
-		return fmt.Errorf("failed to save vault config: %w", err)
+// dockerEnvFile renders rows as a docker env file. Docker takes everything
+// after the first '=' literally, so values are never quoted; values with a
+// newline are base64-encoded with base64Multiline and rejected otherwise.
+// Every row that cannot be represented is listed in the error.
+func dockerEnvFile(rows [][2]string, base64Multiline bool) ([]byte, error) {
+	var buf bytes.Buffer
+	var problems []string
+	for _, row := range rows {
+		name, value := row[0], row[1]
+		if !dockerEnvName.MatchString(name) {
+			problems = append(problems, fmt.Sprintf("%q is not a valid variable name", name))
+			continue
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			if !base64Multiline {
+				problems = append(problems, fmt.Sprintf("%s spans several lines (use --base64-multiline)", name))
+				continue
+			}
+			value = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		if strings.ContainsRune(value, 0) {
+			problems = append(problems, fmt.Sprintf("%s contains a NUL byte", name))
+			continue
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", name, value)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("cannot write a docker env file, nothing was exported:\n  %s", strings.Join(problems, "\n  "))
 	}
+	return buf.Bytes(), nil
+}
 
-	fmt.Printf("✓ Synchronized vault: %s\n", vaultName)
-	return nil
+// dockerRunCommand renders rows as a 'docker run' command with one -e flag
+// per row, shell-quoted so any value survives copying into a terminal
+func dockerRunCommand(rows [][2]string) ([]byte, error) {
+	var problems []string
+	var buf bytes.Buffer
+	buf.WriteString("docker run")
+	for _, row := range rows {
+		name, value := row[0], row[1]
+		if !dockerEnvName.MatchString(name) {
+			problems = append(problems, fmt.Sprintf("%q is not a valid variable name", name))
+			continue
+		}
+		if strings.ContainsRune(value, 0) {
+			problems = append(problems, fmt.Sprintf("%s contains a NUL byte", name))
+			continue
+		}
+		fmt.Fprintf(&buf, " \\\n  -e %s", quoteForShell(name+"="+value))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("cannot build a docker run command, nothing was exported:\n  %s", strings.Join(problems, "\n  "))
+	}
+	buf.WriteString(" \\\n  IMAGE\n")
+	return buf.Bytes(), nil
 }
 
 // secretToEnvName converts a secret path to an environment variable name
@@ -178,13 +735,30 @@ func secretToEnvName(secret string) string {
 	return name
 }
 
-// quoteForShell quotes a value for safe use in shell
+// quoteForShell quotes a value for safe use in a POSIX shell. Values made
+// only of characters that are never special are returned as-is; anything
+// else is wrapped in single quotes, where only the single quote itself needs
+// escaping: the quotes are closed, a backslash-escaped quote is added and
+// they are reopened. Newlines and other bytes are kept literally.
 func quoteForShell(value string) string {
-	// If no special characters, return as-is
-	if !strings.ContainsAny(value, " \t\n'\"$`\\!#&|;<>(){}[]") {
+	if value != "" && strings.Trim(value, shellSafeChars) == "" {
 		return value
 	}
-	// Use single quotes and escape any single quotes in the value
-	escaped := strings.ReplaceAll(value, "'", "'\"'\"'")
-	return "'" + escaped + "'"
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellSafeChars are the characters that never need quoting in a shell word
+const shellSafeChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_@%+=:,./-"
+
+// isShellIdentifier reports whether name is a valid shell variable name
+func isShellIdentifier(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
 }
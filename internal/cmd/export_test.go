@@ -0,0 +1,403 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFilterSecrets(t *testing.T) {
+	secrets := []string{"db/password", "db/replica/user", "api/key", "admin/token"}
+
+	tests := []struct {
+		name    string
+		only    []string
+		exclude []string
+		want    []string
+	}{
+		{"Only", []string{"db/*"}, nil, []string{"db/password"}},
+		{"OnlyMultiple", []string{"db/*", "api/*"}, nil, []string{"db/password", "api/key"}},
+		{"Exclude", nil, []string{"admin/*"}, []string{"db/password", "db/replica/user", "api/key"}},
+		{"Both", []string{"*/*"}, []string{"api/*"}, []string{"db/password", "admin/token"}},
+		{"NoMatch", []string{"missing/*"}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterSecrets(secrets, tt.only, tt.exclude)
+			if err != nil {
+				t.Fatalf("filterSecrets() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterSecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := filterSecrets(secrets, []string{"[db"}, nil); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
+
+func TestQuoteForShell(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "secret123", "secret123"},
+		{"url", "postgres://db.example.com:5432/app", "postgres://db.example.com:5432/app"},
+		{"empty", "", "''"},
+		{"space", "two words", "'two words'"},
+		{"single quote", "it's", `'it'\''s'`},
+		{"single quote then backtick", "a'`id`", `'a'\''` + "`id`'"},
+		{"command substitution", "$(rm -rf /)", "'$(rm -rf /)'"},
+		{"variable", "$HOME", "'$HOME'"},
+		{"backticks", "`id`", "'`id`'"},
+		{"newline", "line1\nline2", "'line1\nline2'"},
+		{"glob", "*", "'*'"},
+		{"tilde", "~/x", "'~/x'"},
+		{"backslash", `a\b`, `'a\b'`},
+		{"non-ascii", "pässwörd", "'pässwörd'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteForShell(tt.input); got != tt.want {
+				t.Errorf("quoteForShell(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteForShellRoundTrip(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	values := []string{"it's", "a'`id`'b", "$(echo pwned)", "line1\nline2\n", "'''", `\'\"`, "tab\there", "!event"}
+	for _, value := range values {
+		out, err := exec.Command(sh, "-c", "V="+quoteForShell(value)+"; printf '%s' \"$V\"").Output()
+		if err != nil {
+			t.Fatalf("sh failed for %q: %v", value, err)
+		}
+		if string(out) != value {
+			t.Errorf("round trip of %q = %q", value, out)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	rows := [][2]string{
+		{"DB_PASSWORD", "plain"},
+		{"NOTE", "has, comma"},
+		{"QUOTE", `say "hi"`},
+		{"CERT", "line1\nline2"},
+	}
+	if err := writeCSV(&buf, rows); err != nil {
+		t.Fatalf("writeCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != len(rows)+1 || records[0][0] != "name" || records[0][1] != "value" {
+		t.Fatalf("records = %q", records)
+	}
+	for i, row := range rows {
+		if records[i+1][0] != row[0] || records[i+1][1] != row[1] {
+			t.Errorf("row %d = %q, want %q", i, records[i+1], row)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	values := map[string]string{
+		"TAB":     "a\tb",
+		"CONTROL": "bell\x07",
+		"CERT":    "line1\nline2",
+		"QUOTE":   `say "hi" \ bye`,
+		"URL":     "https://example.com/?a=1&b=<2>",
+	}
+	for _, compact := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := writeJSON(&buf, values, compact); err != nil {
+			t.Fatalf("writeJSON() error = %v", err)
+		}
+		var got map[string]string
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+		}
+		if !reflect.DeepEqual(got, values) {
+			t.Errorf("round trip = %q, want %q", got, values)
+		}
+		if lines := strings.Count(buf.String(), "\n"); compact != (lines == 1) {
+			t.Errorf("compact = %v, got %d line(s)", compact, lines)
+		}
+		if strings.Index(buf.String(), "CERT") > strings.Index(buf.String(), "URL") {
+			t.Errorf("keys are not sorted:\n%s", buf.String())
+		}
+		if !strings.Contains(buf.String(), "&b=<2>") {
+			t.Errorf("HTML characters were escaped:\n%s", buf.String())
+		}
+	}
+}
+
+func TestParseExportTemplate(t *testing.T) {
+	data := exportTemplateData{
+		Vault: "dev",
+		Secrets: []exportSecret{
+			{Name: "db/password", EnvName: "APP_DB_PASSWORD", Value: "it's"},
+			{Name: "api-key", EnvName: "APP_API_KEY", Value: "k"},
+		},
+		Values: map[string]string{"db/password": "it's", "api-key": "k"},
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{`{{range .Secrets}}{{.EnvName}}={{quote .Value}};{{end}}`, `APP_DB_PASSWORD='it'\''s';APP_API_KEY=k;`},
+		{`[{{.Vault}}]{{range .Secrets}} {{env .Name}}={{b64 .Value}}{{end}}`, `[dev] DB_PASSWORD=aXQncw== API_KEY=aw==`},
+		{`{{index .Values "api-key"}}`, `k`},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseExportTemplate(tt.template)
+		if err != nil {
+			t.Fatalf("parseExportTemplate(%q) error = %v", tt.template, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			t.Fatalf("Execute(%q) error = %v", tt.template, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("template %q = %q, want %q", tt.template, buf.String(), tt.want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "vars.tmpl")
+	if err := os.WriteFile(path, []byte(`{{len .Secrets}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := parseExportTemplate(path)
+	if err != nil {
+		t.Fatalf("parseExportTemplate(file) error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil || buf.String() != "2" {
+		t.Errorf("file template = %q, %v", buf.String(), err)
+	}
+
+	if _, err := parseExportTemplate("missing.tmpl"); ExitCode(err) != ExitNotFound {
+		t.Errorf("missing file: err = %v", err)
+	}
+	if _, err := parseExportTemplate("{{.Secrets"); ExitCode(err) != ExitValidation {
+		t.Errorf("invalid template: err = %v", err)
+	}
+}
+
+func TestK8sSecretManifest(t *testing.T) {
+	values := map[string]string{
+		"DB_PASSWORD": "s3cret",
+		"TLS_CERT":    "-----BEGIN-----\nabc\n-----END-----",
+	}
+	data, err := k8sSecretManifest("app-secrets", "web", values)
+	if err != nil {
+		t.Fatalf("k8sSecretManifest() error = %v", err)
+	}
+
+	var secret k8sSecret
+	if err := yaml.Unmarshal(data, &secret); err != nil {
+		t.Fatalf("manifest is not valid YAML: %v", err)
+	}
+	if secret.APIVersion != "v1" || secret.Kind != "Secret" || secret.Metadata.Name != "app-secrets" || secret.Metadata.Namespace != "web" {
+		t.Errorf("unexpected header: %+v", secret)
+	}
+	for key, want := range values {
+		got, err := base64.StdEncoding.DecodeString(secret.Data[key])
+		if err != nil {
+			t.Fatalf("data[%s] is not standard base64: %v", key, err)
+		}
+		if string(got) != want {
+			t.Errorf("data[%s] = %q, want %q", key, got, want)
+		}
+	}
+
+	if _, err := k8sSecretManifest("app", "", map[string]string{"db/password": "x"}); err == nil {
+		t.Error("expected error for a key containing '/'")
+	}
+}
+
+func TestDockerEnvFile(t *testing.T) {
+	rows := [][2]string{
+		{"DB_PASSWORD", `it's "quoted"`},
+		{"EMPTY", ""},
+		{"TLS_CERT", "line1\nline2"},
+	}
+	if _, err := dockerEnvFile(rows, false); err == nil || !strings.Contains(err.Error(), "TLS_CERT") {
+		t.Errorf("expected error naming the multiline value, got %v", err)
+	}
+
+	data, err := dockerEnvFile(rows, true)
+	if err != nil {
+		t.Fatalf("dockerEnvFile() error = %v", err)
+	}
+	want := "DB_PASSWORD=it's \"quoted\"\nEMPTY=\nTLS_CERT=" + base64.StdEncoding.EncodeToString([]byte("line1\nline2")) + "\n"
+	if string(data) != want {
+		t.Errorf("dockerEnvFile() = %q, want %q", data, want)
+	}
+
+	_, err = dockerEnvFile([][2]string{{"1ST", "a"}, {"MY VAR", "b"}, {"OK", "c"}}, false)
+	if err == nil || !strings.Contains(err.Error(), `"1ST"`) || !strings.Contains(err.Error(), `"MY VAR"`) {
+		t.Errorf("expected error naming both invalid names, got %v", err)
+	}
+}
+
+func TestDockerRunCommand(t *testing.T) {
+	data, err := dockerRunCommand([][2]string{{"DB_PASSWORD", "it's"}, {"TLS_CERT", "a\nb"}})
+	if err != nil {
+		t.Fatalf("dockerRunCommand() error = %v", err)
+	}
+	want := "docker run \\\n  -e 'DB_PASSWORD=it'\\''s' \\\n  -e 'TLS_CERT=a\nb' \\\n  IMAGE\n"
+	if string(data) != want {
+		t.Errorf("dockerRunCommand() = %q, want %q", data, want)
+	}
+
+	if _, err := dockerRunCommand([][2]string{{"bad-name", "x"}}); err == nil {
+		t.Error("expected error for an invalid variable name")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("OLD=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("NEW=2\n"), 0600); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "NEW=2\n" {
+		t.Errorf("file = %q, %v, want %q", data, err, "NEW=2\n")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestSyncVaultDryRunImportsNothing(t *testing.T) {
+	t.Setenv("GNUPGHOME", t.TempDir())
+	secretsDir := t.TempDir()
+	vaultDir := config.GetVaultDir(secretsDir, "dev")
+	if err := os.MkdirAll(config.GetStoreDir(secretsDir, "dev"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	vaultCfg := &config.VaultConfig{
+		Name:    "dev",
+		Members: []string{"alice@example.com"},
+		Groups:  map[string][]string{"ops": {"alice@example.com"}},
+	}
+	if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveGroups(secretsDir, config.Groups{"ops": {"alice@example.com", "bob@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Importing this would fail, so the dry run must not try
+	keysDir := config.GetKeysDir(secretsDir)
+	if err := os.MkdirAll(keysDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(keysDir, "bob@example.com.asc"), []byte("not a key\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dryRun = true
+	defer func() { dryRun = false }()
+	if err := syncVault(secretsDir, "dev"); err != nil {
+		t.Fatalf("syncVault() dry run error = %v", err)
+	}
+	saved, err := config.LoadVaultConfig(vaultDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved.Members, []string{"alice@example.com"}) {
+		t.Errorf("members after dry run = %v", saved.Members)
+	}
+
+	// A member without a key is still reported by the plan
+	if err := os.Remove(filepath.Join(keysDir, "bob@example.com.asc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncVault(secretsDir, "dev"); err == nil || !strings.Contains(err.Error(), "key not found for group member bob@example.com") {
+		t.Errorf("missing key: err = %v", err)
+	}
+}
+
+func TestRunExportRaw(t *testing.T) {
+	fakePass(t)
+	secretsDir := t.TempDir()
+	t.Setenv("SECRETS_DIR", secretsDir)
+	t.Setenv("USER_EMAIL", "alice@example.com")
+	if err := config.SaveConfig(secretsDir, &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	storeDir := config.GetStoreDir(secretsDir, "dev")
+	values := map[string]string{
+		"db/password":  `p@ss "word" $HOME`,
+		"api-key":      "k'ey",
+		"app/base.url": "https://example.com/?a=1&b=2",
+	}
+	for name, value := range values {
+		path := filepath.Join(storeDir, name+".gpg")
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.SaveVaultConfig(config.GetVaultDir(secretsDir, "dev"), &config.VaultConfig{Name: "dev", Members: []string{"alice@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	exportFormat = "raw"
+	defer func() { exportFormat = "env" }()
+	// Paths and values come out as stored: no prefix, renaming or quoting
+	exportPrefix = "APP_"
+	defer func() { exportPrefix = "" }()
+	out, err := captureStdout(t, func() error { return runExport(exportCmd, []string{"dev"}) })
+	if err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	want := "api-key\tk'ey\n" +
+		"app/base.url\thttps://example.com/?a=1&b=2\n" +
+		"db/password\tp@ss \"word\" $HOME\n"
+	if out != want {
+		t.Errorf("export --format raw =\n%s\nwant\n%s", out, want)
+	}
+
+	exportOnly = []string{"db/*"}
+	defer func() { exportOnly = nil }()
+	out, err = captureStdout(t, func() error { return runExport(exportCmd, []string{"dev"}) })
+	if err != nil || out != "db/password\tp@ss \"word\" $HOME\n" {
+		t.Errorf("export --format raw --only db/* = %q, %v", out, err)
+	}
+}
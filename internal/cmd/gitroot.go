@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
 // FindGitRoot traverses up from the current directory to find the git repository root.
@@ -48,3 +50,74 @@ func RequireGitRepository() (string, error) {
 	}
 	return gitRoot, nil
 }
+
+// gitIgnored reports whether git ignores path. ok is false when path is not
+// in a git repository or git cannot tell.
+func gitIgnored(path string) (ignored, ok bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false, false
+	}
+	cmd := exec.Command("git", "-C", filepath.Dir(abs), "check-ignore", "-q", "--", abs)
+	err = cmd.Run()
+	if err == nil {
+		return true, true
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, true
+	}
+	return false, false
+}
+
+// gitIgnoredPaths returns the paths that the git repository at gitRoot
+// ignores. The paths need not exist; paths outside the repository are
+// skipped.
+func gitIgnoredPaths(gitRoot string, paths []string) ([]string, error) {
+	var inside []string
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		if isOutside(gitRoot, abs) {
+			continue
+		}
+		inside = append(inside, abs)
+	}
+	if len(inside) == 0 {
+		return nil, nil
+	}
+
+	cmd := exec.Command("git", append([]string{"-C", gitRoot, "check-ignore", "--"}, inside...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil // nothing is ignored
+		}
+		return nil, fmt.Errorf("git check-ignore failed: %w", err)
+	}
+
+	var ignored []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			ignored = append(ignored, line)
+		}
+	}
+	return ignored, nil
+}
+
+// isOutside reports whether path is outside the directory root
+func isOutside(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// relToRoot returns path relative to root for display, or path unchanged
+func relToRoot(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && !isOutside(root, path) {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage member groups for vault access",
+	Long: `Manage groups of members stored in .secrets/groups.yaml.
+
+A group can be added to a vault with 'secrets-cli vault add-member <vault>
+@group', which adds each of its members. After changing a group, run
+'secrets-cli sync <vault>' for each vault it was added to, so members
+added to the group gain access and members removed from it lose access.
+
+Examples:
+  secrets-cli group add backend alice@example.com bob@example.com
+  secrets-cli group list
+  secrets-cli group remove backend bob@example.com
+  secrets-cli group remove backend`,
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List groups and their members",
+	Args:  cobra.NoArgs,
+	RunE:  runGroupList,
+}
+
+var groupAddCmd = &cobra.Command{
+	Use:   "add <group> <email>...",
+	Short: "Add members to a group, creating it if needed",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runGroupAdd,
+}
+
+var groupRemoveCmd = &cobra.Command{
+	Use:   "remove <group> [email...]",
+	Short: "Remove members from a group, or the whole group",
+	Long: `Remove members from a group. Without emails the whole group is removed.
+
+Vault access only changes when each affected vault is synced.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runGroupRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(groupCmd)
+	groupCmd.AddCommand(groupListCmd)
+	groupCmd.AddCommand(groupAddCmd)
+	groupCmd.AddCommand(groupRemoveCmd)
+}
+
+func runGroupList(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	groups, err := config.LoadGroups(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No groups found. Create one with: secrets-cli group add <group> <email>...")
+		return nil
+	}
+
+	fmt.Println("Groups:")
+	for _, name := range groups.Names() {
+		fmt.Printf("  @%s\n", name)
+		for _, member := range groups[name] {
+			fmt.Printf("    - %s\n", member)
+		}
+	}
+	return nil
+}
+
+func runGroupAdd(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	groupName := args[0]
+	emails := args[1:]
+
+	if err := validateName(groupName); err != nil {
+		return err
+	}
+	for _, email := range emails {
+		if err := validateEmail(email); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	groups, err := config.LoadGroups(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	members := groups[groupName]
+	for _, email := range emails {
+		exists := false
+		for _, m := range members {
+			if strings.EqualFold(m, email) {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			fmt.Printf("  %s is already in @%s\n", email, groupName)
+			continue
+		}
+		members = append(members, email)
+		fmt.Printf("✓ Added %s to @%s\n", email, groupName)
+		recordChange("add %s to @%s", email, groupName)
+	}
+	groups[groupName] = members
+
+	if err := config.SaveGroups(secretsDir, groups); err != nil {
+		return err
+	}
+
+	printGroupSyncHint(secretsDir, groupName)
+	return nil
+}
+
+func runGroupRemove(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	groupName := args[0]
+	emails := args[1:]
+
+	if err := validateName(groupName); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	groups, err := config.LoadGroups(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	members, ok := groups[groupName]
+	if !ok {
+		return notFoundErrorf("group not found: %s", groupName)
+	}
+
+	if len(emails) == 0 {
+		delete(groups, groupName)
+		fmt.Printf("✓ Removed group @%s\n", groupName)
+		recordChange("remove group @%s", groupName)
+	} else {
+		for _, email := range emails {
+			var kept []string
+			for _, m := range members {
+				if !strings.EqualFold(m, email) {
+					kept = append(kept, m)
+				}
+			}
+			if len(kept) == len(members) {
+				return fmt.Errorf("%s is not in @%s", email, groupName)
+			}
+			members = kept
+			fmt.Printf("✓ Removed %s from @%s\n", email, groupName)
+			recordChange("remove %s from @%s", email, groupName)
+		}
+		groups[groupName] = members
+	}
+
+	if err := config.SaveGroups(secretsDir, groups); err != nil {
+		return err
+	}
+
+	printGroupSyncHint(secretsDir, groupName)
+	return nil
+}
+
+// printGroupSyncHint lists the vaults a group was added to, which need a
+// sync for the change to take effect
+func printGroupSyncHint(secretsDir, groupName string) {
+	vaults, err := config.ListVaults(secretsDir)
+	if err != nil {
+		return
+	}
+
+	var affected []string
+	for _, vaultName := range vaults {
+		vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+		if err != nil {
+			continue
+		}
+		if _, ok := vaultCfg.Groups[groupName]; ok {
+			affected = append(affected, vaultName)
+		}
+	}
+
+	if len(affected) > 0 {
+		fmt.Println()
+		fmt.Printf("@%s is used by: %s\n", groupName, strings.Join(affected, ", "))
+		fmt.Println("Run 'secrets-cli sync <vault>' for each to apply the change")
+	}
+}
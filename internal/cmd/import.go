@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <vault> <file>",
+	Short: "Import secrets from a dotenv file",
+	Long: `Import secrets from a dotenv file (VAR=value lines) into a vault.
+
+Variable names are turned into secret paths by lowercasing them and
+replacing underscores with slashes, e.g. DB_PASSWORD becomes db/password.
+Use --prefix to place every imported secret under a namespace so it can't
+collide with existing secrets, then reorganize with 'rename'.
+
+Existing secrets are never overwritten unless --force is given.
+
+Examples:
+  secrets-cli import dev .env
+  secrets-cli import shared legacy.env --prefix legacy   # DB_PASSWORD -> legacy/db/password`,
+	Args: cobra.ExactArgs(2),
+	RunE: runImport,
+}
+
+var (
+	importPrefix string
+	importForce  bool
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importPrefix, "prefix", "", "Path prefix for every imported secret")
+	importCmd.Flags().BoolVarP(&importForce, "force", "f", false, "Overwrite secrets that already exist")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName := args[0]
+	filePath := args[1]
+
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+
+	if err := checkWriteAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+	if err := checkNotArchived(secretsDir, vaultName); err != nil {
+		return err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer f.Close()
+
+	entries, err := parseDotenv(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no variables found in %s", filePath)
+	}
+
+	// Resolve and validate every name before writing anything
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	p := newPass(storeDir)
+	existing, _ := p.List()
+	existingSet := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		existingSet[s] = true
+	}
+
+	names := make([]string, len(entries))
+	var conflicts []string
+	for i, e := range entries {
+		name := envNameToSecret(e.key, importPrefix)
+		if err := validateSecretName(name); err != nil {
+			return fmt.Errorf("cannot import %s: %w", e.key, err)
+		}
+		if existingSet[name] {
+			conflicts = append(conflicts, name)
+		}
+		names[i] = name
+	}
+	if len(conflicts) > 0 && !importForce {
+		return fmt.Errorf("%d secret(s) already exist in %s: %s. Use --prefix to namespace the import or --force to overwrite",
+			len(conflicts), vaultName, strings.Join(conflicts, ", "))
+	}
+
+	for i, e := range entries {
+		if err := p.Insert(names[i], e.value); err != nil {
+			return fmt.Errorf("failed to import %s: %w", e.key, err)
+		}
+		if IsVerbose() {
+			fmt.Printf("  %s -> %s/%s\n", e.key, vaultName, names[i])
+		}
+	}
+
+	fmt.Printf("✓ Imported %d secret(s) into %s\n", len(entries), vaultName)
+	recordChange("import %d secret(s) into %s", len(entries), vaultName)
+	return nil
+}
+
+// dotenvEntry is a single VAR=value assignment
+type dotenvEntry struct {
+	key   string
+	value string
+}
+
+// parseDotenv reads VAR=value lines, skipping blank lines and comments and
+// accepting an optional "export " prefix and single or double quotes
+func parseDotenv(f *os.File) ([]dotenvEntry, error) {
+	var entries []dotenvEntry
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected VAR=value", lineNo)
+		}
+		entries = append(entries, dotenvEntry{key: key, value: unquoteDotenv(strings.TrimSpace(value))})
+	}
+	return entries, scanner.Err()
+}
+
+// unquoteDotenv strips dotenv quoting. Single quotes are literal; double
+// quotes support \n, \" and \\ escapes.
+func unquoteDotenv(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return value[1 : len(value)-1]
+		case value[0] == '"' && value[len(value)-1] == '"':
+			r := strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`)
+			return r.Replace(value[1 : len(value)-1])
+		}
+	}
+	return value
+}
+
+// envNameToSecret converts an environment variable name to a secret path,
+// the inverse of secretToEnvName: "DB_PASSWORD" -> "db/password"
+func envNameToSecret(key, prefix string) string {
+	name := strings.ReplaceAll(strings.ToLower(key), "_", "/")
+	if prefix != "" {
+		name = strings.TrimSuffix(prefix, "/") + "/" + name
+	}
+	return name
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import "testing"
+
+func TestEnvNameToSecret(t *testing.T) {
+	tests := []struct {
+		key    string
+		prefix string
+		want   string
+	}{
+		{"DB_PASSWORD", "", "db/password"},
+		{"DB_PASSWORD", "legacy", "legacy/db/password"},
+		{"API_KEY", "legacy/", "legacy/api/key"},
+		{"TOKEN", "imports/2024", "imports/2024/token"},
+	}
+
+	for _, tt := range tests {
+		if got := envNameToSecret(tt.key, tt.prefix); got != tt.want {
+			t.Errorf("envNameToSecret(%q, %q) = %q, want %q", tt.key, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestUnquoteDotenv(t *testing.T) {
+	tests := map[string]string{
+		`plain`:          "plain",
+		`'single $HOME'`: "single $HOME",
+		`"line1\nline2"`: "line1\nline2",
+		`"say \"hi\""`:   `say "hi"`,
+		`"`:              `"`,
+	}
+
+	for in, want := range tests {
+		if got := unquoteDotenv(in); got != want {
+			t.Errorf("unquoteDotenv(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
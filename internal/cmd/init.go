@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/NuevaNext/secrets-cli/internal/config"
-	"github.com/NuevaNext/secrets-cli/internal/gpg"
 	"github.com/spf13/cobra"
 )
 
@@ -21,17 +21,65 @@ This command creates the .secrets directory structure containing:
   - keys/ directory for GPG public keys
   - vaults/ directory for secret vaults
 
+Use --store-dir-layout shared to keep all vaults in one password store
+(e.g. an existing ~/.password-store, set with --shared-store), with one
+subdirectory and .gpg-id per vault. Access checks still come from each
+vault's vault.yaml, not from the store.
+
+Use --recipient-format fingerprint to write each recipient's full key
+fingerprint to .gpg-id instead of its email, so secrets are never
+encrypted for another key that shares the email. Existing stores can
+switch with 'secrets-cli migrate-recipients'.
+
+The .secrets directory must be committed for teammates to receive it. init
+warns if git would ignore any part of it, e.g. because of a blanket
+.gitignore rule, and --fix-gitignore appends rules re-including it to the
+repository's .gitignore. It also warns about unencrypted files in an
+existing shared store that git would commit.
+
+Use --adopt to wrap an existing pass store: it is copied as it is into a
+new vault (named after the store's directory, or --adopt-vault) without
+re-encrypting anything. The recipients in its .gpg-id, whether emails,
+key IDs or fingerprints, become the vault's members through the emails of
+their keys in your keyring; any that cannot be mapped are reported and
+left out. Their public keys are exported to keys/.
+
 You must have a GPG key pair for your email address. If not, create one with:
   gpg --gen-key
+If several keys in your keyring have your email, init lists their
+fingerprints; choose the one to use with --key-id.
 
 Examples:
   secrets-cli init --email you@example.com
-  secrets-cli init --email you@example.com --secrets-dir ./my-secrets`,
+  secrets-cli init --email you@example.com --secrets-dir ./my-secrets
+  secrets-cli init --email you@example.com --store-dir-layout shared --shared-store ~/.password-store
+  secrets-cli init --email you@example.com --recipient-format fingerprint
+  secrets-cli init --email you@example.com --fix-gitignore
+  secrets-cli init --email you@example.com --key-id 0123456789ABCDEF0123456789ABCDEF01234567
+  secrets-cli init --email you@example.com --adopt ~/.password-store --adopt-vault team`,
 	RunE: runInit,
 }
 
+var (
+	initStoreLayout string
+	initSharedStore string
+	initRecipients  string
+	initFixIgnore   bool
+	initAdopt       string
+	initAdoptVault  string
+	initKeyID       string
+)
+
 func init() {
 	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringVar(&initStoreLayout, "store-dir-layout", config.StoreLayoutPerVault, "Password store layout: per-vault, shared")
+	initCmd.Flags().StringVar(&initSharedStore, "shared-store", "", "Password store for the shared layout (default: <secrets-dir>/password-store)")
+	initCmd.Flags().StringVar(&initRecipients, "recipient-format", config.RecipientFormatEmail, "How recipients are written to .gpg-id: email, fingerprint")
+	initCmd.Flags().StringVar(&initAdopt, "adopt", "", "Register this existing pass store as a vault, without re-encrypting it")
+	initCmd.Flags().StringVar(&initAdoptVault, "adopt-vault", "", "Name of the vault created by --adopt (default: the store's directory name)")
+	initCmd.Flags().StringVar(&initKeyID, "key-id", "", "Fingerprint of your key when several keys have your email")
+	initCmd.Flags().BoolVar(&initFixIgnore, "fix-gitignore", false, "Append rules to .gitignore so the secrets directory is not ignored")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -47,6 +95,19 @@ func runInit(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	email := GetUserEmail()
 
+	if err := config.ValidateStoreLayout(initStoreLayout); err != nil {
+		return err
+	}
+	if err := config.ValidateRecipientFormat(initRecipients); err != nil {
+		return err
+	}
+	if initAdopt != "" && initStoreLayout != config.StoreLayoutPerVault {
+		return validationErrorf("--adopt only works with the per-vault layout")
+	}
+	if initAdopt == "" && initAdoptVault != "" {
+		return validationErrorf("--adopt-vault requires --adopt")
+	}
+
 	// Check if already initialized
 	if _, err := os.Stat(secretsDir); !os.IsNotExist(err) {
 		return fmt.Errorf("secrets directory already exists: %s. Remove it first or use a different path", secretsDir)
@@ -56,12 +117,30 @@ func runInit(cmd *cobra.Command, args []string) error {
 	if email == "" {
 		return fmt.Errorf("email is required. Use --email flag or set USER_EMAIL environment variable")
 	}
+	if err := validateEmail(email); err != nil {
+		return err
+	}
 
 	// Check GPG key exists
-	g := gpg.New(GetGPGBinary())
+	g := newGPG()
 	if !g.KeyExists(email) {
 		return fmt.Errorf("no GPG key found for %s. Generate one with: gpg --gen-key", email)
 	}
+	fpr, err := chooseKey(g, email, initKeyID)
+	if err != nil {
+		return err
+	}
+	if initKeyID != "" && !g.SecretKeyExists(fpr) {
+		return notFoundErrorf("no secret key for %s in your keyring; --key-id must name one of your own keys", fpr)
+	}
+
+	// Read the store to adopt before creating anything
+	var adopted *adoption
+	if initAdopt != "" {
+		if adopted, err = planAdoption(g, initAdopt, initAdoptVault); err != nil {
+			return err
+		}
+	}
 
 	// Create directory structure
 	dirs := []string{
@@ -76,23 +155,46 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := config.EnsureLockIgnored(secretsDir); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Join(secretsDir, ".gitignore"), err)
+	}
+
 	// Create config.yaml
 	cfg := &config.Config{
 		Version: "1",
 		Owner:   email,
 	}
+	if initStoreLayout == config.StoreLayoutShared {
+		cfg.StoreLayout = initStoreLayout
+		cfg.SharedStore = initSharedStore
+	}
+	if initRecipients == config.RecipientFormatFingerprint {
+		cfg.RecipientFormat = initRecipients
+	}
 	if err := config.SaveConfig(secretsDir, cfg); err != nil {
 		return fmt.Errorf("failed to create config: %w", err)
 	}
 
 	// Export owner's public key
 	keyPath := filepath.Join(secretsDir, "keys", email+".asc")
-	if err := g.ExportPublicKeyToFile(email, keyPath); err != nil {
+	if err := g.ExportPublicKeyToFile(fpr, keyPath); err != nil {
 		return fmt.Errorf("failed to export public key: %w", err)
 	}
 
 	fmt.Printf("✓ Initialized secrets store in %s\n", secretsDir)
+	recordChange("init")
 	fmt.Printf("✓ Exported your public key to %s\n", keyPath)
+
+	if adopted != nil {
+		if err := adoptStore(g, secretsDir, email, adopted); err != nil {
+			return err
+		}
+	}
+
+	if err := checkStoreIgnored(gitRoot, secretsDir, email); err != nil {
+		fmt.Printf("⚠ Could not check .gitignore: %v\n", err)
+	}
+
 	fmt.Println()
 	fmt.Println("Next steps:")
 	fmt.Println("  1. Create a vault:  secrets-cli vault create <name>")
@@ -102,6 +204,127 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// checkStoreIgnored warns if git would ignore part of a new secrets store,
+// re-including it with --fix-gitignore, and if an existing shared store has
+// unencrypted files git would commit
+func checkStoreIgnored(gitRoot, secretsDir, email string) error {
+	// Vaults do not exist yet; git matches patterns against placeholder paths
+	storeDir := config.GetStoreDir(secretsDir, "<vault>")
+	samples := []string{
+		secretsDir,
+		filepath.Join(secretsDir, "config.yaml"),
+		filepath.Join(secretsDir, "keys", email+".asc"),
+		filepath.Join(secretsDir, "vaults", "<vault>", "vault.yaml"),
+		filepath.Join(storeDir, ".gpg-id"),
+		filepath.Join(storeDir, "<secret>.gpg"),
+	}
+	ignored, err := gitIgnoredPaths(gitRoot, samples)
+	if err != nil {
+		return err
+	}
+
+	if len(ignored) > 0 {
+		rules, err := unignoreRules(gitRoot, secretsDir)
+		if err != nil {
+			return err
+		}
+		if initFixIgnore {
+			gitignore := filepath.Join(gitRoot, ".gitignore")
+			if err := appendGitignoreRules(gitignore, rules); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Added %s to %s\n", strings.Join(rules, " and "), gitignore)
+			if ignored, err = gitIgnoredPaths(gitRoot, samples); err != nil {
+				return err
+			}
+		}
+		if abs, _ := filepath.Abs(secretsDir); len(ignored) > 0 && ignored[0] == abs {
+			ignored = ignored[:1] // everything below is ignored too
+		}
+		for _, path := range ignored {
+			fmt.Printf("⚠ git ignores %s, so teammates would not receive it\n", relToRoot(gitRoot, path))
+		}
+		if len(ignored) > 0 && initFixIgnore {
+			fmt.Println("  A parent directory is probably ignored; git cannot re-include files below it")
+		} else if len(ignored) > 0 {
+			fmt.Printf("  Add these lines to %s, or use 'init --fix-gitignore':\n", filepath.Join(gitRoot, ".gitignore"))
+			for _, rule := range rules {
+				fmt.Printf("    %s\n", rule)
+			}
+		}
+	}
+
+	// Per-vault stores are new and empty, but a shared store may not be
+	if cfg, err := config.LoadConfig(secretsDir); err == nil && cfg.StoreLayout == config.StoreLayoutShared {
+		root := config.SharedStoreDir(secretsDir, cfg.SharedStore)
+		plaintext, err := config.FindPlaintextFiles(root)
+		if err != nil {
+			return err
+		}
+		var paths []string
+		for _, file := range plaintext {
+			paths = append(paths, filepath.Join(root, file))
+		}
+		ignoredPlain, err := gitIgnoredPaths(gitRoot, paths)
+		if err != nil {
+			return err
+		}
+		skip := map[string]bool{}
+		for _, path := range ignoredPlain {
+			skip[path] = true
+		}
+		for _, path := range paths {
+			if abs, err := filepath.Abs(path); err == nil && !skip[abs] && !isOutside(gitRoot, abs) {
+				fmt.Printf("⚠ %s is not encrypted and would be committed\n", relToRoot(gitRoot, abs))
+			}
+		}
+	}
+
+	return nil
+}
+
+// unignoreRules returns the .gitignore rules that re-include secretsDir and
+// everything below it
+func unignoreRules(gitRoot, secretsDir string) ([]string, error) {
+	abs, err := filepath.Abs(secretsDir)
+	if err != nil {
+		return nil, err
+	}
+	if isOutside(gitRoot, abs) {
+		return nil, fmt.Errorf("%s is outside the git repository %s", secretsDir, gitRoot)
+	}
+	rel, _ := filepath.Rel(gitRoot, abs)
+	rel = filepath.ToSlash(rel)
+	return []string{"!/" + rel + "/", "!/" + rel + "/**"}, nil
+}
+
+// appendGitignoreRules appends rules to a .gitignore, creating it if needed
+func appendGitignoreRules(path string, rules []string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var b strings.Builder
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("# The secrets-cli store is meant to be committed\n")
+	for _, rule := range rules {
+		b.WriteString(rule + "\n")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
 // Helper to get current time in ISO format
 func nowISO() string {
 	return time.Now().UTC().Format(time.RFC3339)
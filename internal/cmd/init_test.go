@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUnignoreRules(t *testing.T) {
+	root := t.TempDir()
+	got, err := unignoreRules(root, filepath.Join(root, "config", ".secrets"))
+	if err != nil {
+		t.Fatalf("unignoreRules() error = %v", err)
+	}
+	want := []string{"!/config/.secrets/", "!/config/.secrets/**"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unignoreRules() = %v, want %v", got, want)
+	}
+
+	if _, err := unignoreRules(root, filepath.Join(filepath.Dir(root), "elsewhere")); err == nil {
+		t.Error("unignoreRules() accepted a directory outside the repository")
+	}
+}
+
+func TestAppendGitignoreRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gitignore")
+	if err := os.WriteFile(path, []byte(".secrets"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendGitignoreRules(path, []string{"!/.secrets/", "!/.secrets/**"}); err != nil {
+		t.Fatalf("appendGitignoreRules() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	want := ".secrets\n# The secrets-cli store is meant to be committed\n!/.secrets/\n!/.secrets/**\n"
+	if string(data) != want {
+		t.Errorf(".gitignore = %q, want %q", data, want)
+	}
+}
+
+func TestAdoptedVaultName(t *testing.T) {
+	tests := map[string]string{
+		"/home/alice/.password-store": "password-store",
+		"/srv/team":                   "team",
+		"/srv/...":                    "default",
+	}
+	for source, want := range tests {
+		if got := adoptedVaultName(source); got != want {
+			t.Errorf("adoptedVaultName(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
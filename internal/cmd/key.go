@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/NuevaNext/secrets-cli/internal/config"
 	"github.com/NuevaNext/secrets-cli/internal/gpg"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var keyCmd = &cobra.Command{
@@ -22,6 +27,7 @@ Examples:
   secrets-cli key list
   secrets-cli key add alice@example.com
   secrets-cli key add bob@example.com --key-file ./bob.asc
+  secrets-cli key show alice@example.com
   secrets-cli key import`,
 }
 
@@ -35,17 +41,40 @@ These keys can be used to add members to vaults.`,
 }
 
 var keyAddCmd = &cobra.Command{
-	Use:   "add <email>",
+	Use:   "add <email> | add --all-from-keyring",
 	Short: "Add a team member's public key",
 	Long: `Add a team member's public key to the secrets repository.
 
 If the key exists in your GPG keyring, it will be exported automatically.
-Otherwise, use --key-file to specify an ASCII-armored key file.
+Otherwise, use --key-file to specify an ASCII-armored key file, or
+--from-stdin (or its alias --stdin-key-file) to paste one or pass it as a
+heredoc in scripts. If several keys in your keyring have the email, the
+command lists their fingerprints and you choose one with --key-id. A
+pasted key must be a complete PGP public key block with a user ID for
+<email>; it is checked in a temporary keyring before it is saved.
+
+Keys that are revoked, expired or have no usable encryption subkey are
+refused, whatever their source, since re-encrypting a vault for them
+fails. Keys expiring within 30 days are added with a warning.
+
+--all-from-keyring adds every public key in your GPG keyring at once,
+e.g. when setting up a store for a team whose keys you already have. Keys
+already in keys/ are skipped, as are keys without an email in their user
+ID (with a warning). --filter limits it to emails containing a substring,
+such as your company's domain.
 
 Examples:
   secrets-cli key add alice@example.com                # Export from GPG keyring
-  secrets-cli key add bob@example.com --key-file ./bob.asc  # From file`,
-	Args: cobra.ExactArgs(1),
+  secrets-cli key add bob@example.com --key-file ./bob.asc  # From file
+  secrets-cli key add carol@example.com --from-stdin < carol.asc
+  secrets-cli key add erin@example.com --from-stdin <<'EOF'
+  -----BEGIN PGP PUBLIC KEY BLOCK-----
+  ...
+  -----END PGP PUBLIC KEY BLOCK-----
+  EOF
+  secrets-cli key add dave@example.com --key-id 0123456789ABCDEF0123456789ABCDEF01234567
+  secrets-cli key add --all-from-keyring --filter @example.com`,
+	Args: cobra.RangeArgs(0, 1),
 	RunE: runKeyAdd,
 }
 
@@ -59,33 +88,76 @@ Note: This does not revoke access to vaults. Use 'vault remove-member' first.`,
 	RunE: runKeyRemove,
 }
 
+var keyShowCmd = &cobra.Command{
+	Use:   "show <email>",
+	Short: "Show a stored key's fingerprint and dates",
+	Long: `Show the details of a stored public key: user IDs, fingerprint, key ID,
+creation date and expiry.
+
+The key is read from keys/<email>.asc without importing it, so this works
+even if the key is not in your GPG keyring. Use --fingerprint-only to print
+just the fingerprint, e.g. to verify it with the key's owner.
+
+Examples:
+  secrets-cli key show alice@example.com
+  secrets-cli key show alice@example.com --fingerprint-only`,
+	Args: cobra.ExactArgs(1),
+	RunE: runKeyShow,
+}
+
 var keyImportCmd = &cobra.Command{
 	Use:   "import",
 	Short: "Import all stored keys to your GPG keyring",
 	Long: `Import all stored public keys into your local GPG keyring.
 
 This is typically run after cloning a repository with secrets, or is 
-called automatically by 'secrets-cli setup'.`,
+called automatically by 'secrets-cli setup'.
+
+Each key is reported as imported, updated, already present or failed,
+with gpg's error for failures. Use --only (repeatable) to import just the
+keys of specific members. The command fails if any key failed to import.
+
+Examples:
+  secrets-cli key import
+  secrets-cli key import --only alice@example.com --only bob@example.com`,
+	Args: cobra.NoArgs,
 	RunE: runKeyImport,
 }
 
-var keyFile string
+var (
+	keyFile            string
+	keyFromStdin       bool
+	keyFingerprintOnly bool
+	keyImportOnly      []string
+	keyAllFromKeyring  bool
+	keyFilter          string
+	keyID              string
+)
 
 func init() {
 	rootCmd.AddCommand(keyCmd)
 	keyCmd.AddCommand(keyListCmd)
 	keyCmd.AddCommand(keyAddCmd)
 	keyCmd.AddCommand(keyRemoveCmd)
+	keyCmd.AddCommand(keyShowCmd)
 	keyCmd.AddCommand(keyImportCmd)
 
 	keyAddCmd.Flags().StringVar(&keyFile, "key-file", "", "Path to key file (optional)")
+	keyAddCmd.Flags().BoolVar(&keyFromStdin, "from-stdin", false, "Read an armored public key from stdin")
+	keyAddCmd.Flags().BoolVar(&keyFromStdin, "stdin-key-file", false, "Alias for --from-stdin")
+	_ = keyAddCmd.Flags().MarkHidden("stdin-key-file")
+	keyAddCmd.Flags().BoolVar(&keyAllFromKeyring, "all-from-keyring", false, "Add every public key in your GPG keyring")
+	keyAddCmd.Flags().StringVar(&keyID, "key-id", "", "Fingerprint of the key to export when several keys have the email")
+	keyAddCmd.Flags().StringVar(&keyFilter, "filter", "", "With --all-from-keyring, only add keys whose email contains this (e.g. @example.com)")
+	keyImportCmd.Flags().StringArrayVar(&keyImportOnly, "only", nil, "Import only the key of this email (repeatable)")
+	keyShowCmd.Flags().BoolVar(&keyFingerprintOnly, "fingerprint-only", false, "Print only the fingerprint")
 }
 
 func runKeyList(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	keysDir := config.GetKeysDir(secretsDir)
@@ -113,14 +185,27 @@ func runKeyList(cmd *cobra.Command, args []string) error {
 
 func runKeyAdd(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
+
+	if keyAllFromKeyring {
+		if len(args) > 0 || keyFile != "" || keyFromStdin || keyID != "" {
+			return validationErrorf("--all-from-keyring cannot be combined with an email, --key-file, --from-stdin or --key-id")
+		}
+		return runKeyAddAllFromKeyring(secretsDir)
+	}
+	if keyFilter != "" {
+		return validationErrorf("--filter requires --all-from-keyring")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("requires an email (or --all-from-keyring)")
+	}
 	email := args[0]
 
-	if err := validateName(email); err != nil {
+	if err := validateEmail(email); err != nil {
 		return err
 	}
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	keysDir := config.GetKeysDir(secretsDir)
@@ -131,14 +216,47 @@ func runKeyAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("key already exists for %s", email)
 	}
 
-	g := gpg.New(GetGPGBinary())
+	if keyFile != "" && keyFromStdin {
+		return validationErrorf("--key-file and --from-stdin cannot be used together")
+	}
+	if keyID != "" && (keyFile != "" || keyFromStdin) {
+		return validationErrorf("--key-id only applies to keys exported from your keyring")
+	}
+
+	g := newGPG()
 
-	if keyFile != "" {
+	var warning string // set if the key expires soon
+	if keyFromStdin {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Fprintln(os.Stderr, "Paste the armored public key, then press Ctrl-D:")
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read key from stdin: %w", err)
+		}
+		keys, err := g.ValidatePublicKey(data, email)
+		if err != nil {
+			return validationErrorf("invalid key for %s: %v", email, err)
+		}
+		if warning, err = checkKeyUsable(keys, email, time.Now()); err != nil {
+			return err
+		}
+		if err := os.WriteFile(keyPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write key: %w", err)
+		}
+	} else if keyFile != "" {
 		// Copy from specified file
 		data, err := os.ReadFile(keyFile)
 		if err != nil {
 			return fmt.Errorf("failed to read key file: %w", err)
 		}
+		keys, err := g.InspectPublicKey(data)
+		if err != nil {
+			return validationErrorf("invalid key file %s: %v", keyFile, err)
+		}
+		if warning, err = checkKeyUsable(keys, email, time.Now()); err != nil {
+			return err
+		}
 		if err := os.WriteFile(keyPath, data, 0644); err != nil {
 			return fmt.Errorf("failed to write key: %w", err)
 		}
@@ -147,21 +265,164 @@ func runKeyAdd(cmd *cobra.Command, args []string) error {
 		if !g.KeyExists(email) {
 			return fmt.Errorf("no GPG key found for %s. Use --key-file to specify a key file", email)
 		}
-		if err := g.ExportPublicKeyToFile(email, keyPath); err != nil {
+		fpr, err := chooseKey(g, email, keyID)
+		if err != nil {
+			return err
+		}
+		keys, err := g.LookupKeys(fpr)
+		if err != nil {
+			return err
+		}
+		if warning, err = checkKeyUsable(keys, email, time.Now()); err != nil {
+			return err
+		}
+		// Export by fingerprint, so only the chosen key is stored
+		if err := g.ExportPublicKeyToFile(fpr, keyPath); err != nil {
 			return fmt.Errorf("failed to export key: %w", err)
 		}
 	}
 
 	fmt.Printf("✓ Added key for %s\n", email)
+	recordChange("add key %s", email)
+	if warning != "" {
+		fmt.Printf("⚠ %s\n", warning)
+	}
+	return nil
+}
+
+// keyExpiryWarning is how long before its expiry a key is flagged when added
+const keyExpiryWarning = 30 * 24 * time.Hour
+
+// checkKeyUsable fails unless one of the keys for email can be encrypted
+// to at now. If the usable key expires soon, it returns a warning. Keys
+// without a user ID for email are only considered if none has one.
+func checkKeyUsable(keys []gpg.Key, email string, now time.Time) (string, error) {
+	candidates := keys
+	if matching := keysWithEmail(keys, email); len(matching) > 0 {
+		candidates = matching
+	}
+
+	var problems []string
+	for _, k := range candidates {
+		if problem := keyProblem(k, now); problem != "" {
+			problems = append(problems, problem)
+			continue
+		}
+		if !k.Expires.IsZero() && k.Expires.Sub(now) < keyExpiryWarning {
+			days := int(k.Expires.Sub(now).Hours() / 24)
+			return fmt.Sprintf("The key for %s expires on %s, in %d day(s). Ask them to extend it and add it again", email, k.Expires.Format("2006-01-02"), days), nil
+		}
+		return "", nil
+	}
+	return "", validationErrorf("refusing the key for %s: %s. Vaults cannot be re-encrypted for it", email, strings.Join(problems, "; "))
+}
+
+// keyProblem explains why k cannot be encrypted to at now, or returns ""
+func keyProblem(k gpg.Key, now time.Time) string {
+	switch {
+	case k.Revoked:
+		return fmt.Sprintf("key %s is revoked", k.KeyID)
+	case !k.Expires.IsZero() && !k.Expires.After(now):
+		return fmt.Sprintf("key %s expired on %s", k.KeyID, k.Expires.Format("2006-01-02"))
+	case !k.CanEncrypt:
+		return fmt.Sprintf("key %s has no usable encryption subkey", k.KeyID)
+	}
+	return ""
+}
+
+// keysWithEmail returns the keys with a user ID for email
+func keysWithEmail(keys []gpg.Key, email string) []gpg.Key {
+	var matching []gpg.Key
+	for _, k := range keys {
+		if k.HasEmail(email) {
+			matching = append(matching, k)
+		}
+	}
+	return matching
+}
+
+// runKeyAddAllFromKeyring stores every public key in the GPG keyring that
+// has an email and is not in keys/ yet
+func runKeyAddAllFromKeyring(secretsDir string) error {
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	g := newGPG()
+	keys, err := g.ListPublicKeys()
+	if err != nil {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	keysDir := config.GetKeysDir(secretsDir)
+	added, skipped := 0, 0
+	now := time.Now()
+	for _, key := range keys {
+		if keyFilter != "" && !strings.Contains(strings.ToLower(key.Email), strings.ToLower(keyFilter)) {
+			continue
+		}
+		if key.Email == "" || validateEmail(key.Email) != nil {
+			fmt.Printf("⚠ Skipped key %s: no valid email in its user ID\n", key.KeyID)
+			skipped++
+			continue
+		}
+		if problem := keyProblem(key, now); problem != "" {
+			fmt.Printf("⚠ Skipped key for %s: %s\n", key.Email, problem)
+			skipped++
+			continue
+		}
+
+		keyPath := filepath.Join(keysDir, key.Email+".asc")
+		if _, err := os.Stat(keyPath); err == nil {
+			skipped++
+			continue
+		}
+		// Export by fingerprint, so only this key is stored even if
+		// another key shares the email
+		if err := g.ExportPublicKeyToFile(key.Fingerprint, keyPath); err != nil {
+			return fmt.Errorf("failed to export key for %s: %w", key.Email, err)
+		}
+		fmt.Printf("✓ Added key for %s\n", key.Email)
+		recordChange("add key %s", key.Email)
+		added++
+	}
+
+	fmt.Printf("✓ Added %d key(s), skipped %d\n", added, skipped)
 	return nil
 }
 
+// chooseKey returns the fingerprint of the keyring key for email: the one
+// given with --key-id, which must have a user ID for email, or else the
+// only key with the email. Several keys make the user choose.
+func chooseKey(g *gpg.GPG, email, keyID string) (string, error) {
+	if keyID == "" {
+		fpr, err := g.ResolveUniqueKey(email)
+		var ambiguous *gpg.AmbiguousKeyError
+		if errors.As(err, &ambiguous) {
+			return "", validationErrorf("%w", err)
+		}
+		return fpr, err
+	}
+
+	if !gpg.IsFingerprint(keyID) {
+		return "", validationErrorf("invalid --key-id %q: expected a full 40-character fingerprint", keyID)
+	}
+	keys, err := g.LookupKeys(keyID)
+	if err != nil {
+		return "", notFoundErrorf("no key with fingerprint %s in your keyring", keyID)
+	}
+	if len(gpg.EmailFingerprints(keys, email)) == 0 {
+		return "", validationErrorf("key %s has no user ID for %s", keyID, email)
+	}
+	return strings.ToUpper(keyID), nil
+}
+
 func runKeyRemove(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	email := args[0]
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	keysDir := config.GetKeysDir(secretsDir)
@@ -176,24 +437,119 @@ func runKeyRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✓ Removed key for %s\n", email)
+	recordChange("remove key %s", email)
 	return nil
 }
 
+func runKeyShow(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := args[0]
+
+	if err := validateName(email); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	keyPath := filepath.Join(config.GetKeysDir(secretsDir), email+".asc")
+	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
+		return fmt.Errorf("no key found for %s", email)
+	}
+
+	keys, err := newGPG().ShowKeyFile(keyPath)
+	if err != nil {
+		return err
+	}
+
+	if keyFingerprintOnly {
+		for _, k := range keys {
+			fmt.Println(k.Fingerprint)
+		}
+		return nil
+	}
+
+	for i, k := range keys {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Key: %s\n", email)
+		for _, uid := range k.UserIDs {
+			fmt.Printf("  User ID:     %s\n", uid)
+		}
+		fmt.Printf("  Fingerprint: %s\n", formatFingerprint(k.Fingerprint))
+		fmt.Printf("  Key ID:      %s\n", k.KeyID)
+		if !k.Created.IsZero() {
+			fmt.Printf("  Created:     %s\n", k.Created.Format("2006-01-02"))
+		}
+		switch {
+		case k.Expires.IsZero():
+			fmt.Println("  Expires:     never")
+		case k.Expires.Before(time.Now()):
+			fmt.Printf("  Expires:     %s (expired)\n", k.Expires.Format("2006-01-02"))
+		default:
+			fmt.Printf("  Expires:     %s\n", k.Expires.Format("2006-01-02"))
+		}
+		if !strings.EqualFold(k.Email, email) {
+			fmt.Printf("  ⚠ The key's user ID does not match %s\n", email)
+		}
+	}
+
+	return nil
+}
+
+// formatFingerprint groups a fingerprint into blocks of four characters
+func formatFingerprint(fpr string) string {
+	var groups []string
+	for len(fpr) > 4 {
+		groups = append(groups, fpr[:4])
+		fpr = fpr[4:]
+	}
+	groups = append(groups, fpr)
+	return strings.Join(groups, " ")
+}
+
 func runKeyImport(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	for _, email := range keyImportOnly {
+		if err := validateEmail(email); err != nil {
+			return err
+		}
 	}
 
 	keysDir := config.GetKeysDir(secretsDir)
-	g := gpg.New(GetGPGBinary())
+	g := newGPG()
 
-	imported, err := g.ImportKeyFromDir(keysDir)
+	results, err := g.ImportKeyFromDir(keysDir, keyImportOnly...)
 	if err != nil {
 		return fmt.Errorf("failed to import keys: %w", err)
 	}
 
-	fmt.Printf("✓ Imported %d key(s) to GPG keyring\n", imported)
+	if printImportResults(results) > 0 {
+		return fmt.Errorf("some keys failed to import")
+	}
 	return nil
 }
+
+// printImportResults prints one line per imported key and a summary, and
+// returns the number of keys that failed
+func printImportResults(results []gpg.ImportResult) int {
+	counts := map[gpg.ImportStatus]int{}
+	for _, r := range results {
+		counts[r.Status]++
+		if r.Err != nil {
+			fmt.Printf("  ✗ %s: %s: %v\n", r.Email, r.Status, r.Err)
+		} else {
+			fmt.Printf("  ✓ %s: %s\n", r.Email, r.Status)
+		}
+	}
+	fmt.Printf("Imported %d new, %d updated, %d already present, %d failed\n",
+		counts[gpg.ImportNew], counts[gpg.ImportUpdated], counts[gpg.ImportUnchanged], counts[gpg.ImportFailed])
+	return counts[gpg.ImportFailed]
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
+)
+
+func TestCheckKeyUsable(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	alice := []string{"Alice <alice@example.com>"}
+	tests := []struct {
+		name    string
+		keys    []gpg.Key
+		warning string // substring, "" for none
+		wantErr string // substring, "" for success
+	}{
+		{"valid", []gpg.Key{{KeyID: "A1", UserIDs: alice, CanEncrypt: true}}, "", ""},
+		{"revoked", []gpg.Key{{KeyID: "A1", UserIDs: alice, Revoked: true}}, "", "A1 is revoked"},
+		{"expired", []gpg.Key{{KeyID: "A1", UserIDs: alice, CanEncrypt: true, Expires: now.Add(-time.Hour)}}, "", "expired on 2026-09-30"},
+		{"no encryption subkey", []gpg.Key{{KeyID: "A1", UserIDs: alice}}, "", "no usable encryption subkey"},
+		{"expires soon", []gpg.Key{{KeyID: "A1", UserIDs: alice, CanEncrypt: true, Expires: now.Add(10 * 24 * time.Hour)}}, "in 10 day(s)", ""},
+		{"old key revoked, new one valid", []gpg.Key{
+			{KeyID: "A1", UserIDs: alice, Revoked: true},
+			{KeyID: "A2", UserIDs: alice, CanEncrypt: true},
+		}, "", ""},
+		{"only another email is valid", []gpg.Key{
+			{KeyID: "A1", UserIDs: alice, Revoked: true},
+			{KeyID: "B1", UserIDs: []string{"bob@example.com"}, CanEncrypt: true},
+		}, "", "A1 is revoked"},
+	}
+	for _, tt := range tests {
+		warning, err := checkKeyUsable(tt.keys, "alice@example.com", now)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: error = %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		case tt.wantErr != "" && ExitCode(err) != ExitValidation:
+			t.Errorf("%s: exit code = %d, want %d", tt.name, ExitCode(err), ExitValidation)
+		}
+		if (tt.warning == "") != (warning == "") || !strings.Contains(warning, tt.warning) {
+			t.Errorf("%s: warning = %q, want %q", tt.name, warning, tt.warning)
+		}
+	}
+}
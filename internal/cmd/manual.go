@@ -38,12 +38,31 @@ COMMANDS
     init
         Initialize a new secrets store in the current directory. Creates
         the .secrets/ directory structure and exports your GPG public key.
+        --store-dir-layout shared keeps all vaults in one password store
+        (--shared-store, e.g. ~/.password-store), one subdirectory each.
+        --recipient-format fingerprint writes key fingerprints to .gpg-id
+        instead of emails. Warns if git would ignore any part of .secrets/;
+        --fix-gitignore appends rules to .gitignore re-including it.
+        --adopt <path> copies an existing pass store into a new vault
+        without re-encrypting it; the members are the emails of the keys
+        in its .gpg-id (--adopt-vault names the vault). If several keys
+        have your email, init lists their fingerprints and --key-id
+        <fingerprint> chooses yours.
 
         secrets-cli init --email you@example.com
+        secrets-cli init --adopt ~/.password-store
 
     setup
         Configure access after cloning a repository with secrets. Imports
-        all stored public keys and verifies your access to vaults.
+        all stored public keys and verifies your access to vaults. Warns
+        if your secret key is missing; --import-secret-key <file> imports
+        it first and test-decrypts to confirm it works. --preset checks
+        your passphrase and caches it in gpg-agent via
+        gpg-preset-passphrase (needs allow-preset-passphrase in
+        gpg-agent.conf), so the rest of the session does not prompt.
+        Finally it decrypts one secret of a vault you can access and, if
+        that fails, prints gpg's error with the likely fix and exits
+        nonzero; --verify=false skips this check.
 
         git clone git@github.com:org/project.git
         cd project
@@ -51,6 +70,11 @@ COMMANDS
 
     vault list
         List all vaults. Shows access status (✓/✗) for your email.
+        Use --member <email> to list only vaults that person can access
+        and --count to print just the number. Archived vaults are only
+        listed with --all, marked [archived].
+
+        secrets-cli vault list --member alice@example.com --count
 
     vault create <name>
         Create a new vault. You are automatically added as the first member.
@@ -58,71 +82,323 @@ COMMANDS
         secrets-cli vault create dev
         secrets-cli vault create production --description "Prod secrets"
 
+        Use --gpg-id-extra <email> to add a break-glass recovery key that
+        every secret is encrypted for, independent of membership. Recovery
+        keys are shown as [recovery] in 'vault info'.
+
+        secrets-cli vault create production --gpg-id-extra recovery@example.com
+
+        --from <vault> uses another vault as a template: its secret names
+        are created with the placeholder value CHANGEME, never its values.
+        --members-from <vault> starts with that vault's members. Replace
+        placeholders before exporting: they decrypt fine, so export
+        includes them even with --keep-going.
+
+        secrets-cli vault create feature-x --from dev --members-from dev
+
+        --gpg-ids-file <file> adds the members listed in the file, one per
+        line as an email, "<fingerprint> <email>", or a fingerprint your
+        keyring knows, without checking their keys (for air-gapped setups).
+        .gpg-id pins the fingerprints given. Members without a public key
+        are listed; import them with 'key add' and run 'sync' before adding
+        secrets.
+
+        secrets-cli vault create offline --gpg-ids-file ids.txt
+
     vault info <vault>
         Display vault details including description, member list, and
-        number of secrets.
+        number of secrets. --secrets also lists the secret names.
 
     vault delete <vault>
-        Delete a vault and all its secrets. Requires --force flag.
+        Delete a vault and all its secrets. Requires --force flag. Only
+        the vault's owner may delete it.
 
         secrets-cli vault delete old-vault --force
 
-    vault add-member <vault> <email>
+    vault archive <vault>
+    vault unarchive <vault>
+        Retire a vault without deleting it. An archived vault is hidden
+        from 'vault list' and read-only: get and export still work, but
+        set, delete, rename, copy, import and add-member are refused until
+        it is unarchived.
+
+        secrets-cli vault archive legacy
+
+    vault add-member <vault> <email|@group>
         Grant a team member access to a vault. Their GPG key must first
-        be added with 'key add'. All secrets are re-encrypted.
+        be added with 'key add'. All secrets are re-encrypted. @group
+        adds every member of a group; 'sync' later follows changes to it.
+        It refuses to add a member whose email is also on another key in
+        your keyring, listing the fingerprints, so secrets are not
+        encrypted to the wrong key.
+
+        --role read adds a read-only member.
 
         secrets-cli vault add-member dev alice@example.com
+        secrets-cli vault add-member dev carol@example.com --role read
+        secrets-cli vault add-member dev @backend
 
     vault remove-member <vault> <email>
         Revoke a member's access. All secrets are re-encrypted to exclude
-        the removed member.
+        the removed member. Afterwards, the secrets they could read are
+        listed as rotation candidates. --rotate <glob> regenerates matching
+        secrets ('**' for all) using --policy presets, keeping the fields
+        after the first line.
 
         secrets-cli vault remove-member dev bob@example.com
+        secrets-cli vault remove-member dev bob@example.com --rotate 'db/*'
+
+        add-member, remove-member and sync accept --dry-run to validate
+        and print the re-encryption plan without changing anything.
+        Vaults with more than 50 secrets are re-encrypted four secrets at
+        a time; add-member and sync accept --parallel to do so for any
+        vault.
+
+    vault set-role <vault> <email> <read|read-write>
+        Change a member's role. Read-only members can get, list and
+        export secrets; set, delete, rename, copy into the vault, import,
+        sync and member management require read-write. A vault always
+        keeps one read-write member. Roles are enforced by this CLI only:
+        every member is a GPG recipient and can decrypt secrets directly.
+
+        secrets-cli vault set-role dev carol@example.com read
+
+    vault transfer-ownership <vault> <email>
+        Make another read-write member the vault's owner. The creator owns
+        a vault until then. Only the owner can delete the vault or remove
+        the owner, who must transfer ownership before leaving. Vaults
+        created before owners existed treat every member as an owner.
+
+        secrets-cli vault transfer-ownership production bob@example.com
+
+    vault subvault <vault> <path> --members <emails>
+        Encrypt the secrets below <path> only for the given vault
+        members, using a .gpg-id in that directory of the store. Only
+        that subtree is re-encrypted. Subvaults are kept in vault.yaml;
+        sync and verify use each secret's nearest subvault. --remove
+        encrypts the subtree for every member again.
+
+        secrets-cli vault subvault prod admin --members alice@example.com
+        secrets-cli vault subvault prod admin --remove
+
+    vault members diff <vault-a> <vault-b>
+        Compare two vaults' members: only in A, only in B, and in both.
+        Use --json for tooling.
+
+        secrets-cli vault members diff staging production
+
+    vault export-archive <vault> --out <file>
+        Back up a vault's config and encrypted store into a tar archive.
+        Use --encrypt-to <email> to add an outer GPG layer.
+
+        secrets-cli vault export-archive prod --out prod.tar.gpg --encrypt-to backup@example.com
+
+    vault import-archive <vault> <file>
+        Restore an archive into a new vault, then re-encrypt it for the
+        vault members. Encrypted archives are decrypted automatically.
+
+        secrets-cli vault import-archive prod-restored prod.tar.gpg
+
+    vault export <vault> --encrypt-to <email> --out <file> --force
+        Hand a vault off to someone outside it: every secret is
+        re-encrypted in memory solely for the given recipients and bundled
+        as a pass store they can read independently. Requires --force.
+
+        secrets-cli vault export dev --encrypt-to consultant@example.com --out dev.tar --force
+
+    group list | add <group> <email>... | remove <group> [email...]
+        Manage member groups in .secrets/groups.yaml. After changing a
+        group, run 'sync' on the vaults it was added to so access follows.
+
+        secrets-cli group add backend alice@example.com bob@example.com
+        secrets-cli group remove backend bob@example.com
 
     key list
         List all GPG public keys stored in the repository.
 
     key add <email>
         Add a team member's public key. If the key exists in your GPG
-        keyring, it is exported automatically. Otherwise use --key-file.
+        keyring, it is exported automatically. Otherwise use --key-file,
+        or --from-stdin to paste an armored key; it is checked in a
+        temporary keyring for a user ID matching <email> before saving.
+        If several keys in your keyring have the email, their fingerprints
+        are listed; choose one with --key-id <fingerprint>. Revoked or
+        expired keys, and keys without a usable encryption subkey, are
+        refused; keys expiring within 30 days are added with a warning.
 
         secrets-cli key add alice@example.com
         secrets-cli key add bob@example.com --key-file bob.asc
+        secrets-cli key add carol@example.com --from-stdin < carol.asc
+
+        --all-from-keyring adds every key in your GPG keyring that has an
+        email and is not stored yet; keys without an email or expired are
+        skipped with a warning. --filter <substr> limits it by email.
+
+        secrets-cli key add --all-from-keyring --filter @example.com
 
     key remove <email>
         Remove a public key from the store. Note: this does not revoke
         vault access. Use 'vault remove-member' first.
 
+    key show <email>
+        Show a stored key's user IDs, fingerprint, key ID, creation date
+        and expiry. The key file is read without importing it. Use
+        --fingerprint-only for scripting.
+
+        secrets-cli key show alice@example.com --fingerprint-only
+
     key import
-        Import all stored public keys into your local GPG keyring.
+        Import all stored public keys into your local GPG keyring. Each
+        key is reported as imported, updated, already present or failed.
+        --only <email> (repeatable) imports just those keys.
+
+        secrets-cli key import --only bob@example.com
 
     list <vault>
-        List all secrets in a vault.
+        List all secrets in a vault, sorted by name (--sort none keeps
+        filesystem order). export also emits secrets in sorted order.
+
+        --long shows each secret's recipient count from its packet headers,
+        marked ✓ if it matches the vault's members and recovery keys.
+        Nothing is decrypted, but gpg runs once per secret.
+
+        --show-values decrypts each secret and shows a masked preview of
+        its first line (ab****yz; 4 characters or less fully masked).
+        --unmask --force prints the full values instead.
+
+        --print0 follows each name with a NUL byte for xargs -0, and
+        --delimiter with any string; both work with --sort.
+
+        --count prints only the number of secrets. --empty lists only
+        secrets whose value is empty or a placeholder such as CHANGEME;
+        it decrypts every secret. Combine them to count unfilled secrets.
 
         secrets-cli list dev
         secrets-cli list production --format names
+        secrets-cli list dev --long
+        secrets-cli list dev --tree
+        secrets-cli list dev --show-values
+        secrets-cli list dev --print0 | xargs -0 -n1 secrets-cli get dev
+        secrets-cli list dev --empty --count
+
+    tree
+        Show every vault you can access with its secrets as a tree, with
+        member counts and [read-only] or [archived] markers. --depth N
+        stops N levels below each vault and shows how many secrets each
+        cut-off directory holds. Nothing is decrypted.
+
+        secrets-cli tree
+        secrets-cli tree --depth 1
 
     get <vault> <secret>
         Retrieve and display a secret value.
+        If decryption fails, the keys the secret is encrypted for are
+        listed, with whether yours is among them. --metadata shows the
+        recipient key IDs mapped to members, any expected recipient that
+        is missing, the file size and its git status and last commit,
+        without decrypting or needing a private key.
 
         secrets-cli get dev database/password
         secrets-cli get production api/stripe-key
+        secrets-cli get dev database/password --metadata
+
+        --quiet-missing prints nothing and exits 0 if the secret does not
+        exist; --default <value> prints that value instead, and cannot be
+        combined with --copy, --field, --raw or --base64-decode. Access and
+        decryption failures still exit nonzero.
+
+        secrets-cli get dev feature/flag --default off
+
+        For multi-field secrets (first line password, then "key: value"
+        lines), --field-list prints the field names without any values
+        (the first line is listed as "password") and --field <key>
+        prints one field.
+
+        secrets-cli get dev database/conn --field-list
+        secrets-cli get dev database/conn --field username
+
+        --cache-ttl <duration> reuses the decrypted value for that long,
+        stored 0600 on tmpfs (refused on disk unless --allow-disk-cache).
+
+        secrets-cli get dev database/password --cache-ttl 30s
+
+        --copy (-c) puts the value on the clipboard (pbcopy, clip.exe,
+        wl-copy, xclip or xsel) instead of printing it. --clip-timeout
+        <seconds> clears the clipboard afterwards.
+
+        secrets-cli get dev database/password --copy --clip-timeout 45
+
+        Values are printed trimmed of trailing whitespace. --raw
+        prints the exact decrypted bytes instead, with no newline added,
+        for binary or whitespace-significant values; --base64-decode
+        prints the bytes of a secret stored as base64.
+
+        secrets-cli get dev tls/keystore --base64-decode > keystore.p12
+
+        --all [vault] prints every secret as one JSON object keyed by
+        the secret path as stored ("database/password"), for programs
+        that load all their configuration at startup.
+
+        secrets-cli get --all dev
+
+        --stdin [vault] reads secret names from stdin, one per line, and
+        prints "name<TAB>value" lines in one process. Missing or
+        undecryptable secrets are reported on stderr; the exit status is
+        nonzero only if none could be read, or with --strict if any
+        could not.
+
+        printf 'db/user\ndb/password\n' | secrets-cli get dev --stdin
+
+        With --use-keychain, your GPG passphrase is read from the system
+        keychain (macOS Keychain or libsecret via secret-tool) and fed to
+        gpg through loopback pinentry. On first use you are prompted and
+        the passphrase is stored once it is verified to unlock your
+        secret key in a fresh gpg-agent, which has nothing cached.
+
+        secrets-cli get dev database/password --use-keychain
 
     set <vault> <secret> [value]
-        Store a secret. If value is omitted, reads from stdin.
+        Store a secret. If value is omitted, reads from stdin. On a
+        terminal the value is typed twice without echo and must match;
+        --no-confirm asks only once.
 
         secrets-cli set dev database/password "my-secret"
         echo "secret123" | secrets-cli set dev api/key
 
+        An inline value that is the path of an existing file, or a
+        placeholder such as changeme or TODO, needs confirmation; without
+        a terminal, set refuses. --force stores it anyway.
+
+        --generate stores a random value from a policy: strong (24 chars
+        with symbols, default), pin (6 digits) or token (40 hex). The value
+        is only printed with --show.
+
+        secrets-cli set dev db/password --generate --policy strong
+
+        --field key=value updates one "key: value" line of a multi-field
+        secret, keeping the password line and the other fields. A new
+        secret gets an empty password line.
+
+        secrets-cli set dev database/conn --field username=admin
+
     delete <vault> <secret>
         Delete a secret. Requires --force flag.
 
+        --recursive takes a glob instead (* does not cross /) and deletes
+        every secret matching it or under a matching directory. Matches
+        are listed and deleted after one confirmation, or at once with
+        --force. A glob matching the whole vault also needs --all.
+
         secrets-cli delete dev old/secret --force
+        secrets-cli delete dev 'temp/*' --recursive
 
     rename <vault> <old> <new>
-        Rename or move a secret within a vault.
+        Rename or move a secret within a vault. A trailing slash (or
+        --recursive) moves a whole subtree, keeping relative names; it
+        refuses to move a path into itself or over existing secrets.
 
         secrets-cli rename dev old/path new/path
+        secrets-cli rename dev db/ database/
 
     copy <src-vault> <secret> <dst-vault>
         Copy a secret to another vault. Use --new-name to rename.
@@ -130,13 +406,92 @@ COMMANDS
         secrets-cli copy dev database/password staging
         secrets-cli copy dev api/key production --new-name api/dev-backup
 
+        A trailing slash or --recursive copies a whole subtree; use
+        --dst-prefix to place it under another path.
+
+        secrets-cli copy dev db/ staging
+        secrets-cli copy dev db/ staging --dst-prefix legacy/db
+
+    import <vault> <file>
+        Import VAR=value lines from a dotenv file. Names are mapped to
+        paths (DB_PASSWORD -> db/password). Use --prefix to namespace the
+        imported secrets; existing secrets are kept unless --force.
+
+        secrets-cli import shared legacy.env --prefix legacy
+
     export <vault>
-        Export all secrets from a vault in various formats.
+        Export all secrets from a vault in various formats. If any
+        secret cannot be decrypted, nothing is exported and the command
+        fails; --keep-going skips it instead, listing it on stderr. A
+        summary of exported and skipped counts goes to stderr.
+
+        The docker format never quotes values, since docker reads env
+        files literally, and fails on values spanning several lines
+        unless --base64-multiline. --docker-run prints a 'docker run'
+        command with -e flags instead.
+
+        --template takes a Go text/template, as a file or inline, instead
+        of a format. It gets .Vault, .Secrets (each with .Name, .EnvName
+        and .Value) and .Values by name, and the functions env, quote and
+        b64.
 
         secrets-cli export dev                    # Shell format
         secrets-cli export dev --format dotenv    # .env format
         secrets-cli export dev --format json      # JSON format
+        secrets-cli export dev --format json --compact
+        secrets-cli export dev --format raw       # path<TAB>value lines
+        secrets-cli export dev --format csv       # name,value rows
+        secrets-cli export dev --format k8s --name app --namespace web
+        secrets-cli export dev --format docker    # docker --env-file
+        secrets-cli export dev --format docker --docker-run
+        secrets-cli export dev --template nginx.conf.tmpl --out nginx.conf
         secrets-cli export dev --prefix APP_      # Add prefix
+        secrets-cli export dev --vault-prefix     # DEV_ prefix per vault
+        secrets-cli export dev --only 'db/*'      # Only matching secrets
+        secrets-cli export dev --exclude 'admin/*'
+        secrets-cli export dev --keep-going       # Skip undecryptable
+        secrets-cli export dev --format dotenv --out .env
+        secrets-cli export dev --format dotenv --mask --out .env.example
+
+        --out writes a 0600 file, replaced atomically, instead of stdout,
+        and warns if git does not ignore it. Prefer it over redirecting,
+        which creates a world-readable file.
+
+        --mask replaces every value with CHANGEME (or the text given with
+        --mask-with) without decrypting anything, for committable example
+        files such as .env.example.
+
+    env <vault>
+        Print a sourceable script of 'export VAR=value' lines. Values are
+        always single-quoted unless plainly safe, so quotes, backticks,
+        $(...) and newlines are set literally. --unset prints the matching
+        'unset VAR' lines for teardown without decrypting anything.
+
+        eval "$(secrets-cli env dev)"
+        eval "$(secrets-cli env dev --unset)"
+
+    changed <vault> [--since 7d]
+        List secrets whose encrypted files changed in git since a duration
+        (7d, 2w, 24h) or date (2024-01-01), with the date and commit of
+        their last change. Nothing is decrypted.
+
+        secrets-cli changed dev --since 7d
+
+    status [--exit-code]
+        Show uncommitted changes to the secrets store according to git,
+        grouped by vault: secrets added, modified or deleted, recipients
+        and vault.yaml, then keys and settings. --exit-code exits 1 when
+        there are changes, for pre-commit hooks.
+
+        secrets-cli status
+
+    render --template <file> [--vault <vault>] [--out <file>]
+        Render a template, replacing ${vault/name} and
+        {{secret "vault" "name"}} references with secret values. All
+        unresolved references are reported together and nothing is written.
+
+        secrets-cli render --template config.tmpl --vault dev
+        secrets-cli render -t app.yaml.tmpl -o app.yaml
 
     sync <vault>
         Re-encrypt all secrets for current vault members. Use after
@@ -144,6 +499,113 @@ COMMANDS
 
         secrets-cli sync production
 
+    reencrypt-all
+        Run sync for every vault you are a member of, e.g. after adding
+        a backup recipient or rotating a key. --all-vaults attempts every
+        vault and is limited to the store owner. Failures are reported per
+        vault and do not stop the rest; the exit status is nonzero if any
+        vault failed.
+
+        secrets-cli reencrypt-all --dry-run
+
+    audit
+        Report members vs vaults with secret counts, for access reviews.
+        Backup recipients are listed too. Flags key files that no vault
+        or backup recipient uses ("unused key") and members without
+        a key file ("missing key"). Use --by-member for a per-member list
+        or --format json for spreadsheets. Members missing from a vault's
+        .gpg-id and stale .gpg-id recipients are reported too. --fix shows
+        how to repair them (remove members without keys, re-sync) and
+        --fix --force applies it. A vault's owner is never removed.
+
+        secrets-cli audit --format json > access-review.json
+        secrets-cli audit --fix --force
+
+    verify [vault...]
+        Check the store for CI: .gpg-id matches the members, recovery
+        keys and backup recipients; every member has a key file; every
+        secret is encrypted for exactly those recipients; and no
+        unencrypted file sits in a password store. Every issue is
+        reported and the exit status is nonzero if there is any. No
+        private key is needed. --format json for tooling.
+
+        secrets-cli verify
+        secrets-cli verify --format json
+
+    reindex [vault...]
+        Check the packet structure of every secret file without
+        decrypting: files gpg cannot parse, without recipients or
+        encrypted data, or whose packets do not add up to the file size
+        (say, cut short by an interrupted merge) are reported, and the
+        exit status is nonzero. --quarantine moves them into .corrupt/
+        in the password store instead; nothing is ever deleted.
+
+        secrets-cli reindex
+        secrets-cli reindex dev --quarantine
+
+    config show
+        Print the store settings from config.yaml as YAML, or as JSON
+        with --format json.
+
+        secrets-cli config show --format json
+
+    config set <key> <value>
+        Change a store setting: owner, default_vault, access_control,
+        trust_model, auto_commit or auto_push.
+        Unknown keys are rejected; settings with their own command
+        (store_layout, recipient_format, backup_recipients) name it.
+        An empty value resets the setting to its default.
+
+        secrets-cli config set access_control gpg-only
+
+    config set-default-vault <vault>
+        Set the vault used when get, set, list, delete or export are run
+        without a vault argument. An explicit vault always wins. Pass ""
+        to clear it. set then reads the value from stdin or the prompt,
+        since two arguments always mean "set <vault> <secret>".
+
+        secrets-cli config set-default-vault dev
+        secrets-cli get database/password
+
+    config add-backup-recipient <email>
+    config remove-backup-recipient <email>
+        Encrypt every secret in every vault for an offline break-glass key
+        that is not a member anywhere. The key must be stored with 'key
+        add' first. Run 'reencrypt-all' afterwards to re-encrypt
+        existing secrets. Whoever holds this key can read everything,
+        so keep it offline.
+
+        secrets-cli config add-backup-recipient breakglass@example.com
+
+    migrate-layout <per-vault|shared>
+        Move every vault's password store to another layout. With shared,
+        each vault is a subdirectory of one store with its own .gpg-id.
+        vault.yaml stays in vaults/<vault>/ and still decides access.
+        Without --force the moves are only printed.
+
+        secrets-cli migrate-layout shared --shared-store ~/.password-store --force
+
+    migrate-recipients <email|fingerprint>
+        Rewrite every vault's .gpg-id to list recipients by email or by
+        full key fingerprint. Fingerprints rule out encrypting for another
+        key with the same email. Nothing is re-encrypted. Vaults
+        whose .gpg-id does not match their members are reported and left
+        for 'sync'. Without --force the changes are only printed.
+
+        secrets-cli migrate-recipients fingerprint --force
+
+    cache purge
+        Remove all values cached by 'get --cache-ttl'.
+
+    profile list | add <name> | use <name>
+        Manage profiles in ~/.config/secrets-cli/profiles.yaml, each an
+        email with an optional GnuPG home and gpg binary, for working
+        under several identities. 'profile use' picks the default one;
+        --profile overrides it. Profiles never contain keys.
+
+        secrets-cli profile add work --email you@company.com --gnupg-home ~/.gnupg-work
+        secrets-cli profile use work
+
     version
         Display version, commit hash, and build date.
 
@@ -152,6 +614,12 @@ GLOBAL OPTIONS
         Path to secrets directory. Default: .secrets
         Environment: SECRETS_DIR
 
+    --profile <name>
+        Take the email, GnuPG home and gpg binary from this profile (see
+        'profile'). Default: the profile chosen with 'profile use'.
+        Explicitly given flags still win; the profile wins over
+        USER_EMAIL and GPG_BINARY.
+
     --email <email>
         Your email address for GPG operations.
         Environment: USER_EMAIL
@@ -161,13 +629,108 @@ GLOBAL OPTIONS
         Path to GPG binary. Default: gpg
         Environment: GPG_BINARY
 
+    --gnupg-home <dir>
+        GnuPG home directory used by every gpg and pass subprocess, e.g.
+        a throwaway keyring per CI job. Default: inherited GNUPGHOME
+        Environment: GNUPGHOME
+
+    --gpg-retries <n>
+        Retry gpg and pass up to n times, with backoff, after transient
+        gpg-agent errors (e.g. "agent refused", "Inappropriate ioctl for
+        device"). Other errors fail immediately. Default: 2
+
+    --batch
+        Run non-interactively: gpg gets --batch --pinentry-mode loopback
+        --no-tty, directly and through pass, so it never prompts. A
+        passphrase for your key is read from GPG_PASSPHRASE and handed to
+        gpg on a file descriptor, never on the command line.
+
+        GPG_PASSPHRASE=... secrets-cli --batch export prod --format env
+
+    --loose
+        After re-encrypting a vault, secrets-cli checks that a secret's
+        recipient key IDs are exactly the expected keys. --loose only
+        compares the number of recipients, for gpg versions whose packet
+        listing cannot be matched against the keyring.
+
+    --trust-model <model>
+        The gpg trust model used to encrypt, directly and through pass
+        (appended to PASSWORD_STORE_GPG_OPTS): always, direct, pgp,
+        classic, tofu, tofu+pgp or auto. Defaults to trust_model in
+        config.yaml, else always: team keys are vetted by being
+        committed to keys/, so no web-of-trust signatures are needed.
+        --no-force-trust passes no trust model at all, leaving it to
+        gpg.conf and your own PASSWORD_STORE_GPG_OPTS.
+
+    --follow-symlinks
+        Symlinked directories in a password store are skipped when
+        listing secrets, and symlinks resolving outside the store are
+        always ignored, so a cloned repository cannot point the CLI at
+        other files on your machine. --follow-symlinks lists the secrets
+        of symlinked directories that stay inside the store.
+
+    --gpg-opts <options>
+        Extra gpg options whenever pass encrypts or decrypts, such as
+        '--compress-algo none' or '--cipher-algo AES256'. A vault can set
+        its own in vault.yaml as gpg_opts, limited to --cipher-algo,
+        --compress-algo, --digest-algo and the --s2k-* options, since
+        vault.yaml is committed and must not be able to add recipients
+        or weaken trust; a vault.yaml with any other option is refused.
+        PASSWORD_STORE_GPG_OPTS is composed in this order: your
+        environment, the vault's gpg_opts, the trust model, then
+        --gpg-opts. gpg honors the last of repeated options, so only
+        your own --gpg-opts can override the trust model. Parallel
+        re-encryption passes the same options to gpg; reading a secret
+        with gpg directly, when pass is not installed, does not need
+        them.
+
+        secrets-cli --gpg-opts '--compress-algo none' set dev tls/keystore < ks.b64
+
+    --commit, --push
+        After a command changed the store, git add the secrets directory
+        and commit it alone, with a message such as "secrets: set
+        dev/db/password"; --push also pushes. Other staged files are left
+        out of the commit. Nothing happens if nothing changed, and it is
+        an error outside a git repository. Defaults to auto_commit and
+        auto_push in config.yaml; --commit=false overrides them.
+
+        secrets-cli set dev db/password --commit
+
+    --cache
+        Decrypt each secret at most once per run. Values are kept in
+        memory only, never written to disk, and dropped when a write in
+        the same run changes the secret.
+
+    --timeout <duration>
+        Kill any gpg or pass process, with its children, that runs longer
+        than this, e.g. 60s. Recommended in CI, where a gpg-agent waiting
+        on a pinentry would otherwise hang the job. Default: 0 (no limit,
+        so interactive passphrase prompts are never cut short)
+
+    --migrate
+        vault.yaml files from older releases are upgraded in memory when
+        loaded. With --migrate the upgraded config is also written back.
+
     -v, --verbose
-        Enable verbose output.
+        Enable verbose output, including every gpg and pass command line
+        and its environment overrides on stderr. Values passed on stdin
+        are never printed.
         Environment: VERBOSE
 
+EXIT STATUS
+    0   Success
+    1   Other error
+    2   Not found: secrets directory, vault, secret, key or group
+    3   Access denied: not a vault member, or the vault is archived
+    4   gpg or pass failed or timed out
+    5   Invalid arguments, flags or names
+    130 Interrupted while a vault was locked; the lock is released
+
 DIRECTORY STRUCTURE
     .secrets/
+    ├── .gitignore            # Keeps vault lock files out of git
     ├── config.yaml           # Store configuration
+    ├── groups.yaml           # Member groups (optional)
     ├── keys/                 # GPG public keys
     │   ├── alice@example.com.asc
     │   └── bob@example.com.asc
@@ -197,7 +760,7 @@ EXAMPLES
         $ secrets-cli get dev database/password
 
     Export to .env file:
-        $ secrets-cli export dev --format dotenv > .env
+        $ secrets-cli export dev --format dotenv --out .env
 
     Copy secrets between environments:
         $ secrets-cli copy dev database/password staging
@@ -215,6 +778,14 @@ SECURITY NOTES
 
     • Private GPG keys are never stored in the repository.
 
+    • Setting "access_control: gpg-only" in .secrets/config.yaml makes read
+      commands skip the membership check and rely on GPG decryption alone.
+      Use it when member lists are advisory rather than authoritative.
+
+    • --use-keychain trades security for convenience: anyone who can
+      unlock your desktop session can read the cached passphrase. It is
+      strictly opt-in and never enabled by default.
+
 SEE ALSO
     gpg(1), pass(1)
 
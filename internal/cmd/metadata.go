@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+)
+
+// printSecretMetadata shows what can be learned about a secret without
+// decrypting it: its recipients, mapped to members through the stored keys,
+// the file's size and its git status
+func printSecretMetadata(secretsDir, vaultName, secretName, email string) error {
+	vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+	if err != nil {
+		return fmt.Errorf("failed to load vault config: %w", err)
+	}
+
+	path := filepath.Join(config.GetStoreDir(secretsDir, vaultName), filepath.FromSlash(secretName)+".gpg")
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	g := newGPG()
+	ids, err := g.RecipientKeyIDs(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the recipients of %s/%s: %w", vaultName, secretName, err)
+	}
+	keys := storedKeys(g, secretsDir)
+
+	fmt.Printf("Secret: %s/%s\n", vaultName, secretName)
+	if sub, ok := vaultCfg.SubvaultFor(secretName); ok {
+		fmt.Printf("Subvault: %s/\n", sub)
+	}
+	fmt.Printf("File: %s (%d bytes, modified %s)\n", path, info.Size(), info.ModTime().Local().Format("2006-01-02 15:04"))
+	fmt.Printf("Git: %s\n", gitFileStatus(path))
+
+	// Name each recipient after the stored key it belongs to
+	found := map[string]bool{}
+	fmt.Printf("Recipients (%d):\n", len(ids))
+	for _, id := range ids {
+		name := "unknown key"
+		if strings.Trim(id, "0") == "" {
+			name = "hidden recipient"
+		}
+		for _, k := range keys {
+			if k.HasKeyID(id) {
+				name = k.Email
+				found[strings.ToLower(k.Email)] = true
+				break
+			}
+		}
+		if email != "" && strings.EqualFold(name, email) {
+			name += " (you)"
+		}
+		fmt.Printf("  %s  %s\n", id, name)
+	}
+
+	var missing []string
+	for _, r := range secretRecipients(secretsDir, vaultCfg, secretName) {
+		if !found[strings.ToLower(r)] {
+			missing = append(missing, r)
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Printf("⚠ Not encrypted for: %s. Run 'secrets-cli sync %s' if they should read it\n", strings.Join(missing, ", "), vaultName)
+	}
+	return nil
+}
+
+// gitFileStatus describes whether git tracks path, whether it has
+// uncommitted changes and which commit last touched it
+func gitFileStatus(path string) string {
+	dir, base := filepath.Dir(path), filepath.Base(path)
+	git := func(args ...string) (string, error) {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	if _, err := git("rev-parse", "--is-inside-work-tree"); err != nil {
+		return "not in a git repository"
+	}
+	if _, err := git("ls-files", "--error-unmatch", "--", base); err != nil {
+		if ignored, ok := gitIgnored(path); ok && ignored {
+			return "ignored"
+		}
+		return "untracked"
+	}
+
+	status := "tracked"
+	if changes, err := git("status", "--porcelain", "--", base); err == nil && changes != "" {
+		status += ", uncommitted changes"
+	}
+	if last, err := git("log", "-1", "--format=%h %cs %an: %s", "--", base); err == nil && last != "" {
+		status += ", last commit " + last
+	}
+	return status
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitFileStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	if got := gitFileStatus(filepath.Join(dir, "a.gpg")); got != "not in a git repository" {
+		t.Errorf("outside a repository: got %q", got)
+	}
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Alice", "-c", "user.email=alice@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	path := filepath.Join(dir, "a.gpg")
+	if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitFileStatus(path); got != "untracked" {
+		t.Errorf("new file: got %q, want untracked", got)
+	}
+
+	git("add", "a.gpg")
+	git("commit", "-q", "-m", "Add a")
+	if got := gitFileStatus(path); !strings.HasPrefix(got, "tracked, last commit ") || !strings.HasSuffix(got, " Alice: Add a") {
+		t.Errorf("committed file: got %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("y"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitFileStatus(path); !strings.HasPrefix(got, "tracked, uncommitted changes, last commit ") {
+		t.Errorf("modified file: got %q", got)
+	}
+}
@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
+	"github.com/NuevaNext/secrets-cli/internal/pass"
+	"github.com/spf13/cobra"
+)
+
+var migrateLayoutCmd = &cobra.Command{
+	Use:   "migrate-layout <per-vault|shared>",
+	Short: "Move vault password stores to another store layout",
+	Long: `Move every vault's password store to another store layout.
+
+Layouts:
+  per-vault  each vault keeps its store in vaults/<vault>/.password-store (default)
+  shared     each vault is a subdirectory of one password store, with its
+             own .gpg-id, e.g. an existing ~/.password-store
+
+Only the encrypted files move; vault.yaml stays in vaults/<vault>/ and
+access checks still come from the vault config, not from the store. Anyone
+who can read the shared store directly can see which secrets exist.
+
+Without --force the planned moves are only printed.
+
+Examples:
+  secrets-cli migrate-layout shared --shared-store ~/.password-store
+  secrets-cli migrate-layout shared --shared-store ~/.password-store --force
+  secrets-cli migrate-layout per-vault --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMigrateLayout,
+}
+
+var migrateRecipientsCmd = &cobra.Command{
+	Use:   "migrate-recipients <email|fingerprint>",
+	Short: "Rewrite .gpg-id files to list recipients by email or fingerprint",
+	Long: `Switch how recipients are written to every vault's .gpg-id.
+
+Formats:
+  email        recipients are listed by email address (default)
+  fingerprint  recipients are listed by full key fingerprint, so secrets
+               are never encrypted for another key with the same email
+
+Each vault's recipients (members, recovery keys and backup recipients) are
+resolved to fingerprints in your keyring; missing keys are imported from
+keys/ first. The same keys are named either way, so nothing is
+re-encrypted. A vault whose .gpg-id does not match its members, or whose
+recipients cannot be resolved, is left alone and reported; fix it and run
+'secrets-cli sync' on it after migrating.
+
+Without --force the planned changes are only printed.
+
+Examples:
+  secrets-cli migrate-recipients fingerprint
+  secrets-cli migrate-recipients fingerprint --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMigrateRecipients,
+}
+
+var (
+	migrateSharedStore string
+	migrateForce       bool
+)
+
+func init() {
+	rootCmd.AddCommand(migrateLayoutCmd)
+	rootCmd.AddCommand(migrateRecipientsCmd)
+
+	migrateLayoutCmd.Flags().StringVar(&migrateSharedStore, "shared-store", "", "Password store for the shared layout (default: <secrets-dir>/password-store)")
+	migrateLayoutCmd.Flags().BoolVarP(&migrateForce, "force", "f", false, "Move the stores instead of printing the plan")
+	migrateRecipientsCmd.Flags().BoolVarP(&migrateForce, "force", "f", false, "Rewrite the .gpg-id files instead of printing the plan")
+}
+
+// usesSharedStore reports whether the store is configured for the shared layout
+func usesSharedStore(secretsDir string) bool {
+	cfg, err := config.LoadConfig(secretsDir)
+	return err == nil && cfg.StoreLayout == config.StoreLayoutShared
+}
+
+func runMigrateLayout(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	layout := args[0]
+
+	if layout == "" {
+		return fmt.Errorf("store layout cannot be empty")
+	}
+	if err := config.ValidateStoreLayout(layout); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	cfg, err := config.LoadConfig(secretsDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	sharedStore := migrateSharedStore
+	if layout == config.StoreLayoutPerVault {
+		sharedStore = ""
+	} else if sharedStore == "" {
+		sharedStore = cfg.SharedStore
+	}
+
+	vaults, err := config.ListVaults(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	type move struct{ vault, from, to string }
+	var moves []move
+	for _, vaultName := range vaults {
+		from := config.StoreDirForLayout(secretsDir, vaultName, cfg.StoreLayout, cfg.SharedStore)
+		to := config.StoreDirForLayout(secretsDir, vaultName, layout, sharedStore)
+		if from == to {
+			continue
+		}
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			continue
+		}
+		if _, err := os.Stat(to); !os.IsNotExist(err) {
+			return fmt.Errorf("cannot move %s's store: %s already exists", vaultName, to)
+		}
+		moves = append(moves, move{vaultName, from, to})
+	}
+
+	if len(moves) == 0 && cfg.StoreLayout == layout && cfg.SharedStore == sharedStore {
+		fmt.Printf("✓ Store already uses the %s layout\n", layout)
+		return nil
+	}
+
+	fmt.Printf("Migrating to the %s layout:\n", layout)
+	for _, m := range moves {
+		fmt.Printf("  - %s: %s -> %s\n", m.vault, m.from, m.to)
+	}
+	if len(moves) == 0 {
+		fmt.Println("  (no stores to move)")
+	}
+
+	if !migrateForce {
+		fmt.Println()
+		fmt.Println("Use --force to apply the migration")
+		return nil
+	}
+
+	// Move every store, putting back the ones already moved if one fails
+	var done []move
+	for _, m := range moves {
+		err := config.WithVaultLock(config.GetVaultDir(secretsDir, m.vault), func() error {
+			return moveDir(m.from, m.to)
+		})
+		if err != nil {
+			for _, d := range done {
+				if rerr := moveDir(d.to, d.from); rerr != nil {
+					fmt.Printf("✗ Failed to restore %s's store to %s: %v\n", d.vault, d.from, rerr)
+				}
+			}
+			return fmt.Errorf("failed to move %s's store: %w", m.vault, err)
+		}
+		done = append(done, m)
+	}
+
+	cfg.StoreLayout = layout
+	if layout == config.StoreLayoutPerVault {
+		cfg.StoreLayout = ""
+	}
+	cfg.SharedStore = sharedStore
+	if err := config.SaveConfig(secretsDir, cfg); err != nil {
+		return fmt.Errorf("stores moved but failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Migrated %d vault store(s) to the %s layout\n", len(moves), layout)
+	recordChange("migrate to the %s layout", layout)
+	return nil
+}
+
+func runMigrateRecipients(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	format := args[0]
+
+	if format == "" {
+		return fmt.Errorf("recipient format cannot be empty")
+	}
+	if err := config.ValidateRecipientFormat(format); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	cfg, err := config.LoadConfig(secretsDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	vaults, err := config.ListVaults(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	type rewrite struct {
+		vault string
+		p     *pass.Pass
+		ids   []string
+	}
+	var rewrites []rewrite
+	var skipped []string
+	g := newGPG()
+	for _, vaultName := range vaults {
+		vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+		if err != nil {
+			return fmt.Errorf("failed to load vault config for %s: %w", vaultName, err)
+		}
+		p := newPass(config.GetStoreDir(secretsDir, vaultName))
+		p.Fingerprints = format == config.RecipientFormatFingerprint
+		current, err := p.GetGPGIDs()
+		if err != nil {
+			continue // store not initialized yet
+		}
+
+		recipients := vaultRecipients(secretsDir, vaultCfg)
+		importRecipientKeys(g, secretsDir, recipients)
+		ids, err := p.ResolveGPGIDs(recipients)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", vaultName, err))
+			continue
+		}
+		if sameIDs(current, ids) {
+			continue
+		}
+		if !sameKeys(g, current, ids) {
+			skipped = append(skipped, fmt.Sprintf("%s: .gpg-id does not match the vault's members; run 'secrets-cli sync %s' after migrating", vaultName, vaultName))
+			continue
+		}
+		rewrites = append(rewrites, rewrite{vaultName, p, ids})
+	}
+
+	if len(rewrites) == 0 && len(skipped) == 0 && cfg.RecipientFormat == format {
+		fmt.Printf("✓ Store already uses %s recipients\n", format)
+		return nil
+	}
+
+	fmt.Printf("Migrating .gpg-id recipients to %s:\n", format)
+	for _, r := range rewrites {
+		fmt.Printf("  - %s: %s\n", r.vault, strings.Join(r.ids, ", "))
+	}
+	for _, reason := range skipped {
+		fmt.Printf("  ✗ %s\n", strings.Join(strings.Fields(reason), " "))
+	}
+	if len(rewrites) == 0 {
+		fmt.Println("  (no .gpg-id files to rewrite)")
+	}
+
+	if !migrateForce {
+		fmt.Println()
+		fmt.Println("Use --force to apply the migration")
+		return nil
+	}
+
+	for _, r := range rewrites {
+		err := config.WithVaultLock(config.GetVaultDir(secretsDir, r.vault), func() error {
+			return r.p.WriteGPGIDs(r.ids)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rewrite %s's .gpg-id: %w", r.vault, err)
+		}
+	}
+
+	cfg.RecipientFormat = format
+	if format == config.RecipientFormatEmail {
+		cfg.RecipientFormat = ""
+	}
+	if err := config.SaveConfig(secretsDir, cfg); err != nil {
+		return fmt.Errorf(".gpg-id files rewritten but failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Migrated %d vault(s) to %s recipients\n", len(rewrites), format)
+	recordChange("migrate to %s recipients", format)
+	return nil
+}
+
+// sameIDs reports whether two recipient lists hold the same entries in any
+// order, ignoring case
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := map[string]bool{}
+	for _, id := range a {
+		set[strings.ToLower(id)] = true
+	}
+	for _, id := range b {
+		if !set[strings.ToLower(id)] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameKeys reports whether two recipient lists, each given by email or
+// fingerprint, name the same keys in the keyring
+func sameKeys(g *gpg.GPG, a, b []string) bool {
+	fingerprints := func(ids []string) ([]string, bool) {
+		var fprs []string
+		for _, id := range ids {
+			fpr, err := g.GetFingerprint(id)
+			if err != nil {
+				return nil, false
+			}
+			fprs = append(fprs, fpr)
+		}
+		return fprs, true
+	}
+	fa, ok := fingerprints(a)
+	if !ok {
+		return false
+	}
+	fb, ok := fingerprints(b)
+	return ok && sameIDs(fa, fb)
+}
+
+// importRecipientKeys imports the keys of recipients that are missing from
+// the keyring from keys/, so they can be encrypted to or resolved
+func importRecipientKeys(g *gpg.GPG, secretsDir string, recipients []string) {
+	keysDir := config.GetKeysDir(secretsDir)
+	for _, recipient := range recipients {
+		keyPath := filepath.Join(keysDir, recipient+".asc")
+		if _, err := os.Stat(keyPath); err == nil && !g.KeyExists(recipient) {
+			_ = g.ImportKey(keyPath)
+		}
+	}
+}
+
+// moveDir moves a directory, copying it when it crosses filesystems
+func moveDir(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+		return err
+	}
+	err := os.Rename(from, to)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := copyDir(from, to); err != nil {
+		os.RemoveAll(to)
+		return err
+	}
+	return os.RemoveAll(from)
+}
+
+// copyDir recursively copies a directory tree, keeping file modes
+func copyDir(from, to string) error {
+	return copyTree(from, to, nil)
+}
+
+// copyTree is copyDir leaving out every path, relative to from, for which
+// skip returns true, and everything below it
+func copyTree(from, to string, skip func(rel string) bool) error {
+	return filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		if skip != nil && rel != "." && skip(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target := filepath.Join(to, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("unsupported file type: %s", path)
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			dst.Close()
+			return err
+		}
+		return dst.Close()
+	})
+}
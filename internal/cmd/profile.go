@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage identities for working under several emails and keyrings",
+	Long: `Manage profiles stored in ~/.config/secrets-cli/profiles.yaml (or
+$XDG_CONFIG_HOME/secrets-cli/profiles.yaml).
+
+A profile bundles an email, a GnuPG home directory and a gpg binary, so one
+--profile flag replaces --email, --gnupg-home and --gpg-binary. The profile
+chosen with 'profile use' applies to every command until another one is
+chosen; --profile overrides it for one command. Flags given explicitly
+still win over the profile, and the profile wins over USER_EMAIL and
+GPG_BINARY.
+
+A profile only points at a keyring: keys stay in the GnuPG home directory
+and are never written to profiles.yaml.
+
+Examples:
+  secrets-cli profile add work --email alice@company.com --gnupg-home ~/.gnupg-work
+  secrets-cli profile add personal --email alice@example.com
+  secrets-cli profile use work
+  secrets-cli --profile personal get dev db/password
+  secrets-cli profile list`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	RunE:  runProfileList,
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or replace a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileAdd,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name> | use --none",
+	Short: "Choose the profile used when --profile is not given",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE:  runProfileUse,
+}
+
+var (
+	profileName      string
+	profileEmail     string
+	profileGNUPGHome string
+	profileGPGBinary string
+	profileNone      bool
+)
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileAddCmd)
+	profileCmd.AddCommand(profileUseCmd)
+
+	profileAddCmd.Flags().StringVar(&profileEmail, "email", "", "Email of the identity (required)")
+	profileAddCmd.Flags().StringVar(&profileGNUPGHome, "gnupg-home", "", "GnuPG home directory holding its keys (default: $GNUPGHOME)")
+	profileAddCmd.Flags().StringVar(&profileGPGBinary, "gpg-binary", "", "gpg binary to use (default: gpg)")
+	profileUseCmd.Flags().BoolVar(&profileNone, "none", false, "Stop using a profile by default")
+}
+
+// activeProfile returns the profile chosen with --profile, else the one
+// chosen with 'profile use', or nil if there is none
+func activeProfile() (*config.Profile, error) {
+	name := profileName
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		if name == "" {
+			return nil, nil // an unreadable file only matters if asked for
+		}
+		return nil, err
+	}
+	if name == "" {
+		name = profiles.Current
+	}
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := profiles.Profiles[name]
+	if !ok {
+		return nil, notFoundErrorf("profile not found: %s. List profiles with: secrets-cli profile list", name)
+	}
+	return &profile, nil
+}
+
+// currentProfile is activeProfile without the error, for the getters of
+// global settings; PersistentPreRunE reports the error before they run
+func currentProfile() *config.Profile {
+	profile, _ := activeProfile()
+	return profile
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles.Profiles) == 0 {
+		fmt.Println("No profiles found. Create one with: secrets-cli profile add <name> --email <email>")
+		return nil
+	}
+
+	fmt.Println("Profiles:")
+	for _, name := range profiles.Names() {
+		profile := profiles.Profiles[name]
+		marker := " "
+		if name == profiles.Current {
+			marker = "*"
+		}
+		details := []string{profile.Email}
+		if profile.GNUPGHome != "" {
+			details = append(details, "gnupg home "+profile.GNUPGHome)
+		}
+		if profile.GPGBinary != "" {
+			details = append(details, "gpg "+profile.GPGBinary)
+		}
+		fmt.Printf("%s %s: %s\n", marker, name, strings.Join(details, ", "))
+	}
+	return nil
+}
+
+func runProfileAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if profileEmail == "" {
+		return validationErrorf("--email is required")
+	}
+	if err := validateEmail(profileEmail); err != nil {
+		return err
+	}
+
+	profile := config.Profile{Email: profileEmail, GPGBinary: profileGPGBinary}
+	if profileGNUPGHome != "" {
+		// Keep "~/" so the file works across machines; store other paths absolute
+		home := profileGNUPGHome
+		if !strings.HasPrefix(home, "~/") {
+			abs, err := filepath.Abs(home)
+			if err != nil {
+				return err
+			}
+			home = abs
+		}
+		if info, err := os.Stat(config.ExpandHome(home)); err != nil || !info.IsDir() {
+			return notFoundErrorf("GnuPG home directory not found: %s", profileGNUPGHome)
+		}
+		profile.GNUPGHome = home
+	}
+
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	_, replaced := profiles.Profiles[name]
+	profiles.Profiles[name] = profile
+	if err := config.SaveProfiles(profiles); err != nil {
+		return err
+	}
+
+	if replaced {
+		fmt.Printf("✓ Updated profile %s\n", name)
+	} else {
+		fmt.Printf("✓ Added profile %s\n", name)
+	}
+	if profiles.Current != name {
+		fmt.Printf("  Use it with --profile %s, or by default with: secrets-cli profile use %s\n", name, name)
+	}
+	return nil
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	if (len(args) == 1) == profileNone {
+		return validationErrorf("give a profile name or --none")
+	}
+
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	if profileNone {
+		profiles.Current = ""
+	} else {
+		if _, ok := profiles.Profiles[args[0]]; !ok {
+			return notFoundErrorf("profile not found: %s. List profiles with: secrets-cli profile list", args[0])
+		}
+		profiles.Current = args[0]
+	}
+	if err := config.SaveProfiles(profiles); err != nil {
+		return err
+	}
+
+	if profileNone {
+		fmt.Println("✓ No profile is used by default anymore")
+	} else {
+		fmt.Printf("✓ Using profile %s by default\n", args[0])
+	}
+	return nil
+}
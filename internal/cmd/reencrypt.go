@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var reencryptAllCmd = &cobra.Command{
+	Use:   "reencrypt-all",
+	Short: "Synchronize every vault you are a member of",
+	Long: `Re-encrypt every vault you are a member of for its current recipients,
+as 'sync' does for one vault. Run it after adding a backup recipient or
+rotating a key.
+
+Vaults you are not a read-write member of are skipped. With --all-vaults
+every vault is attempted; this is limited to the store owner and only
+succeeds where their key can decrypt the secrets, e.g. when holding a
+recovery key.
+
+A failing vault does not stop the others. Each vault's result is reported
+and the command exits nonzero if any vault failed.
+
+Examples:
+  secrets-cli reencrypt-all
+  secrets-cli reencrypt-all --dry-run
+  secrets-cli reencrypt-all --all-vaults`,
+	Args: cobra.NoArgs,
+	RunE: runReencryptAll,
+}
+
+var reencryptAllVaults bool
+
+func init() {
+	rootCmd.AddCommand(reencryptAllCmd)
+
+	reencryptAllCmd.Flags().BoolVar(&reencryptAllVaults, "all-vaults", false, "Attempt every vault, not only those you are a member of")
+	reencryptAllCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the re-encryption plan for each vault without changing anything")
+}
+
+func runReencryptAll(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	// Skipping the membership checks is for the store owner alone
+	if reencryptAllVaults {
+		cfg, err := config.LoadConfig(secretsDir)
+		if err != nil {
+			return err
+		}
+		if email == "" || !strings.EqualFold(cfg.Owner, email) {
+			return accessDeniedErrorf("Access denied: --all-vaults is limited to the store owner")
+		}
+	}
+
+	vaults, err := config.ListVaults(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	var synced, skipped, failed []string
+	for _, vaultName := range vaults {
+		if !reencryptAllVaults && !hasWriteAccess(secretsDir, vaultName, email) {
+			skipped = append(skipped, vaultName)
+			continue
+		}
+		if err := syncVault(secretsDir, vaultName); err != nil {
+			fmt.Printf("✗ Failed to synchronize %s: %v\n", vaultName, err)
+			failed = append(failed, vaultName)
+			continue
+		}
+		synced = append(synced, vaultName)
+	}
+
+	verb := "Synchronized"
+	if dryRun {
+		verb = "Planned"
+	}
+	fmt.Println()
+	fmt.Printf("%s %d vault(s), skipped %d, failed %d\n", verb, len(synced), len(skipped), len(failed))
+	if len(skipped) > 0 {
+		fmt.Printf("  Skipped (not a read-write member): %s\n", strings.Join(skipped, ", "))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to synchronize %d vault(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
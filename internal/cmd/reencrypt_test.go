@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+)
+
+func TestRunReencryptAll(t *testing.T) {
+	t.Setenv("GNUPGHOME", t.TempDir())
+	secretsDir := t.TempDir()
+	t.Setenv("SECRETS_DIR", secretsDir)
+	t.Setenv("USER_EMAIL", "alice@example.com")
+	if err := config.SaveConfig(secretsDir, &config.Config{Owner: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveGroups(secretsDir, config.Groups{"ops": {"alice@example.com", "bob@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	vaults := map[string]*config.VaultConfig{
+		// Synchronized: alice is a read-write member and it has no secrets
+		"dev": {Name: "dev", Members: []string{"alice@example.com"}},
+		// Failed: the group gained bob, whose key is not in keys/
+		"ops": {
+			Name:    "ops",
+			Members: []string{"alice@example.com"},
+			Groups:  map[string][]string{"ops": {"alice@example.com"}},
+		},
+		// Skipped: alice is not a member
+		"prod": {Name: "prod", Members: []string{"bob@example.com"}},
+	}
+	for name, vaultCfg := range vaults {
+		if err := os.MkdirAll(config.GetStoreDir(secretsDir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.SaveVaultConfig(config.GetVaultDir(secretsDir, name), vaultCfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gpgID := func(vault string) string {
+		data, _ := os.ReadFile(filepath.Join(config.GetStoreDir(secretsDir, vault), ".gpg-id"))
+		return strings.TrimSpace(string(data))
+	}
+
+	err := runReencryptAll(reencryptAllCmd, nil)
+	if err == nil || ExitCode(err) == ExitOK || !strings.Contains(err.Error(), "failed to synchronize 1 vault(s): ops") {
+		t.Fatalf("runReencryptAll() err = %v, want ops to fail", err)
+	}
+	if got := gpgID("dev"); got != "alice@example.com" {
+		t.Errorf("dev .gpg-id = %q, want it synchronized", got)
+	}
+	if got := gpgID("prod"); got != "" {
+		t.Errorf("prod .gpg-id = %q, want it skipped", got)
+	}
+
+	// --all-vaults is for the store owner alone
+	reencryptAllVaults = true
+	defer func() { reencryptAllVaults = false }()
+	t.Setenv("USER_EMAIL", "bob@example.com")
+	if err := runReencryptAll(reencryptAllCmd, nil); ExitCode(err) != ExitAccessDenied {
+		t.Errorf("--all-vaults as a member: err = %v, want access denied", err)
+	}
+	if got := gpgID("prod"); got != "" {
+		t.Errorf("prod .gpg-id = %q after a refused --all-vaults", got)
+	}
+	t.Setenv("USER_EMAIL", "alice@example.com")
+	if err := runReencryptAll(reencryptAllCmd, nil); err == nil || !strings.Contains(err.Error(), ": ops") {
+		t.Errorf("--all-vaults as the owner: err = %v, want only ops to fail", err)
+	}
+	if got := gpgID("prod"); got != "bob@example.com" {
+		t.Errorf("prod .gpg-id = %q, want it synchronized with --all-vaults", got)
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex [vault...]",
+	Short: "Find damaged secret files, such as ones cut short by a merge",
+	Long: `Scan the password store of every vault, or of the given vaults, and check
+the packet structure of each secret file without decrypting it. A file is
+reported as damaged when gpg cannot list its packets, when it has no
+recipient or no encrypted data, or when its packet lengths do not add up
+to its size, as happens after an interrupted git merge or a partial write.
+
+Damaged files break commands that read every secret, such as export.
+Restore them from git if you can. Otherwise --quarantine moves them into
+a .corrupt/ directory at the top of the vault's password store, keeping
+their paths, so they are no longer listed. Nothing is ever deleted, and
+nothing is moved without --quarantine. Quarantining needs write access to
+the vault.
+
+The command exits nonzero if damaged files were found and not
+quarantined.
+
+Examples:
+  secrets-cli reindex
+  secrets-cli reindex dev
+  secrets-cli reindex dev --quarantine`,
+	RunE: runReindex,
+}
+
+var reindexQuarantine bool
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+
+	reindexCmd.Flags().BoolVar(&reindexQuarantine, "quarantine", false, "Move damaged files into .corrupt/ in the password store")
+}
+
+// corruptDir is where reindex --quarantine moves damaged secrets, relative
+// to the password store. Hidden directories are not listed as secrets.
+const corruptDir = ".corrupt"
+
+func runReindex(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	vaults := args
+	if len(vaults) == 0 {
+		var err error
+		if vaults, err = config.ListVaults(secretsDir); err != nil {
+			return err
+		}
+	}
+	for _, vaultName := range vaults {
+		if err := validateName(vaultName); err != nil {
+			return err
+		}
+		if _, err := os.Stat(config.GetVaultDir(secretsDir, vaultName)); os.IsNotExist(err) {
+			return notFoundErrorf("vault not found: %s", vaultName)
+		}
+	}
+
+	g := newGPG()
+	total, damaged, quarantined := 0, 0, 0
+	for _, vaultName := range vaults {
+		storeDir := config.GetStoreDir(secretsDir, vaultName)
+		secrets, err := newPass(storeDir).List()
+		if err != nil {
+			return fmt.Errorf("failed to list secrets of %s: %w", vaultName, err)
+		}
+		total += len(secrets)
+
+		var corrupt []string
+		for _, secret := range secrets {
+			if err := g.CheckPackets(filepath.Join(storeDir, secret+".gpg")); err != nil {
+				fmt.Printf("✗ %s/%s: %v\n", vaultName, secret, err)
+				corrupt = append(corrupt, secret)
+			}
+		}
+		damaged += len(corrupt)
+		if len(corrupt) == 0 || !reindexQuarantine {
+			continue
+		}
+
+		if err := checkWriteAccess(secretsDir, vaultName, email); err != nil {
+			return err
+		}
+		if err := checkNotArchived(secretsDir, vaultName); err != nil {
+			return err
+		}
+		err = config.WithVaultLock(config.GetVaultDir(secretsDir, vaultName), func() error {
+			for _, secret := range corrupt {
+				dest, err := quarantineSecret(storeDir, secret)
+				if err != nil {
+					return fmt.Errorf("failed to quarantine %s/%s: %w", vaultName, secret, err)
+				}
+				fmt.Printf("  Moved to %s\n", dest)
+				recordChange("quarantine %s/%s", vaultName, secret)
+				quarantined++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case damaged == 0:
+		fmt.Printf("✓ All %d secret(s) in %d vault(s) are intact\n", total, len(vaults))
+	case quarantined == damaged:
+		fmt.Printf("✓ Quarantined %d damaged secret(s) of %d; restore them from git or set them again\n", quarantined, total)
+	default:
+		return fmt.Errorf("%d of %d secret(s) are damaged; restore them from git or move them aside with --quarantine", damaged, total)
+	}
+	return nil
+}
+
+// quarantineSecret moves a secret's file into corruptDir under the same
+// path, never replacing an earlier quarantined copy, and returns where it
+// went
+func quarantineSecret(storeDir, secret string) (string, error) {
+	src := filepath.Join(storeDir, filepath.FromSlash(secret)+".gpg")
+	base := filepath.Join(storeDir, corruptDir, filepath.FromSlash(secret)+".gpg")
+	if err := os.MkdirAll(filepath.Dir(base), 0700); err != nil {
+		return "", err
+	}
+	dest := base
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(dest); os.IsNotExist(err) {
+			break
+		}
+		dest = fmt.Sprintf("%s.%d", base, i)
+	}
+	if err := os.Rename(src, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
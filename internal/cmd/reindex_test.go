@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuarantineSecret(t *testing.T) {
+	storeDir := t.TempDir()
+	write := func(content string) {
+		t.Helper()
+		path := filepath.Join(storeDir, "db", "password.gpg")
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("first")
+	dest, err := quarantineSecret(storeDir, "db/password")
+	if err != nil {
+		t.Fatalf("quarantineSecret() error = %v", err)
+	}
+	if want := filepath.Join(storeDir, ".corrupt", "db", "password.gpg"); dest != want {
+		t.Errorf("dest = %s, want %s", dest, want)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, "db", "password.gpg")); !os.IsNotExist(err) {
+		t.Errorf("the secret is still in place: %v", err)
+	}
+
+	// A second damaged copy must not replace the first
+	write("second")
+	dest, err = quarantineSecret(storeDir, "db/password")
+	if err != nil {
+		t.Fatalf("quarantineSecret() error = %v", err)
+	}
+	if want := filepath.Join(storeDir, ".corrupt", "db", "password.gpg.1"); dest != want {
+		t.Errorf("dest = %s, want %s", dest, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(storeDir, ".corrupt", "db", "password.gpg")); string(data) != "first" {
+		t.Errorf("first copy = %q, want first", data)
+	}
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/NuevaNext/secrets-cli/internal/pass"
+	"github.com/spf13/cobra"
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render a config template with secret values",
+	Long: `Render a template file, replacing secret references with their values.
+
+Two reference styles are supported:
+  ${vault/name}               e.g. ${dev/database/password}
+  {{secret "vault" "name"}}   Go text/template function
+  {{secret "name"}}           uses the vault given by --vault
+
+Only referenced secrets are decrypted. If any reference cannot be resolved,
+nothing is written and all unresolved references are reported at once.
+Output goes to stdout, or to --out (created with 0600 permissions).
+
+Examples:
+  secrets-cli render --template config.tmpl --vault dev
+  secrets-cli render --template app.yaml.tmpl --out app.yaml`,
+	RunE: runRender,
+}
+
+var (
+	renderTemplatePath string
+	renderVault        string
+	renderOut          string
+)
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+
+	renderCmd.Flags().StringVarP(&renderTemplatePath, "template", "t", "", "Template file to render")
+	renderCmd.Flags().StringVar(&renderVault, "vault", "", "Default vault for {{secret \"name\"}} references")
+	renderCmd.Flags().StringVarP(&renderOut, "out", "o", "", "Write output to this file instead of stdout")
+	_ = renderCmd.MarkFlagRequired("template")
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	data, err := os.ReadFile(renderTemplatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	stores := map[string]*pass.Pass{}
+	lookup := func(vaultName, secretName string) (string, error) {
+		p, ok := stores[vaultName]
+		if !ok {
+			if err := validateName(vaultName); err != nil {
+				return "", err
+			}
+			vaultDir := config.GetVaultDir(secretsDir, vaultName)
+			if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+				return "", notFoundErrorf("vault not found")
+			}
+			if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+				return "", err
+			}
+			p = newPass(config.GetStoreDir(secretsDir, vaultName))
+			stores[vaultName] = p
+		}
+
+		if err := validateSecretName(secretName); err != nil {
+			return "", err
+		}
+		if !p.Exists(secretName) {
+			return "", notFoundErrorf("secret not found")
+		}
+		return p.Show(secretName)
+	}
+
+	out, err := renderTemplate(string(data), renderVault, lookup)
+	if err != nil {
+		return err
+	}
+
+	if renderOut == "" {
+		fmt.Print(out)
+		return nil
+	}
+
+	if err := writeFileAtomic(renderOut, []byte(out), 0600); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "✓ Rendered %s to %s\n", renderTemplatePath, renderOut)
+	return nil
+}
+
+// placeholderRegex matches ${vault/name} references. A slash is required, so
+// ordinary ${VAR} shell-style variables are left alone.
+var placeholderRegex = regexp.MustCompile(`\$\{([A-Za-z0-9_.@-]+)/([^}\s]+)\}`)
+
+// renderTemplate resolves ${vault/name} placeholders and {{secret ...}} calls
+// using lookup. Every reference is attempted so that all failures can be
+// reported together. Placeholders are rewritten into secret calls before
+// parsing, so only the template text is searched for them, never the
+// values of the secrets it pulls in.
+func renderTemplate(text, defaultVault string, lookup func(vault, name string) (string, error)) (string, error) {
+	var unresolved []string
+	seen := map[string]bool{}
+	resolve := func(vaultName, secretName string) string {
+		value, err := lookup(vaultName, secretName)
+		if err != nil {
+			ref := vaultName + "/" + secretName
+			if !seen[ref] {
+				seen[ref] = true
+				unresolved = append(unresolved, fmt.Sprintf("%s (%v)", ref, err))
+			}
+			return ""
+		}
+		return value
+	}
+
+	funcs := template.FuncMap{
+		"secret": func(args ...string) (string, error) {
+			switch len(args) {
+			case 1:
+				if defaultVault == "" {
+					return "", fmt.Errorf("secret %q has no vault; pass --vault or use {{secret \"vault\" \"name\"}}", args[0])
+				}
+				return resolve(defaultVault, args[0]), nil
+			case 2:
+				return resolve(args[0], args[1]), nil
+			default:
+				return "", fmt.Errorf("secret takes 1 or 2 arguments, got %d", len(args))
+			}
+		},
+	}
+
+	text = placeholderRegex.ReplaceAllStringFunc(text, func(match string) string {
+		m := placeholderRegex.FindStringSubmatch(match)
+		return fmt.Sprintf("{{secret %s %s}}", strconv.Quote(m[1]), strconv.Quote(m[2]))
+	})
+
+	tmpl, err := template.New("render").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+
+	if len(unresolved) > 0 {
+		return "", fmt.Errorf("%d unresolved secret reference(s):\n  %s", len(unresolved), strings.Join(unresolved, "\n  "))
+	}
+	return buf.String(), nil
+}
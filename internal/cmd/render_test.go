@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values := map[string]string{
+		"dev/db/password": "s3cret",
+		"dev/api/key":     "abc",
+		"prod/api/key":    "xyz",
+		"dev/literal":     "pa${dev/db/password}ss {{secret \"api/key\"}}",
+	}
+	lookup := func(vault, name string) (string, error) {
+		if v, ok := values[vault+"/"+name]; ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("secret not found")
+	}
+
+	t.Run("BothStyles", func(t *testing.T) {
+		in := "db=${dev/db/password}\nhome=${HOME}\napi={{secret \"api/key\"}}\nprod={{secret \"prod\" \"api/key\"}}\n"
+		want := "db=s3cret\nhome=${HOME}\napi=abc\nprod=xyz\n"
+		got, err := renderTemplate(in, "dev", lookup)
+		if err != nil {
+			t.Fatalf("renderTemplate() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("renderTemplate() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("ValuesAreNotExpanded", func(t *testing.T) {
+		in := "${dev/literal}\n{{secret \"literal\"}}\n"
+		want := values["dev/literal"] + "\n" + values["dev/literal"] + "\n"
+		got, err := renderTemplate(in, "dev", lookup)
+		if err != nil {
+			t.Fatalf("renderTemplate() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("renderTemplate() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("ReportsAllUnresolved", func(t *testing.T) {
+		in := "${dev/missing/one} {{secret \"missing/two\"}} ${dev/missing/one}"
+		_, err := renderTemplate(in, "dev", lookup)
+		if err == nil {
+			t.Fatal("expected error for unresolved references")
+		}
+		for _, ref := range []string{"dev/missing/one", "dev/missing/two"} {
+			if !strings.Contains(err.Error(), ref) {
+				t.Errorf("error %q does not mention %s", err, ref)
+			}
+		}
+		if !strings.HasPrefix(err.Error(), "2 unresolved") {
+			t.Errorf("expected duplicates to be reported once, got %q", err)
+		}
+	})
+}
@@ -6,7 +6,12 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
+	"github.com/NuevaNext/secrets-cli/internal/pass"
 	"github.com/spf13/cobra"
 )
 
@@ -15,8 +20,20 @@ var (
 	secretsDir string
 	userEmail  string
 	gpgBinary  string
+	gnupgHome  string
+	gpgRetries int
 	verbose    bool
 
+	migrateConfigs bool
+	cmdTimeout     time.Duration
+	batchMode      bool
+	memoryCache    bool
+	looseVerify    bool
+	trustModel     string
+	noForceTrust   bool
+	gpgOpts        string
+	followSymlinks bool
+
 	// Version info
 	versionInfo struct {
 		Version string
@@ -46,12 +63,24 @@ Quick Start:
   secrets-cli set dev database/password "secret123"
   secrets-cli get dev database/password
 
+` + exitCodesHelp + `
+
 For more information, visit: https://github.com/NuevaNext/secrets-cli`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.ValidateTrustModel(trustModel); err != nil {
+			return validationErrorf("%w", err)
+		}
+		if _, err := activeProfile(); err != nil {
+			return err
+		}
+		return nil
+	},
 }
 
-// Execute runs the root command
+// Execute runs the root command. Map a returned error to the process exit
+// status with ExitCode.
 func Execute() error {
 	return rootCmd.Execute()
 }
@@ -64,11 +93,47 @@ func SetVersionInfo(version, commit, date string) {
 }
 
 func init() {
+	cobra.OnInitialize(func() {
+		// Trace gpg and pass command lines with --verbose
+		gpg.SetVerbose(IsVerbose())
+		pass.SetVerbose(IsVerbose())
+		pass.SetLooseVerify(looseVerify)
+		config.SetRewriteMigrated(migrateConfigs)
+		gpg.SetTimeout(cmdTimeout)
+		// Stop gpg and pass before an interrupted command releases its lock
+		config.SetInterruptHandler(gpg.Interrupt)
+		config.SetFingerprintResolver(func(email string) (string, error) {
+			return newGPG().GetFingerprint(email)
+		})
+	})
+
+	// --commit and auto_commit commit what a successful command changed
+	rootCmd.PersistentPostRunE = commitAfterRun
+
+	// Unknown or malformed flags are validation errors
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return validationErrorf("%w", err)
+	})
+
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&secretsDir, "secrets-dir", ".secrets", "Path to secrets directory")
 	rootCmd.PersistentFlags().StringVar(&userEmail, "email", "", "User email for GPG operations")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Profile from ~/.config/secrets-cli/profiles.yaml supplying email, GnuPG home and gpg binary")
 	rootCmd.PersistentFlags().StringVar(&gpgBinary, "gpg-binary", "gpg", "Path to GPG binary")
+	rootCmd.PersistentFlags().StringVar(&gnupgHome, "gnupg-home", "", "GnuPG home directory for all gpg and pass operations (default: $GNUPGHOME)")
+	rootCmd.PersistentFlags().IntVar(&gpgRetries, "gpg-retries", 2, "Retries after transient gpg-agent errors")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().BoolVar(&batchMode, "batch", false, "Never prompt: run gpg with --batch and loopback pinentry, reading the passphrase from GPG_PASSPHRASE")
+	rootCmd.PersistentFlags().BoolVar(&memoryCache, "cache", false, "Decrypt each secret at most once per run, keeping values in memory only")
+	rootCmd.PersistentFlags().BoolVar(&looseVerify, "loose", false, "Verify re-encryption by recipient count only, without matching key IDs")
+	rootCmd.PersistentFlags().StringVar(&trustModel, "trust-model", "", "gpg trust model for encrypting: always, direct, pgp, ... (default: trust_model from config.yaml, else always)")
+	rootCmd.PersistentFlags().StringVar(&gpgOpts, "gpg-opts", "", "Extra gpg options for pass, after the trust model and the vault's gpg_opts, e.g. '--compress-algo none'")
+	rootCmd.PersistentFlags().BoolVar(&followSymlinks, "follow-symlinks", false, "List secrets in symlinked directories of a password store (never ones outside it)")
+	rootCmd.PersistentFlags().BoolVar(&noForceTrust, "no-force-trust", false, "Pass no --trust-model to gpg; use gpg.conf and PASSWORD_STORE_GPG_OPTS as they are")
+	rootCmd.PersistentFlags().DurationVar(&cmdTimeout, "timeout", 0, "Kill gpg and pass processes that run longer than this (e.g. 60s); 0 disables")
+	rootCmd.PersistentFlags().BoolVar(&autoCommit, "commit", false, "After a successful change, git commit the secrets directory (default: auto_commit from config.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&autoPush, "push", false, "Like --commit, and push the commit (default: auto_push from config.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&migrateConfigs, "migrate", false, "Rewrite vault configs from older schema versions when they are loaded")
 
 	// Version command
 	rootCmd.AddCommand(&cobra.Command{
@@ -117,6 +182,9 @@ func GetUserEmail() string {
 	if userEmail != "" {
 		return userEmail
 	}
+	if profile := currentProfile(); profile != nil && profile.Email != "" {
+		return profile.Email
+	}
 	if envEmail := os.Getenv("USER_EMAIL"); envEmail != "" {
 		return envEmail
 	}
@@ -136,9 +204,8 @@ func detectUserEmail() string {
 	}
 
 	// Try GPG default key
-	gpgBin := GetGPGBinary()
-	cmd = exec.Command(gpgBin, "--list-secret-keys", "--keyid-format", "long")
-	if output, err := cmd.Output(); err == nil {
+	gpgCmd := newGPG().Command("--list-secret-keys", "--keyid-format", "long")
+	if output, err := gpgCmd.Output(); err == nil {
 		// Parse email from GPG output
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
@@ -158,13 +225,94 @@ func detectUserEmail() string {
 
 // GetGPGBinary returns the GPG binary path
 func GetGPGBinary() string {
-	if gpgBinary == "" {
-		if envGPG := os.Getenv("GPG_BINARY"); envGPG != "" {
-			return envGPG
+	if rootCmd.PersistentFlags().Changed("gpg-binary") && gpgBinary != "" {
+		return gpgBinary
+	}
+	if profile := currentProfile(); profile != nil && profile.GPGBinary != "" {
+		return profile.GPGBinary
+	}
+	if envGPG := os.Getenv("GPG_BINARY"); envGPG != "" {
+		return envGPG
+	}
+	return "gpg"
+}
+
+// GetGNUPGHome returns the GnuPG home directory set with --gnupg-home or by
+// the profile, made absolute. An empty result means child processes inherit
+// GNUPGHOME.
+func GetGNUPGHome() string {
+	if gnupgHome == "" {
+		if profile := currentProfile(); profile != nil && profile.GNUPGHome != "" {
+			return config.ExpandHome(profile.GNUPGHome)
+		}
+		return ""
+	}
+	if abs, err := filepath.Abs(gnupgHome); err == nil {
+		return abs
+	}
+	return gnupgHome
+}
+
+// GetTrustModel returns the gpg trust model for encrypting: --trust-model,
+// else trust_model from config.yaml, else always
+func GetTrustModel() string {
+	if trustModel != "" {
+		return trustModel
+	}
+	if cfg, err := config.LoadConfig(GetSecretsDir()); err == nil && cfg.TrustModel != "" {
+		return cfg.TrustModel
+	}
+	return config.TrustModelAlways
+}
+
+// newGPG returns a gpg wrapper configured from the global flags
+func newGPG() *gpg.GPG {
+	g := gpg.New(GetGPGBinary())
+	g.Home = GetGNUPGHome()
+	g.Retries = gpgRetries
+	g.TrustModel = GetTrustModel()
+	g.NoForceTrust = noForceTrust
+	if batchMode {
+		g.Batch = true
+		g.Passphrase = os.Getenv("GPG_PASSPHRASE")
+	}
+	return g
+}
+
+// newPass returns a pass wrapper for storeDir configured from the global flags
+func newPass(storeDir string) *pass.Pass {
+	p := pass.New(storeDir)
+	p.GPG = newGPG()
+	p.Fingerprints = config.UsesFingerprints(GetSecretsDir())
+	p.VaultGPGOpts = vaultGPGOptsFor(storeDir)
+	p.GPGOpts = strings.Fields(gpgOpts)
+	p.FollowSymlinks = followSymlinks
+	if memoryCache {
+		p.EnableCache()
+	}
+	return p
+}
+
+// vaultGPGOptsFor returns the gpg_opts of the vault a password store
+// belongs to. LoadVaultConfig rejects any option but algorithm choices.
+func vaultGPGOptsFor(storeDir string) []string {
+	secretsDir := GetSecretsDir()
+	vaults, err := config.ListVaults(secretsDir)
+	if err != nil {
+		return nil
+	}
+	for _, vaultName := range vaults {
+		if filepath.Clean(config.GetStoreDir(secretsDir, vaultName)) != filepath.Clean(storeDir) {
+			continue
 		}
-		return "gpg"
+		vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+		if err != nil {
+			return nil
+		}
+		opts, _ := config.ValidateVaultGPGOpts(vaultCfg.GPGOpts)
+		return opts
 	}
-	return gpgBinary
+	return nil
 }
 
 // IsVerbose returns whether verbose mode is enabled
@@ -176,11 +324,42 @@ func IsVerbose() bool {
 // (e.g. vault names, emails)
 func validateName(name string) error {
 	if name == "" {
-		return fmt.Errorf("name cannot be empty")
+		return validationErrorf("name cannot be empty")
 	}
 	// Prevent path traversal and argument injection
 	if strings.Contains(name, "..") || strings.ContainsAny(name, "/\\") || strings.HasPrefix(name, "-") {
-		return fmt.Errorf("invalid name: %s (contains illegal characters or path traversal)", name)
+		return validationErrorf("invalid name: %s (contains illegal characters or path traversal)", name)
+	}
+	return nil
+}
+
+// validateEmail ensures an email is safe to use as a key file name and has a
+// basic local@domain shape. Plus tags and subdomains are allowed.
+func validateEmail(email string) error {
+	if err := validateName(email); err != nil {
+		return err
+	}
+	if strings.ContainsFunc(email, unicode.IsSpace) {
+		return validationErrorf("invalid email: %q (contains whitespace)", email)
+	}
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" || domain == "" || strings.Contains(domain, "@") {
+		return validationErrorf("invalid email: %s (expected local@domain)", email)
+	}
+	if strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return validationErrorf("invalid email: %s (malformed domain)", email)
+	}
+	return nil
+}
+
+// validateRecipient checks a recipient that is not a member, such as a
+// recovery key: a full key fingerprint or an email
+func validateRecipient(id string) error {
+	if gpg.IsFingerprint(id) {
+		return nil
+	}
+	if err := validateEmail(id); err != nil {
+		return validationErrorf("invalid recipient: %s (expected an email or a 40-character fingerprint)", id)
 	}
 	return nil
 }
@@ -189,7 +368,7 @@ func validateName(name string) error {
 // It allows slashes for organization but prevents traversal and argument injection.
 func validateSecretName(name string) error {
 	if name == "" {
-		return fmt.Errorf("secret name cannot be empty")
+		return validationErrorf("secret name cannot be empty")
 	}
 	// Prevent path traversal, backslashes, double slashes, and leading/trailing slashes
 	if strings.Contains(name, "..") ||
@@ -198,7 +377,7 @@ func validateSecretName(name string) error {
 		strings.HasPrefix(name, "/") ||
 		strings.HasSuffix(name, "/") ||
 		strings.HasPrefix(name, "-") {
-		return fmt.Errorf("invalid secret name: %s (must not contain '..', '\\', '//', or start/end with '/')", name)
+		return validationErrorf("invalid secret name: %s (must not contain '..', '\\', '//', or start/end with '/')", name)
 	}
 	return nil
 }
@@ -2,67 +2,205 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/NuevaNext/secrets-cli/internal/cache"
+	"github.com/NuevaNext/secrets-cli/internal/clipboard"
 	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
+	"github.com/NuevaNext/secrets-cli/internal/keychain"
 	"github.com/NuevaNext/secrets-cli/internal/pass"
+	"github.com/NuevaNext/secrets-cli/internal/secretgen"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list <vault>",
+	Use:   "list [vault]",
 	Short: "List all secrets in a vault",
 	Long: `List all secrets stored in a vault.
 
-Use --format names to get just secret names (useful for scripting).
+Use --format names to get just secret names (useful for scripting), or
+--tree to show the secret hierarchy. Listing never decrypts anything.
+
+Use --long (--format long) for a quick integrity view: each secret's
+recipient count, read from its packet headers, with ✓ if it matches the
+vault's members and recovery keys and ✗ otherwise. Nothing is decrypted,
+but gpg is still run once per secret.
+
+Use --show-values to decrypt every secret and print a masked preview of
+its value next to its name, e.g. ab****yz, to check that a set landed
+without exposing it. Only the first line is previewed, and values of 4
+characters or less are fully masked. --unmask prints the full values
+instead and needs --force to confirm.
+
+Secrets are sorted by name. Use --sort none to keep filesystem order.
+
+Use --count to print only the number of secrets, e.g. for dashboards.
+--empty lists only secrets that still need a value: empty ones and
+placeholders such as CHANGEME left by 'vault create --from'. Unlike plain
+listing, --empty decrypts every secret (in parallel, like --show-values).
+The two combine: 'list dev --empty --count' counts unfilled secrets.
+
+For scripts, --print0 prints only the names, each followed by a NUL byte,
+so names with unusual characters survive 'xargs -0'. --delimiter does the
+same with any string after each name, e.g. --delimiter $'\t'. Both imply
+--format names and print nothing for an empty vault.
 
 Examples:
   secrets-cli list dev
-  secrets-cli list production --format names`,
-	Args: cobra.ExactArgs(1),
+  secrets-cli list production --format names
+  secrets-cli list dev --print0 | xargs -0 -n1 secrets-cli get dev
+  secrets-cli list dev --tree
+  secrets-cli list dev --long
+  secrets-cli list dev --show-values
+  secrets-cli list dev --count
+  secrets-cli list dev --empty`,
+	Args: cobra.RangeArgs(0, 1),
 	RunE: runList,
 }
 
 var getCmd = &cobra.Command{
-	Use:   "get <vault> <secret>",
+	Use:   "get [vault] <secret> | get --all [vault] | get --stdin [vault]",
 	Short: "Retrieve and display a secret value",
 	Long: `Retrieve and display the decrypted value of a secret.
 
 The secret name can use slashes for organization (e.g., database/password).
 
+If decryption fails, get reads the secret's recipients from its packet
+headers and reports which stored keys it is encrypted for and whether
+yours is among them. --metadata prints that and more without decrypting
+anything: each recipient key ID with the member it belongs to, expected
+recipients the secret is not encrypted for, the file's size and its git
+status and last commit. No private key is needed.
+
+For scripts that read optional secrets, --quiet-missing prints nothing and
+exits 0 when the secret does not exist, and --default <value> prints that
+value instead. Any other failure, such as access denied or a failed
+decryption, still exits nonzero. --default cannot be combined with --copy,
+--field, --raw or --base64-decode.
+
+Use --use-keychain to cache your GPG passphrase in the system keychain
+(macOS Keychain or libsecret). This is opt-in: anyone able to unlock your
+desktop session can then decrypt your secrets without the passphrase.
+
+Secrets may follow the pass multi-field convention: the first line is the
+password and later lines are "key: value" fields. Use --field-list to see
+which fields exist without printing any values (the first line is listed
+as "password"), then --field to print a single field's value.
+
 Examples:
   secrets-cli get dev database/password
-  secrets-cli get production api/key`,
-	Args: cobra.ExactArgs(2),
+  secrets-cli get production api/key
+  secrets-cli get dev database/conn --field username
+  secrets-cli get dev database/conn --field-list
+  secrets-cli get dev database/password --metadata
+  secrets-cli get dev database/password --use-keychain
+  secrets-cli get dev database/password --cache-ttl 30s
+  secrets-cli get dev database/password --copy --clip-timeout 45
+  secrets-cli get dev tls/keystore --base64-decode > keystore.p12
+  secrets-cli get --all dev
+  printf 'db/user\ndb/password\n' | secrets-cli get dev --stdin
+
+--all prints every secret in the vault as one JSON object keyed by the
+secret path as stored (e.g. "database/password"), unlike 'export --format
+json' which renames keys to environment variable style. Values are
+decrypted concurrently. It fails if any secret cannot be decrypted.
+
+--stdin reads secret names from stdin, one per line, and prints a
+"name<TAB>value" line for each, in input order, decrypting concurrently in
+one process. Values are printed as stored, so use --all when values may
+contain newlines. Secrets that are missing or cannot be decrypted are
+reported on stderr and skipped; the command exits nonzero only if none
+could be read, or with --strict if any could not.
+
+Values are printed trimmed of trailing whitespace, plus a newline. Use
+--raw to print the exact decrypted bytes instead, for binary values or
+values whose whitespace matters, and --base64-decode to print the binary
+content of a secret stored as base64 (such as a TLS keystore); line breaks
+in the base64 text are ignored.
+
+--copy places the value on the system clipboard (pbcopy, clip.exe, wl-copy,
+xclip or xsel) instead of printing it, keeping it out of terminal
+scrollback. --clip-timeout clears the clipboard after that many seconds.
+
+--cache-ttl keeps the decrypted value in a 0600 file on tmpfs for the given
+duration so scripts calling get in a loop don't decrypt every time. It is
+disabled by default and refuses disk-backed locations unless
+--allow-disk-cache is set. Clear it with 'secrets-cli cache purge'.`,
+	Args: cobra.RangeArgs(0, 2),
 	RunE: runGet,
 }
 
 var setCmd = &cobra.Command{
-	Use:   "set <vault> <secret> [value]",
+	Use:   "set [vault] <secret> [value]",
 	Short: "Set a secret value",
 	Long: `Set a secret value. If no value is provided, reads from stdin.
 
+When stdin is a terminal, the value is typed without echo and asked for
+twice, like passwd, and nothing is stored if the two entries differ. Use
+--no-confirm to type it only once. Piped input is read as is.
+
+Without a vault argument the default vault is used (see 'config
+set-default-vault'). The value must then come from stdin or the prompt:
+"set <vault> <secret>" is the only meaning of two arguments.
+
+Use --generate to store a random value from a policy preset instead:
+  strong  24 characters with letters, digits and symbols (default)
+  pin     6 digits
+  token   40 lowercase hex characters
+The generated value is only printed if --show is given.
+
+Use --field key=value to set a single "key: value" line of a multi-field
+secret (see 'get --field'), keeping its password line and other fields. A
+new secret created this way has an empty password line.
+
+set asks for confirmation before storing a value that looks like a mistake:
+an inline value that is the path of an existing file (pipe the file in
+instead), or a placeholder such as changeme or TODO. Without a terminal to
+confirm on, it refuses. Use --force to store the value anyway.
+
 Examples:
   secrets-cli set development database/password "my-password"
-  echo "my-password" | secrets-cli set development database/password`,
-	Args: cobra.RangeArgs(2, 3),
+  echo "my-password" | secrets-cli set development database/password
+  secrets-cli set dev db/password --generate --policy strong
+  secrets-cli set dev db/conn --field username=admin
+  secrets-cli set dev db/password < password.txt`,
+	Args: cobra.RangeArgs(1, 3),
 	RunE: runSet,
 }
 
 var deleteCmd = &cobra.Command{
-	Use:     "delete <vault> <secret>",
+	Use:     "delete [vault] <secret|glob>",
 	Aliases: []string{"rm"},
 	Short:   "Permanently delete a secret",
 	Long: `Permanently delete a secret from a vault.
 
 This action cannot be undone. Use --force to confirm.
 
-Example:
-  secrets-cli delete dev temp/test-secret --force`,
-	Args: cobra.ExactArgs(2),
+With --recursive the argument is a glob, where * does not cross /, and
+every secret matching it or inside a directory matching it is deleted.
+The matches are listed first and deleted after a single confirmation, or
+right away with --force. A glob matching every secret in the vault also
+needs --all. The number deleted is reported, with any that failed.
+
+Examples:
+  secrets-cli delete dev temp/test-secret --force
+  secrets-cli delete dev 'temp/*' --recursive
+  secrets-cli delete dev 'feature-x' --recursive --force
+  secrets-cli delete dev '*' --recursive --all --force`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: runDelete,
 }
 
@@ -72,8 +210,14 @@ var renameCmd = &cobra.Command{
 	Short:   "Rename or move a secret within a vault",
 	Long: `Rename a secret or move it to a different path within the same vault.
 
-Example:
-  secrets-cli rename dev old/path new/path`,
+A trailing slash (or --recursive) moves every secret under that path to
+the new path, keeping their relative names. A path cannot be moved onto
+itself or into its own subtree, and nothing is moved if any destination
+secret already exists.
+
+Examples:
+  secrets-cli rename dev old/path new/path
+  secrets-cli rename dev db/ database/`,
 	Args: cobra.ExactArgs(3),
 	RunE: runRename,
 }
@@ -86,17 +230,59 @@ var copyCmd = &cobra.Command{
 
 You must have access to both vaults. Use --new-name to rename during copy.
 
+A trailing slash (or --recursive) copies every secret under that path,
+keeping their names. Use --dst-prefix to place them under a different path
+in the destination instead.
+
 Examples:
   secrets-cli copy dev database/password staging
-  secrets-cli copy dev api/key production --new-name api/dev_key_backup`,
+  secrets-cli copy dev api/key production --new-name api/dev_key_backup
+  secrets-cli copy dev db/ staging
+  secrets-cli copy dev db staging --recursive --dst-prefix legacy/db`,
 	Args: cobra.ExactArgs(3),
 	RunE: runCopy,
 }
 
 var (
-	listFormat    string
-	forceSecret   bool
-	newSecretName string
+	listFormat      string
+	listLong        bool
+	listTree        bool
+	forceSecret     bool
+	deleteRecurse   bool
+	deleteAll       bool
+	newSecretName   string
+	useKeychain     bool
+	getField        string
+	getFieldList    bool
+	getCacheTTL     time.Duration
+	getCopy         bool
+	getAll          bool
+	getQuietMiss    bool
+	getStdin        bool
+	getStrict       bool
+	getRaw          bool
+	getBase64Decode bool
+	getMetadata     bool
+	getDefault      string
+	getClipTimeout  int
+	allowDiskCache  bool
+	setGenerate     bool
+	setPolicy       string
+	setShow         bool
+	setField        string
+	setForce        bool
+	setNoConfirm    bool
+	copyRecursive   bool
+	renameRecurse   bool
+	copyDstPrefix   string
+	listSort        string
+	listShowValues  bool
+	listUnmask      bool
+	listForce       bool
+	listPrint0      bool
+	listDelimiter   string
+	listCount       bool
+	listEmpty       bool
 )
 
 func init() {
@@ -107,49 +293,190 @@ func init() {
 	rootCmd.AddCommand(renameCmd)
 	rootCmd.AddCommand(copyCmd)
 
-	listCmd.Flags().StringVar(&listFormat, "format", "table", "Output format: table, names")
+	listCmd.Flags().StringVar(&listFormat, "format", "table", "Output format: table, names, tree, long")
+	listCmd.Flags().BoolVar(&listTree, "tree", false, "Show secrets as a tree (same as --format tree)")
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "Show recipient counts and encryption status (same as --format long)")
+	listCmd.Flags().StringVar(&listSort, "sort", "name", "Sort order: name, none")
+	listCmd.Flags().BoolVarP(&listPrint0, "print0", "0", false, "Print only names, each followed by a NUL byte (for xargs -0)")
+	listCmd.Flags().StringVar(&listDelimiter, "delimiter", "", "Print only names, each followed by this string")
+	listCmd.Flags().BoolVar(&listShowValues, "show-values", false, "Decrypt each secret and show a masked preview of its value")
+	listCmd.Flags().BoolVar(&listUnmask, "unmask", false, "With --show-values, print full values instead of masked previews (requires --force)")
+	listCmd.Flags().BoolVarP(&listForce, "force", "f", false, "Confirm --unmask")
+	listCmd.Flags().BoolVar(&listCount, "count", false, "Print only the number of secrets")
+	listCmd.Flags().BoolVar(&listEmpty, "empty", false, "List only secrets whose value is empty or a placeholder (decrypts every secret)")
 	deleteCmd.Flags().BoolVarP(&forceSecret, "force", "f", false, "Force delete without confirmation")
+	deleteCmd.Flags().BoolVarP(&deleteRecurse, "recursive", "r", false, "Delete every secret matching a glob, or under a matching directory")
+	deleteCmd.Flags().BoolVar(&deleteAll, "all", false, "With --recursive, allow a glob that matches every secret in the vault")
 	copyCmd.Flags().StringVar(&newSecretName, "new-name", "", "New name for the copied secret")
+	copyCmd.Flags().BoolVarP(&copyRecursive, "recursive", "r", false, "Copy every secret under the given path")
+	renameCmd.Flags().BoolVarP(&renameRecurse, "recursive", "r", false, "Move every secret under the given path")
+	copyCmd.Flags().StringVar(&copyDstPrefix, "dst-prefix", "", "Destination path for a recursive copy (default: same path)")
+	setCmd.Flags().BoolVar(&setGenerate, "generate", false, "Generate a random value instead of reading one")
+	setCmd.Flags().StringVar(&setPolicy, "policy", secretgen.DefaultPolicy, "Policy for --generate: "+strings.Join(secretgen.Names(), ", "))
+	setCmd.Flags().BoolVar(&setShow, "show", false, "Print the generated value")
+	setCmd.Flags().StringVar(&setField, "field", "", "Set only this key=value field of a multi-field secret")
+	setCmd.Flags().BoolVarP(&setForce, "force", "f", false, "Store values that look like file paths or placeholders without asking")
+	setCmd.Flags().BoolVar(&setNoConfirm, "no-confirm", false, "When typing the value on a terminal, ask for it only once")
+	getCmd.Flags().StringVar(&getField, "field", "", "Print only the value of this key: value field")
+	getCmd.Flags().BoolVar(&getFieldList, "field-list", false, "List the field names in the secret without printing any values")
+	getCmd.Flags().BoolVar(&getFieldList, "fields", false, "Alias for --field-list")
+	_ = getCmd.Flags().MarkHidden("fields")
+	getCmd.Flags().DurationVar(&getCacheTTL, "cache-ttl", 0, "Reuse the decrypted value for this long (e.g. 30s); disabled by default")
+	getCmd.Flags().BoolVar(&allowDiskCache, "allow-disk-cache", false, "Allow --cache-ttl to use a cache directory that is not on tmpfs")
+	getCmd.Flags().BoolVar(&getQuietMiss, "quiet-missing", false, "Print nothing and exit 0 if the secret does not exist")
+	getCmd.Flags().StringVar(&getDefault, "default", "", "Print this value and exit 0 if the secret does not exist")
+	getCmd.Flags().BoolVar(&getMetadata, "metadata", false, "Show the secret's recipients, file size and git status instead of its value")
+	getCmd.Flags().BoolVar(&getAll, "all", false, "Print every secret in the vault as JSON keyed by secret path")
+	getCmd.Flags().BoolVar(&getStdin, "stdin", false, "Read secret names from stdin, one per line, and print name<TAB>value lines")
+	getCmd.Flags().BoolVar(&getStrict, "strict", false, "With --stdin, exit nonzero if any secret could not be read")
+	getCmd.Flags().BoolVarP(&getCopy, "copy", "c", false, "Copy the value to the clipboard instead of printing it")
+	getCmd.Flags().IntVar(&getClipTimeout, "clip-timeout", 0, "With --copy, clear the clipboard after this many seconds (0 keeps it)")
+	getCmd.Flags().BoolVar(&getRaw, "raw", false, "Print the exact decrypted bytes, without trimming or a trailing newline")
+	getCmd.Flags().BoolVar(&getBase64Decode, "base64-decode", false, "Decode the base64 value and print the resulting bytes")
+	getCmd.Flags().BoolVar(&useKeychain, "use-keychain", false, "Read the GPG passphrase from the system keychain (stores it after first use)")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	email := GetUserEmail()
-	vaultName := args[0]
+	vaultName, _, err := resolveVaultArg(secretsDir, args, len(args) == 1)
+	if err != nil {
+		return err
+	}
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Check vault exists
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
 	// Check access
-	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", vaultName)
+	if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	if listUnmask && !listShowValues {
+		return validationErrorf("--unmask requires --show-values")
+	}
+	if listCount && (listTree || listLong || listShowValues || listPrint0 || cmd.Flags().Changed("format") || cmd.Flags().Changed("delimiter")) {
+		return validationErrorf("--count cannot be combined with --format, --tree, --long, --show-values, --print0 or --delimiter")
+	}
+	if listEmpty && (listShowValues || listLong) {
+		return validationErrorf("--empty cannot be combined with --show-values or --long")
+	}
+	if listShowValues && (listTree || listLong || cmd.Flags().Changed("format")) {
+		return validationErrorf("--show-values cannot be combined with --format, --tree or --long")
+	}
+	if listPrint0 || cmd.Flags().Changed("delimiter") {
+		if listPrint0 && cmd.Flags().Changed("delimiter") {
+			return validationErrorf("--print0 and --delimiter cannot be combined")
+		}
+		if listTree || listLong || listShowValues || (cmd.Flags().Changed("format") && listFormat != "names") {
+			return validationErrorf("--print0 and --delimiter only apply to --format names")
+		}
+		if listPrint0 {
+			listDelimiter = "\x00"
+		}
+		listFormat = "names"
+	} else {
+		listDelimiter = "\n"
+	}
+	if listUnmask && !listForce {
+		return fmt.Errorf("--unmask prints every secret in %s in plain text. Use --force to confirm", vaultName)
 	}
 
 	// List secrets
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
-	secrets, err := p.List()
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	p := newPass(storeDir)
+	var secrets []string
+	switch listSort {
+	case "name":
+		secrets, err = p.List()
+	case "none":
+		secrets, err = p.ListUnsorted()
+	default:
+		return fmt.Errorf("unknown sort order: %s (use name or none)", listSort)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list secrets: %w", err)
 	}
 
+	if listEmpty {
+		if secrets, err = unfilledSecrets(p, secrets); err != nil {
+			return err
+		}
+	}
+	if listCount {
+		fmt.Println(len(secrets))
+		return nil
+	}
+
 	if len(secrets) == 0 {
-		fmt.Printf("No secrets in vault: %s\n", vaultName)
+		// Scripts reading delimited names must not get a message as a name
+		if listDelimiter == "\n" {
+			if listEmpty {
+				fmt.Printf("No empty secrets in vault: %s\n", vaultName)
+			} else {
+				fmt.Printf("No secrets in vault: %s\n", vaultName)
+			}
+		}
 		return nil
 	}
 
+	if listShowValues {
+		return printSecretValues(p, vaultName, secrets, listUnmask)
+	}
+
+	if listTree {
+		listFormat = "tree"
+	}
+	if listLong {
+		listFormat = "long"
+	}
+
 	switch listFormat {
+	case "long":
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config: %w", err)
+		}
+		expected := len(vaultRecipients(secretsDir, vaultCfg))
+		g := newGPG()
+
+		fmt.Printf("Secrets in vault '%s' (%d expected recipient(s)):\n", vaultName, expected)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, secret := range secrets {
+			// Subvaults have recipients of their own
+			expected := expected
+			if _, ok := vaultCfg.SubvaultFor(secret); ok {
+				expected = len(secretRecipients(secretsDir, vaultCfg, secret))
+			}
+			count, err := g.CountRecipients(filepath.Join(storeDir, secret+".gpg"))
+			switch {
+			case err != nil:
+				fmt.Fprintf(w, "  ✗ %s\tunreadable\n", secret)
+			case count == expected:
+				fmt.Fprintf(w, "  ✓ %s\t%d recipient(s)\n", secret, count)
+			default:
+				fmt.Fprintf(w, "  ✗ %s\t%d recipient(s)\n", secret, count)
+			}
+		}
+		w.Flush()
 	case "names":
+		w := bufio.NewWriter(os.Stdout)
 		for _, secret := range secrets {
-			fmt.Println(secret)
+			w.WriteString(secret + listDelimiter)
 		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	case "tree":
+		fmt.Printf("%s\n", vaultName)
+		printSecretTree(os.Stdout, secrets, "", 0)
 	default: // table
 		fmt.Printf("Secrets in vault '%s':\n", vaultName)
 		for _, secret := range secrets {
@@ -160,11 +487,85 @@ func runList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printSecretValues decrypts secrets in parallel and prints a table of
+// names and masked (or, with unmask, full) values
+func printSecretValues(p *pass.Pass, vaultName string, secrets []string, unmask bool) error {
+	values, err := p.ShowBatch(secrets)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Secrets in vault '%s':\n", vaultName)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, secret := range secrets {
+		value := maskValue(values[secret])
+		if unmask {
+			value = strings.ReplaceAll(values[secret], "\n", `\n`)
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", secret, value)
+	}
+	return w.Flush()
+}
+
+// unfilledSecrets decrypts secrets and returns those whose value is empty
+// or a placeholder, keeping their order
+func unfilledSecrets(p *pass.Pass, secrets []string) ([]string, error) {
+	if len(secrets) == 0 {
+		return nil, nil
+	}
+	values, err := p.ShowBatch(secrets)
+	if err != nil {
+		return nil, err
+	}
+	var unfilled []string
+	for _, secret := range secrets {
+		if isUnfilled(values[secret]) {
+			unfilled = append(unfilled, secret)
+		}
+	}
+	return unfilled, nil
+}
+
+// isUnfilled reports whether value is empty or a placeholder such as
+// CHANGEME, i.e. the secret still needs a real value
+func isUnfilled(value string) bool {
+	trimmed := strings.TrimSpace(value)
+	return trimmed == "" || placeholderValues[strings.ToLower(trimmed)]
+}
+
+// maskValue returns a preview of the first line of value showing only its
+// first and last two characters. Values of 4 characters or less are fully
+// masked. The mask has a fixed width so it does not reveal the length.
+func maskValue(value string) string {
+	line, _, _ := strings.Cut(value, "\n")
+	runes := []rune(line)
+	if len(runes) <= 4 {
+		return "****"
+	}
+	return string(runes[:2]) + "****" + string(runes[len(runes)-2:])
+}
+
 func runGet(cmd *cobra.Command, args []string) error {
+	if (getRaw || getBase64Decode) && (getAll || getStdin || getCopy || getField != "" || getFieldList || getCacheTTL > 0 || useKeychain || getMetadata) {
+		return validationErrorf("--raw and --base64-decode cannot be combined with --all, --stdin, --copy, --field, --field-list, --cache-ttl, --use-keychain or --metadata")
+	}
+	if getAll {
+		return runGetAll(args)
+	}
+	if getStdin {
+		return runGetStdin(args)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("requires a secret name (or --all)")
+	}
+
 	secretsDir := GetSecretsDir()
 	email := GetUserEmail()
-	vaultName := args[0]
-	secretName := args[1]
+	vaultName, rest, err := resolveVaultArg(secretsDir, args, len(args) == 2)
+	if err != nil {
+		return err
+	}
+	secretName := rest[0]
 
 	if err := validateName(vaultName); err != nil {
 		return err
@@ -172,44 +573,486 @@ func runGet(cmd *cobra.Command, args []string) error {
 	if err := validateSecretName(secretName); err != nil {
 		return err
 	}
+	if getCopy && getFieldList {
+		return fmt.Errorf("--copy cannot be combined with --field-list")
+	}
+	if getClipTimeout < 0 {
+		return fmt.Errorf("--clip-timeout must not be negative")
+	}
+	if getMetadata && (getCopy || getField != "" || getFieldList || getCacheTTL > 0 || useKeychain) {
+		return validationErrorf("--metadata cannot be combined with --copy, --field, --field-list, --cache-ttl or --use-keychain")
+	}
+	// The default is printed as is, so it cannot stand in for a field, raw
+	// bytes or a clipboard copy
+	if cmd.Flags().Changed("default") && (getCopy || getField != "" || getRaw || getBase64Decode) {
+		return validationErrorf("--default cannot be combined with --copy, --field, --raw or --base64-decode")
+	}
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Check vault exists
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
 	// Check access
-	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", vaultName)
+	if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+		return err
 	}
 
 	// Get secret
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	p := newPass(storeDir)
 
 	if !p.Exists(secretName) {
-		return fmt.Errorf("secret not found: %s/%s", vaultName, secretName)
+		if cmd.Flags().Changed("default") {
+			fmt.Println(getDefault)
+			return nil
+		}
+		if getQuietMiss {
+			return nil
+		}
+		return notFoundErrorf("secret not found: %s/%s", vaultName, secretName)
 	}
 
-	value, err := p.Show(secretName)
+	if getMetadata {
+		return printSecretMetadata(secretsDir, vaultName, secretName, email)
+	}
+
+	if getRaw || getBase64Decode {
+		data, err := p.ShowRaw(secretName)
+		if err != nil {
+			return fmt.Errorf("failed to get secret: %w%s", err, decryptFailureHint(secretsDir, storeDir, vaultName, secretName, email))
+		}
+		if getBase64Decode {
+			if data, err = decodeBase64Secret(data); err != nil {
+				return fmt.Errorf("%s/%s: %w", vaultName, secretName, err)
+			}
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	var value string
+	var c *cache.Cache
+	var cacheKey string
+	cached := false
+	if getCacheTTL > 0 {
+		if c, err = cache.Open(cache.Dir(), allowDiskCache); err != nil {
+			return err
+		}
+		if cacheKey, err = secretCacheKey(storeDir, vaultName, secretName, email); err != nil {
+			return err
+		}
+		value, cached = c.Get(cacheKey, getCacheTTL)
+	}
+
+	if !cached {
+		if useKeychain {
+			value, err = showWithKeychain(p, secretName, email)
+			if err != nil {
+				return err
+			}
+		} else {
+			value, err = p.Show(secretName)
+			if err != nil {
+				return fmt.Errorf("failed to get secret: %w%s", err, decryptFailureHint(secretsDir, storeDir, vaultName, secretName, email))
+			}
+		}
+
+		if c != nil {
+			if err := c.Put(cacheKey, value); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to cache value: %v\n", err)
+			}
+		}
+	}
+
+	if getField != "" || getFieldList {
+		password, fields := pass.ParseFields(value)
+
+		// The first line has no key; it is addressed as "password"
+		var keys []string
+		if password != "" {
+			keys = append(keys, "password")
+		}
+		for _, f := range fields {
+			keys = append(keys, f.Key)
+		}
+
+		if getFieldList {
+			for _, k := range keys {
+				fmt.Println(k)
+			}
+			return nil
+		}
+
+		for _, f := range fields {
+			if f.Key == getField {
+				return outputSecret(f.Value, vaultName+"/"+secretName)
+			}
+		}
+		if getField == "password" && password != "" {
+			return outputSecret(password, vaultName+"/"+secretName)
+		}
+		return notFoundErrorf("field %q not found in %s/%s (available: %s)", getField, vaultName, secretName, strings.Join(keys, ", "))
+	}
+
+	return outputSecret(value, vaultName+"/"+secretName)
+}
+
+// decodeBase64Secret decodes a secret stored as base64, ignoring the line
+// breaks and surrounding whitespace that wrapped base64 text contains
+func decodeBase64Secret(data []byte) ([]byte, error) {
+	text := strings.Join(strings.Fields(string(data)), "")
+	decoded, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("value is not valid base64: %w", err)
+	}
+	return decoded, nil
+}
+
+// decryptFailureHint explains a failed decryption by naming the keys the
+// secret is encrypted for and whether the user's key is one of them. It
+// returns an empty string when the recipients cannot be read.
+func decryptFailureHint(secretsDir, storeDir, vaultName, secretName, email string) string {
+	g := newGPG()
+	ids, err := g.RecipientKeyIDs(filepath.Join(storeDir, secretName+".gpg"))
+	if err != nil || len(ids) == 0 {
+		return ""
+	}
+
+	return recipientHint(vaultName, email, resolveRecipients(ids, storedKeys(g, secretsDir)))
+}
+
+// storedKeys reads the public keys in keys/ without importing them, each
+// named by the email its file is stored under
+func storedKeys(g *gpg.GPG, secretsDir string) []gpg.Key {
+	var keys []gpg.Key
+	keysDir := config.GetKeysDir(secretsDir)
+	entries, err := os.ReadDir(keysDir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".asc" {
+			continue
+		}
+		if found, err := g.ShowKeyFile(filepath.Join(keysDir, entry.Name())); err == nil {
+			for _, k := range found {
+				k.Email = strings.TrimSuffix(entry.Name(), ".asc")
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
+
+// resolveRecipients names each recipient key ID by the email of the stored
+// key it belongs to
+func resolveRecipients(ids []string, keys []gpg.Key) []string {
+	var names []string
+	for _, id := range ids {
+		name := "unknown key " + id
+		if strings.Trim(id, "0") == "" {
+			name = "hidden recipient"
+		}
+		for _, k := range keys {
+			if k.HasKeyID(id) {
+				name = k.Email
+				break
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// recipientHint tells the user whether their email is among the recipients
+// of a secret they failed to decrypt, and what to do about it
+func recipientHint(vaultName, email string, recipients []string) string {
+	hint := "\n  This secret is encrypted for: " + strings.Join(recipients, ", ")
+	for _, r := range recipients {
+		if email != "" && strings.EqualFold(r, email) {
+			return hint + "\n  Your key is among them; check that its secret key is in your keyring (gpg --list-secret-keys)"
+		}
+	}
+	if email == "" {
+		return hint + "\n  Set --email to check whether your key is among them"
+	}
+	return hint + fmt.Sprintf("\n  Your key (%s) is not among them. Ask a member of %s to add you to the vault, or to run 'secrets-cli sync %s' if you already are a member", email, vaultName, vaultName)
+}
+
+// runGetAll prints all secrets of a vault as a JSON object keyed by their
+// raw secret path
+func runGetAll(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("--all takes at most one argument, the vault")
+	}
+	if getField != "" || getFieldList || getCopy || getCacheTTL > 0 || useKeychain {
+		return fmt.Errorf("--all cannot be combined with --field, --field-list, --copy, --cache-ttl or --use-keychain")
+	}
+
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName, _, err := resolveVaultArg(secretsDir, args, len(args) == 1)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	// Check vault exists
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+
+	// Check access
+	if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	p := newPass(config.GetStoreDir(secretsDir, vaultName))
+	secrets, err := p.List()
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	values, err := p.ShowBatch(secrets)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// runGetStdin implements get --stdin, printing name<TAB>value for each
+// secret name read from stdin
+func runGetStdin(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("--stdin takes at most one argument, the vault")
+	}
+	if getAll || getField != "" || getFieldList || getCopy || getCacheTTL > 0 || useKeychain {
+		return fmt.Errorf("--stdin cannot be combined with --all, --field, --field-list, --copy, --cache-ttl or --use-keychain")
+	}
+
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName, _, err := resolveVaultArg(secretsDir, args, len(args) == 1)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	// Check vault exists
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+
+	// Check access
+	if err := checkReadAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	names, err := readSecretNames(os.Stdin)
 	if err != nil {
-		return fmt.Errorf("failed to get secret: %w", err)
+		return err
+	}
+	if len(names) == 0 {
+		return validationErrorf("no secret names on stdin")
 	}
 
-	fmt.Println(value)
+	p := newPass(config.GetStoreDir(secretsDir, vaultName))
+	failed := 0
+	var found []string
+	for _, name := range names {
+		if err := validateSecretName(name); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			failed++
+			continue
+		}
+		if !p.Exists(name) {
+			fmt.Fprintf(os.Stderr, "✗ secret not found: %s/%s\n", vaultName, name)
+			failed++
+			continue
+		}
+		found = append(found, name)
+	}
+
+	values, errs := p.ShowEach(found)
+	for _, name := range found {
+		if err, ok := errs[name]; ok {
+			fmt.Fprintf(os.Stderr, "✗ failed to decrypt %s/%s: %v\n", vaultName, name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s\t%s\n", name, values[name])
+	}
+
+	if failed == len(names) || (getStrict && failed > 0) {
+		return fmt.Errorf("%d of %d secret(s) could not be read", failed, len(names))
+	}
 	return nil
 }
 
+// readSecretNames reads one secret name per line, skipping blank lines and
+// repeated names
+func readSecretNames(r io.Reader) ([]string, error) {
+	var names []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read secret names: %w", err)
+	}
+	return names, nil
+}
+
+// outputSecret prints a secret value, or with --copy places it on the
+// clipboard without ever printing it
+func outputSecret(value, label string) error {
+	if !getCopy {
+		fmt.Println(value)
+		return nil
+	}
+
+	if err := clipboard.Copy(value); err != nil {
+		return fmt.Errorf("failed to copy %s to the clipboard: %w", label, err)
+	}
+	if getClipTimeout > 0 {
+		if err := clipboard.ClearAfter(time.Duration(getClipTimeout) * time.Second); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			fmt.Printf("✓ Copied %s to the clipboard; it will be cleared in %d seconds\n", label, getClipTimeout)
+			return nil
+		}
+	}
+	fmt.Printf("✓ Copied %s to the clipboard\n", label)
+	return nil
+}
+
+// showWithKeychain decrypts a secret using the GPG passphrase cached in the
+// system keychain. If no passphrase is cached, it prompts for one and stores
+// it only after it decrypted the secret and unlocked the secret key in a
+// fresh gpg-agent.
+func showWithKeychain(p *pass.Pass, secretName, email string) (string, error) {
+	if email == "" {
+		return "", fmt.Errorf("email is required for --use-keychain. Use --email flag or set USER_EMAIL environment variable")
+	}
+
+	passphrase, err := keychain.Get(email)
+	cached := err == nil
+	if !cached {
+		if !errors.Is(err, keychain.ErrNotFound) {
+			return "", err
+		}
+		passphrase, err = promptPassphrase(email)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	p.Passphrase = passphrase
+	value, err := p.Show(secretName)
+	if err != nil {
+		if cached {
+			return "", fmt.Errorf("failed to get secret with keychain passphrase (it may be stale; remove it from your keychain and retry): %w", err)
+		}
+		return "", fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	// A decryption proves little: gpg-agent ignores the passphrase for a
+	// key it already unlocked, the gpg fallback without pass and --cache
+	// may not use it at all. Check it against the key itself first.
+	if !cached {
+		if err := newGPG().CheckPassphrase(email, passphrase); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: not storing the passphrase in the keychain: %v\n", err)
+		} else if err := keychain.Set(email, passphrase); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to store passphrase in keychain: %v\n", err)
+		}
+	}
+
+	return value, nil
+}
+
+// promptValue reads a secret value from the terminal without echo. With
+// retype it is asked for twice, like passwd, and must match.
+func promptValue(retype bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	fmt.Fprint(os.Stderr, "Value: ")
+	first, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read value: %w", err)
+	}
+	if !retype {
+		return string(first), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Retype value: ")
+	second, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read value: %w", err)
+	}
+	if string(first) != string(second) {
+		return "", validationErrorf("values do not match, nothing was stored")
+	}
+	return string(first), nil
+}
+
+// promptPassphrase reads a GPG passphrase from the terminal without echo
+func promptPassphrase(email string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no passphrase for %s in keychain and stdin is not a terminal", email)
+	}
+
+	fmt.Fprintf(os.Stderr, "GPG passphrase for %s: ", email)
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	return string(data), nil
+}
+
 func runSet(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	email := GetUserEmail()
-	vaultName := args[0]
-	secretName := args[1]
+	// Two arguments are always "set <vault> <secret>" with the value read
+	// from stdin or a prompt: taking them as "set <secret> <value>" in the
+	// default vault would turn a mistyped vault into a new secret
+	if len(args) == 2 && !config.VaultExists(secretsDir, args[0]) {
+		return notFoundErrorf("vault not found: %s. To set a secret in the default vault, give only its name and pipe or type the value: secrets-cli set <secret>", args[0])
+	}
+	vaultName, rest, err := resolveVaultArg(secretsDir, args, len(args) >= 2)
+	if err != nil {
+		return err
+	}
+	secretName := rest[0]
 
 	if err := validateName(vaultName); err != nil {
 		return err
@@ -219,24 +1062,66 @@ func runSet(cmd *cobra.Command, args []string) error {
 	}
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Check vault exists
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
 	// Check access
-	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", vaultName)
+	if err := checkWriteAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+	if err := checkNotArchived(secretsDir, vaultName); err != nil {
+		return err
+	}
+
+	// Reject namespace collisions before prompting for a value
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	p := newPass(storeDir)
+	if err := p.CheckShadow(secretName); err != nil {
+		return err
+	}
+
+	if setField != "" {
+		key, fieldValue, ok := strings.Cut(setField, "=")
+		if !ok || key == "" {
+			return validationErrorf("--field expects key=value, got %q", setField)
+		}
+		if setGenerate || len(rest) > 1 {
+			return validationErrorf("--field cannot be combined with a value argument or --generate")
+		}
+		return config.WithVaultLock(vaultDir, func() error {
+			if err := p.InsertField(secretName, key, fieldValue); err != nil {
+				return fmt.Errorf("failed to set field: %w", err)
+			}
+			fmt.Printf("✓ Set field %s of secret: %s/%s\n", key, vaultName, secretName)
+			recordChange("set field %s of %s/%s", key, vaultName, secretName)
+			return nil
+		})
 	}
 
 	// Get value
 	var value string
-	if len(args) > 2 {
-		value = args[2]
+	interactive := len(rest) == 1 && term.IsTerminal(int(os.Stdin.Fd()))
+	if setGenerate {
+		if len(rest) > 1 {
+			return fmt.Errorf("cannot use a value argument with --generate")
+		}
+		generated, err := secretgen.Generate(setPolicy)
+		if err != nil {
+			return err
+		}
+		value = generated
+	} else if len(rest) > 1 {
+		value = rest[1]
+	} else if interactive {
+		if value, err = promptValue(!setNoConfirm); err != nil {
+			return err
+		}
 	} else {
 		// Read from stdin
 		reader := bufio.NewReader(os.Stdin)
@@ -255,53 +1140,192 @@ func runSet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("empty secret value not allowed")
 	}
 
-	// Set secret
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
+	if !setGenerate && !setForce {
+		typed := len(rest) > 1 || interactive
+		if reason := suspiciousValue(value, typed); reason != "" {
+			if !typed || !confirm(fmt.Sprintf("⚠ %s. Store it anyway?", reason)) {
+				return validationErrorf("%s. Use --force to store it anyway", reason)
+			}
+		}
+	}
+
+	return config.WithVaultLock(vaultDir, func() error {
+		// Set secret
+		if err := p.Insert(secretName, value); err != nil {
+			return fmt.Errorf("failed to set secret: %w", err)
+		}
+
+		fmt.Printf("✓ Set secret: %s/%s\n", vaultName, secretName)
+		recordChange("set %s/%s", vaultName, secretName)
+		if setGenerate {
+			if setShow {
+				fmt.Println(value)
+			} else {
+				fmt.Printf("  Generated with policy %s. Use 'secrets-cli get %s %s' to view it\n", setPolicy, vaultName, secretName)
+			}
+		}
+		return nil
+	})
+}
+
+// placeholderValues are values that are almost never a real secret
+var placeholderValues = map[string]bool{
+	"changeme": true, "change-me": true, "change_me": true, "todo": true,
+	"fixme": true, "tbd": true, "placeholder": true, "xxx": true,
+	"password": true, "secret": true,
+}
 
-	if err := p.Insert(secretName, value); err != nil {
-		return fmt.Errorf("failed to set secret: %w", err)
+// suspiciousValue explains why value looks like a mistake, or returns "" if
+// it does not. Only inline values are checked for being a file path, since
+// values read from stdin are file contents already.
+func suspiciousValue(value string, inline bool) string {
+	trimmed := strings.TrimSpace(value)
+	if placeholderValues[strings.ToLower(trimmed)] ||
+		(strings.HasPrefix(trimmed, "<") && strings.HasSuffix(trimmed, ">")) {
+		return fmt.Sprintf("the value %q looks like a placeholder", trimmed)
+	}
+	if inline {
+		if info, err := os.Stat(value); err == nil && info.Mode().IsRegular() {
+			return fmt.Sprintf("the value %q is the path of an existing file (to store its contents, use: set ... < %s)", value, value)
+		}
 	}
+	return ""
+}
 
-	fmt.Printf("✓ Set secret: %s/%s\n", vaultName, secretName)
-	return nil
+// confirm asks a yes/no question on the terminal. It returns false when
+// stdin is not a terminal.
+func confirm(question string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	email := GetUserEmail()
-	vaultName := args[0]
-	secretName := args[1]
+	vaultName, rest, err := resolveVaultArg(secretsDir, args, len(args) == 2)
+	if err != nil {
+		return err
+	}
+	secretName := rest[0]
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Check vault exists
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
 	// Check access
-	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", vaultName)
+	if err := checkWriteAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+	if err := checkNotArchived(secretsDir, vaultName); err != nil {
+		return err
+	}
+
+	if deleteAll && !deleteRecurse {
+		return validationErrorf("--all requires --recursive")
+	}
+	if deleteRecurse {
+		return deleteMatching(vaultDir, config.GetStoreDir(secretsDir, vaultName), vaultName, secretName)
 	}
 
 	if !forceSecret {
 		return fmt.Errorf("use --force to confirm deletion of secret: %s/%s", vaultName, secretName)
 	}
 
-	// Delete secret
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
+	return config.WithVaultLock(vaultDir, func() error {
+		// Delete secret
+		storeDir := config.GetStoreDir(secretsDir, vaultName)
+		p := newPass(storeDir)
+
+		if err := p.Remove(secretName); err != nil {
+			return fmt.Errorf("failed to delete secret: %w", err)
+		}
 
-	if err := p.Remove(secretName); err != nil {
-		return fmt.Errorf("failed to delete secret: %w", err)
+		fmt.Printf("✓ Deleted secret: %s/%s\n", vaultName, secretName)
+		recordChange("delete %s/%s", vaultName, secretName)
+		return nil
+	})
+}
+
+// deleteMatching deletes every secret in the store matching pattern, see
+// matchesOrUnder, after listing them and asking once unless --force is set
+func deleteMatching(vaultDir, storeDir, vaultName, pattern string) error {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if _, err := path.Match(pattern, ""); err != nil {
+		return validationErrorf("invalid glob %q: %v", pattern, err)
 	}
 
-	fmt.Printf("✓ Deleted secret: %s/%s\n", vaultName, secretName)
-	return nil
+	p := newPass(storeDir)
+	secrets, err := p.List()
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	var matches []string
+	for _, secret := range secrets {
+		if matchesOrUnder(pattern, secret) {
+			matches = append(matches, secret)
+		}
+	}
+	if len(matches) == 0 {
+		return notFoundErrorf("no secrets in %s match %s", vaultName, pattern)
+	}
+	if len(matches) == len(secrets) && !deleteAll {
+		return validationErrorf("%s matches all %d secret(s) in %s. Add --all to delete them all", pattern, len(secrets), vaultName)
+	}
+
+	fmt.Printf("Secrets matching %s in vault '%s':\n", pattern, vaultName)
+	for _, secret := range matches {
+		fmt.Printf("  %s\n", secret)
+	}
+	if !forceSecret && !confirm(fmt.Sprintf("Permanently delete these %d secret(s)?", len(matches))) {
+		return fmt.Errorf("nothing deleted. Use --force to delete the %d secret(s) without asking", len(matches))
+	}
+
+	return config.WithVaultLock(vaultDir, func() error {
+		var failed []string
+		for _, secret := range matches {
+			if err := p.Remove(secret); err != nil {
+				fmt.Printf("✗ Failed to delete %s/%s: %v\n", vaultName, secret, err)
+				failed = append(failed, secret)
+			}
+		}
+
+		deleted := len(matches) - len(failed)
+		if deleted > 0 {
+			fmt.Printf("✓ Deleted %d secret(s) from %s\n", deleted, vaultName)
+			recordChange("delete %d secret(s) matching %s from %s", deleted, pattern, vaultName)
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%d secret(s) could not be deleted: %s", len(failed), strings.Join(failed, ", "))
+		}
+		return nil
+	})
+}
+
+// matchesOrUnder reports whether secret matches pattern, or lies inside a
+// directory that does, like rm -r on the expanded glob
+func matchesOrUnder(pattern, secret string) bool {
+	name := secret
+	for {
+		if matchSecretGlob(pattern, name) {
+			return true
+		}
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return false
+		}
+		name = name[:i]
+	}
 }
 
 func runRename(cmd *cobra.Command, args []string) error {
@@ -312,26 +1336,33 @@ func runRename(cmd *cobra.Command, args []string) error {
 	newName := args[2]
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Check vault exists
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
 	// Check access
-	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", vaultName)
+	if err := checkWriteAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+	if err := checkNotArchived(secretsDir, vaultName); err != nil {
+		return err
 	}
 
 	// Rename secret
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	p := newPass(storeDir)
+
+	if renameRecurse || strings.HasSuffix(oldName, "/") {
+		return moveSubtree(p, vaultDir, vaultName, strings.TrimSuffix(oldName, "/"), strings.TrimSuffix(newName, "/"))
+	}
 
 	if !p.Exists(oldName) {
-		return fmt.Errorf("secret not found: %s/%s", vaultName, oldName)
+		return notFoundErrorf("secret not found: %s/%s", vaultName, oldName)
 	}
 
 	if err := p.Move(oldName, newName); err != nil {
@@ -339,6 +1370,7 @@ func runRename(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✓ Renamed secret: %s/%s -> %s/%s\n", vaultName, oldName, vaultName, newName)
+	recordChange("rename %s/%s to %s", vaultName, oldName, newName)
 	return nil
 }
 
@@ -350,35 +1382,46 @@ func runCopy(cmd *cobra.Command, args []string) error {
 	dstVault := args[2]
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Check source vault exists and access
 	srcVaultDir := config.GetVaultDir(secretsDir, srcVault)
 	if _, err := os.Stat(srcVaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("source vault not found: %s", srcVault)
+		return notFoundErrorf("source vault not found: %s", srcVault)
 	}
 
 	if !hasVaultAccess(secretsDir, srcVault, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", srcVault)
+		return accessDeniedErrorf("Access denied: you are not a member of vault %s", srcVault)
 	}
 
 	// Check destination vault exists and access
 	dstVaultDir := config.GetVaultDir(secretsDir, dstVault)
 	if _, err := os.Stat(dstVaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("destination vault not found: %s", dstVault)
+		return notFoundErrorf("destination vault not found: %s", dstVault)
 	}
 
-	if !hasVaultAccess(secretsDir, dstVault, email) && email != "" {
-		return fmt.Errorf("Access denied: you are not a member of vault %s", dstVault)
+	if err := checkWriteAccess(secretsDir, dstVault, email); err != nil {
+		return err
+	}
+	if err := checkNotArchived(secretsDir, dstVault); err != nil {
+		return err
 	}
 
 	// Get source secret
-	srcStoreDir := filepath.Join(srcVaultDir, ".password-store")
-	srcPass := pass.New(srcStoreDir)
+	srcStoreDir := config.GetStoreDir(secretsDir, srcVault)
+	srcPass := newPass(srcStoreDir)
+	dstPass := newPass(config.GetStoreDir(secretsDir, dstVault))
+
+	if copyRecursive || strings.HasSuffix(secretName, "/") {
+		return copySubtree(srcPass, dstPass, srcVault, strings.TrimSuffix(secretName, "/"), dstVaultDir, dstVault)
+	}
+	if copyDstPrefix != "" {
+		return fmt.Errorf("--dst-prefix only applies to recursive copies; use --new-name for a single secret")
+	}
 
 	if !srcPass.Exists(secretName) {
-		return fmt.Errorf("secret not found: %s/%s", srcVault, secretName)
+		return notFoundErrorf("secret not found: %s/%s", srcVault, secretName)
 	}
 
 	value, err := srcPass.Show(secretName)
@@ -387,9 +1430,6 @@ func runCopy(cmd *cobra.Command, args []string) error {
 	}
 
 	// Set in destination
-	dstStoreDir := filepath.Join(dstVaultDir, ".password-store")
-	dstPass := pass.New(dstStoreDir)
-
 	dstSecretName := secretName
 	if newSecretName != "" {
 		dstSecretName = newSecretName
@@ -400,5 +1440,123 @@ func runCopy(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✓ Copied secret: %s/%s -> %s/%s\n", srcVault, secretName, dstVault, dstSecretName)
+	recordChange("copy %s/%s to %s/%s", srcVault, secretName, dstVault, dstSecretName)
 	return nil
 }
+
+// moveSubtree moves every secret under prefix to the same relative path
+// under newPrefix within one vault
+func moveSubtree(p *pass.Pass, vaultDir, vaultName, prefix, newPrefix string) error {
+	for _, name := range []string{prefix, newPrefix} {
+		if err := validateSecretName(name); err != nil {
+			return err
+		}
+	}
+	if prefix == newPrefix {
+		return validationErrorf("cannot move %s/ onto itself", prefix)
+	}
+	if strings.HasPrefix(newPrefix, prefix+"/") {
+		return validationErrorf("cannot move %s/ into its own subtree %s/", prefix, newPrefix)
+	}
+
+	return config.WithVaultLock(vaultDir, func() error {
+		secrets, err := p.List()
+		if err != nil {
+			return fmt.Errorf("failed to list secrets: %w", err)
+		}
+		moves := subtreeCopies(secrets, prefix, newPrefix)
+		if len(moves) == 0 {
+			return notFoundErrorf("no secrets found under %s/%s/", vaultName, prefix)
+		}
+
+		var existing []string
+		for _, m := range moves {
+			if p.Exists(m.dst) {
+				existing = append(existing, m.dst)
+			}
+		}
+		if len(existing) > 0 {
+			return fmt.Errorf("nothing was moved: %d destination secret(s) already exist: %s", len(existing), strings.Join(existing, ", "))
+		}
+
+		for i, m := range moves {
+			if err := p.Move(m.src, m.dst); err != nil {
+				return fmt.Errorf("failed to move %s/%s after moving %d secret(s): %w", vaultName, m.src, i, err)
+			}
+			if IsVerbose() {
+				fmt.Printf("  %s/%s -> %s/%s\n", vaultName, m.src, vaultName, m.dst)
+			}
+		}
+
+		fmt.Printf("✓ Moved %d secret(s): %s/%s/ -> %s/%s/\n", len(moves), vaultName, prefix, vaultName, newPrefix)
+		recordChange("rename %s/%s/ to %s/", vaultName, prefix, newPrefix)
+		return nil
+	})
+}
+
+// copySubtree copies every secret under prefix from srcPass into dstPass,
+// the store of dstVault
+func copySubtree(srcPass, dstPass *pass.Pass, srcVault, prefix, dstVaultDir, dstVault string) error {
+	if newSecretName != "" {
+		return fmt.Errorf("--new-name cannot be used when copying a directory; use --dst-prefix")
+	}
+	if err := validateSecretName(prefix); err != nil {
+		return err
+	}
+	dstPrefix := strings.TrimSuffix(copyDstPrefix, "/")
+	if dstPrefix == "" {
+		dstPrefix = prefix
+	}
+	if err := validateSecretName(dstPrefix); err != nil {
+		return err
+	}
+
+	secrets, err := srcPass.List()
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	copies := subtreeCopies(secrets, prefix, dstPrefix)
+	if len(copies) == 0 {
+		return fmt.Errorf("no secrets found under %s/%s/", srcVault, prefix)
+	}
+
+	return config.WithVaultLock(dstVaultDir, func() error {
+		for _, c := range copies {
+			value, err := srcPass.Show(c.src)
+			if err != nil {
+				return fmt.Errorf("failed to read %s/%s: %w", srcVault, c.src, err)
+			}
+			if err := dstPass.Insert(c.dst, value); err != nil {
+				return fmt.Errorf("failed to copy %s/%s: %w", srcVault, c.src, err)
+			}
+			if IsVerbose() {
+				fmt.Printf("  %s/%s -> %s/%s\n", srcVault, c.src, dstVault, c.dst)
+			}
+		}
+
+		fmt.Printf("✓ Copied %d secret(s): %s/%s/ -> %s/%s/\n", len(copies), srcVault, prefix, dstVault, dstPrefix)
+		recordChange("copy %s/%s/ to %s/%s/", srcVault, prefix, dstVault, dstPrefix)
+		return nil
+	})
+}
+
+// secretCopy is one source/destination pair in a subtree copy
+type secretCopy struct {
+	src string
+	dst string
+}
+
+// subtreeCopies maps every secret under prefix to the same relative path
+// under dstPrefix, sorted by source name
+func subtreeCopies(secrets []string, prefix, dstPrefix string) []secretCopy {
+	var copies []secretCopy
+	for _, secret := range secrets {
+		rel, ok := strings.CutPrefix(secret, prefix+"/")
+		if !ok {
+			continue
+		}
+		copies = append(copies, secretCopy{src: secret, dst: dstPrefix + "/" + rel})
+	}
+	sort.Slice(copies, func(i, j int) bool { return copies[i].src < copies[j].src })
+	return copies
+}
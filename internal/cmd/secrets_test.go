@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
+	"github.com/spf13/cobra"
+)
+
+func TestSubtreeCopies(t *testing.T) {
+	secrets := []string{"db/password", "api/key", "db/replica/user", "dbx/other", "db"}
+
+	got := subtreeCopies(secrets, "db", "db")
+	want := []secretCopy{
+		{src: "db/password", dst: "db/password"},
+		{src: "db/replica/user", dst: "db/replica/user"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("subtreeCopies(same prefix) = %v, want %v", got, want)
+	}
+
+	got = subtreeCopies(secrets, "db", "legacy/db")
+	want = []secretCopy{
+		{src: "db/password", dst: "legacy/db/password"},
+		{src: "db/replica/user", dst: "legacy/db/replica/user"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("subtreeCopies(dst prefix) = %v, want %v", got, want)
+	}
+
+	if got := subtreeCopies(secrets, "missing", "missing"); len(got) != 0 {
+		t.Errorf("subtreeCopies(missing) = %v, want none", got)
+	}
+}
+
+func TestResolveRecipients(t *testing.T) {
+	keys := []gpg.Key{
+		{KeyID: "1092A3C3F9339B23", SubkeyIDs: []string{"5518626961FE31D9"}, Email: "alice@example.com"},
+		{KeyID: "AAAABBBBCCCCDDDD", Email: "bob@example.com"},
+	}
+	ids := []string{"5518626961fe31d9", "AAAABBBBCCCCDDDD", "0123456789ABCDEF", "0000000000000000"}
+
+	got := resolveRecipients(ids, keys)
+	want := []string{"alice@example.com", "bob@example.com", "unknown key 0123456789ABCDEF", "hidden recipient"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveRecipients() = %v, want %v", got, want)
+	}
+}
+
+func TestRecipientHint(t *testing.T) {
+	recipients := []string{"alice@example.com", "bob@example.com"}
+
+	if hint := recipientHint("dev", "carol@example.com", recipients); !strings.Contains(hint, "is not among them") || !strings.Contains(hint, "sync dev") {
+		t.Errorf("hint for a non-recipient = %q", hint)
+	}
+	if hint := recipientHint("dev", "Bob@example.com", recipients); !strings.Contains(hint, "Your key is among them") {
+		t.Errorf("hint for a recipient = %q", hint)
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", "****"},
+		{"abcd", "****"},
+		{"abcde", "ab****de"},
+		{"s3cret-value", "s3****ue"},
+		{"héllo wörld", "hé****ld"},
+		{"password\nuser: admin", "pa****rd"},
+	}
+	for _, tt := range tests {
+		if got := maskValue(tt.value); got != tt.want {
+			t.Errorf("maskValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsUnfilled(t *testing.T) {
+	for value, want := range map[string]bool{
+		"":            true,
+		" \n":         true,
+		"CHANGEME":    true,
+		"todo\n":      true,
+		"s3cret":      false,
+		"changeme-42": false,
+	} {
+		if got := isUnfilled(value); got != want {
+			t.Errorf("isUnfilled(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestMatchesOrUnder(t *testing.T) {
+	tests := []struct {
+		pattern, secret string
+		want            bool
+	}{
+		{"temp/*", "temp/a", true},
+		{"temp/*", "temp/x/y", true},
+		{"temp", "temp/x/y", true},
+		{"temp", "temporary/x", false},
+		{"*", "db/password", true},
+		{"db/pass*", "db/password", true},
+		{"db/pass*", "api/password", false},
+	}
+	for _, tt := range tests {
+		if got := matchesOrUnder(tt.pattern, tt.secret); got != tt.want {
+			t.Errorf("matchesOrUnder(%q, %q) = %v, want %v", tt.pattern, tt.secret, got, tt.want)
+		}
+	}
+}
+
+func TestSuspiciousValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password.txt")
+	if err := os.WriteFile(path, []byte("s3cret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value      string
+		inline     bool
+		suspicious bool
+	}{
+		{"s3cret-value", true, false},
+		{"changeme", true, true},
+		{" TODO ", false, true},
+		{"<your-api-key>", true, true},
+		{path, true, true},
+		{path, false, false}, // stdin input is exempt from the path check
+		{filepath.Dir(path), true, false},
+	}
+	for _, tt := range tests {
+		if got := suspiciousValue(tt.value, tt.inline) != ""; got != tt.suspicious {
+			t.Errorf("suspiciousValue(%q, %v) suspicious = %v, want %v", tt.value, tt.inline, got, tt.suspicious)
+		}
+	}
+}
+
+func TestReadSecretNames(t *testing.T) {
+	got, err := readSecretNames(strings.NewReader("db/user\n\n  db/password \r\ndb/user\napi/key"))
+	if err != nil {
+		t.Fatalf("readSecretNames() error = %v", err)
+	}
+	want := []string{"db/user", "db/password", "api/key"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readSecretNames() = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeBase64Secret(t *testing.T) {
+	want := []byte{0x00, 0xff, '\n', ' ', 0x10}
+	encoded := base64.StdEncoding.EncodeToString(want)
+	wrapped := encoded[:4] + "\n" + encoded[4:] + "\n"
+	for _, input := range []string{encoded, wrapped, "  " + encoded + "\r\n"} {
+		got, err := decodeBase64Secret([]byte(input))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("decodeBase64Secret(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := decodeBase64Secret([]byte("not base64!")); err == nil {
+		t.Error("decodeBase64Secret() of invalid input succeeded")
+	}
+}
+
+func TestRunSetUnknownVault(t *testing.T) {
+	secretsDir := t.TempDir()
+	t.Setenv("SECRETS_DIR", secretsDir)
+	t.Setenv("USER_EMAIL", "alice@example.com")
+	if err := config.SaveConfig(secretsDir, &config.Config{DefaultVault: "dev"}); err != nil {
+		t.Fatal(err)
+	}
+	vaultDir := config.GetVaultDir(secretsDir, "dev")
+	if err := os.MkdirAll(config.GetStoreDir(secretsDir, "dev"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveVaultConfig(vaultDir, &config.VaultConfig{Name: "dev", Members: []string{"alice@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A mistyped vault must not become a secret in the default vault
+	err := runSet(setCmd, []string{"prodd", "API_KEY"})
+	if ExitCode(err) != ExitNotFound || !strings.Contains(err.Error(), "vault not found: prodd") {
+		t.Fatalf("runSet() err = %v, want vault not found", err)
+	}
+	if _, err := os.Stat(filepath.Join(config.GetStoreDir(secretsDir, "dev"), "prodd.gpg")); !os.IsNotExist(err) {
+		t.Errorf("prodd was stored in the default vault: %v", err)
+	}
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fnErr := fn()
+	w.Close()
+	return string(<-done), fnErr
+}
+
+// setFlag sets a command flag for the rest of the test
+func setFlag(t *testing.T, cmd *cobra.Command, name, value string) {
+	t.Helper()
+	flag := cmd.Flags().Lookup(name)
+	old := flag.Value.String()
+	if err := flag.Value.Set(value); err != nil {
+		t.Fatal(err)
+	}
+	flag.Changed = true
+	t.Cleanup(func() {
+		flag.Value.Set(old)
+		flag.Changed = false
+	})
+}
+
+func TestRunGetMissing(t *testing.T) {
+	t.Setenv("GNUPGHOME", t.TempDir())
+	secretsDir := t.TempDir()
+	t.Setenv("SECRETS_DIR", secretsDir)
+	t.Setenv("USER_EMAIL", "alice@example.com")
+	if err := config.SaveConfig(secretsDir, &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	storeDir := config.GetStoreDir(secretsDir, "dev")
+	if err := os.MkdirAll(storeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveVaultConfig(config.GetVaultDir(secretsDir, "dev"), &config.VaultConfig{Name: "dev", Members: []string{"alice@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	// A secret that exists but cannot be decrypted
+	if err := os.WriteFile(filepath.Join(storeDir, "broken.gpg"), []byte("not encrypted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runGet(getCmd, []string{"dev", "missing"}); ExitCode(err) != ExitNotFound {
+		t.Errorf("missing without flags: err = %v, want not found", err)
+	}
+
+	setFlag(t, getCmd, "quiet-missing", "true")
+	out, err := captureStdout(t, func() error { return runGet(getCmd, []string{"dev", "missing"}) })
+	if err != nil || out != "" {
+		t.Errorf("--quiet-missing: out = %q, err = %v, want nothing and exit 0", out, err)
+	}
+
+	setFlag(t, getCmd, "default", "off")
+	out, err = captureStdout(t, func() error { return runGet(getCmd, []string{"dev", "missing"}) })
+	if err != nil || out != "off\n" {
+		t.Errorf("--default: out = %q, err = %v, want the default and exit 0", out, err)
+	}
+
+	// Only a missing secret is forgiven
+	if _, err := captureStdout(t, func() error { return runGet(getCmd, []string{"dev", "broken"}) }); ExitCode(err) == ExitOK {
+		t.Error("--default with a decryption failure: want a nonzero exit")
+	}
+	t.Setenv("USER_EMAIL", "mallory@example.com")
+	if _, err := captureStdout(t, func() error { return runGet(getCmd, []string{"dev", "missing"}) }); ExitCode(err) != ExitAccessDenied {
+		t.Errorf("--default without access: err = %v, want access denied", err)
+	}
+	t.Setenv("USER_EMAIL", "alice@example.com")
+
+	for _, flag := range [][2]string{{"copy", "true"}, {"field", "user"}, {"raw", "true"}} {
+		t.Run(flag[0], func(t *testing.T) {
+			setFlag(t, getCmd, flag[0], flag[1])
+			if err := runGet(getCmd, []string{"dev", "missing"}); ExitCode(err) != ExitValidation {
+				t.Errorf("--default with --%s: err = %v, want a validation error", flag[0], err)
+			}
+		})
+	}
+}
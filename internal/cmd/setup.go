@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/NuevaNext/secrets-cli/internal/config"
 	"github.com/NuevaNext/secrets-cli/internal/gpg"
+	"github.com/NuevaNext/secrets-cli/internal/pass"
 	"github.com/spf13/cobra"
 )
 
@@ -17,17 +21,49 @@ var setupCmd = &cobra.Command{
 This command:
   1. Verifies your GPG key exists in the stored keys
   2. Imports all stored public keys to your GPG keyring
-  3. Lists vaults and shows your access status
+  3. Checks that your secret key is in your GPG keyring
+  4. Lists vaults and shows your access status
+  5. Test-decrypts one secret of a vault you can access
 
-Example:
+On a new machine, use --import-secret-key to import your private key from
+a file first. setup then test-decrypts to confirm the key is usable.
+
+The final check decrypts one secret you are a recipient of, the same way
+'get' does, and reports gpg's error with hints if it fails: a missing
+secret key, a secret not re-encrypted for you yet, or gpg-agent unable
+to ask for your passphrase. setup then exits nonzero. Use --verify=false
+to skip it, e.g. where no passphrase can be entered.
+
+Use --preset to prime gpg-agent with your passphrase through
+gpg-preset-passphrase, so later commands in the session do not prompt.
+The passphrase is checked with a test decryption first. It is read from
+GPG_PASSPHRASE with --batch, otherwise prompted for. gpg-agent must allow
+it: add allow-preset-passphrase to gpg-agent.conf and run
+'gpgconf --reload gpg-agent'. The cached passphrase expires with the
+agent's max-cache-ttl.
+
+Examples:
   git clone git@github.com:org/project.git
   cd project
-  secrets-cli setup --email you@example.com`,
+  secrets-cli setup --email you@example.com
+  secrets-cli setup --email you@example.com --import-secret-key ~/private.asc
+  secrets-cli setup --email you@example.com --preset
+  secrets-cli setup --email you@example.com --verify=false`,
 	RunE: runSetup,
 }
 
+var (
+	setupSecretKeyFile string
+	setupPreset        bool
+	setupVerify        bool
+)
+
 func init() {
 	rootCmd.AddCommand(setupCmd)
+
+	setupCmd.Flags().StringVar(&setupSecretKeyFile, "import-secret-key", "", "Import your private key from this file before verifying access")
+	setupCmd.Flags().BoolVar(&setupVerify, "verify", true, "Test-decrypt a secret from a vault you can access")
+	setupCmd.Flags().BoolVar(&setupPreset, "preset", false, "Cache your passphrase in gpg-agent for this session via gpg-preset-passphrase")
 }
 
 func runSetup(cmd *cobra.Command, args []string) error {
@@ -36,13 +72,16 @@ func runSetup(cmd *cobra.Command, args []string) error {
 
 	// Check if secrets directory exists
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	// Require email
 	if email == "" {
 		return fmt.Errorf("email is required. Use --email flag or set USER_EMAIL environment variable")
 	}
+	if err := validateEmail(email); err != nil {
+		return err
+	}
 
 	// Load config
 	cfg, err := config.LoadConfig(secretsDir)
@@ -64,14 +103,55 @@ func runSetup(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("✓ Found your key: %s\n", keyFile)
 
+	g := newGPG()
+	if setupSecretKeyFile != "" {
+		if err := g.ImportSecretKey(setupSecretKeyFile); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Imported secret key from %s\n", setupSecretKeyFile)
+	}
+
 	// Import all keys
-	g := gpg.New(GetGPGBinary())
-	imported, err := g.ImportKeyFromDir(keysDir)
+	results, err := g.ImportKeyFromDir(keysDir)
 	if err != nil {
 		return fmt.Errorf("failed to import keys: %w", err)
 	}
 
-	fmt.Printf("✓ Imported %d key(s) to your GPG keyring\n", imported)
+	fmt.Println("Importing keys to your GPG keyring:")
+	if failed := printImportResults(results); failed > 0 {
+		fmt.Printf("⚠ %d key(s) failed to import; encrypting for those members will fail\n", failed)
+	}
+
+	// Match the secret key against the stored public key's fingerprint
+	secretKeyID := email
+	if keys, err := g.ShowKeyFile(keyFile); err == nil && keys[0].Fingerprint != "" {
+		secretKeyID = keys[0].Fingerprint
+	}
+	if !g.SecretKeyExists(secretKeyID) {
+		if setupSecretKeyFile != "" {
+			return fmt.Errorf("%s does not contain the secret key for %s", setupSecretKeyFile, email)
+		}
+		fmt.Printf("⚠ Your public key is in the store but no matching secret key is in your GPG keyring.\n")
+		fmt.Printf("  Decryption will fail until you import it, e.g. with: secrets-cli setup --import-secret-key <file>\n")
+	} else if setupSecretKeyFile != "" {
+		ciphertext, err := g.Encrypt([]byte("secrets-cli setup check\n"), []string{secretKeyID})
+		if err == nil {
+			_, err = g.DecryptBytes(ciphertext)
+		}
+		if err != nil {
+			return fmt.Errorf("your secret key was imported but test decryption failed: %w", err)
+		}
+		fmt.Println("✓ Verified your secret key can decrypt")
+	}
+
+	if setupPreset {
+		if err := presetAgentPassphrase(g, secretKeyID, email); err != nil {
+			if !errors.Is(err, gpg.ErrPresetUnavailable) {
+				return err
+			}
+			fmt.Println("⚠ gpg-preset-passphrase is not installed (it ships with GnuPG in its libexec directory); skipping --preset")
+		}
+	}
 
 	// List vaults and check access
 	vaults, err := config.ListVaults(secretsDir)
@@ -79,6 +159,7 @@ func runSetup(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list vaults: %w", err)
 	}
 
+	var accessible []string
 	if len(vaults) > 0 {
 		fmt.Println()
 		fmt.Println("Available vaults:")
@@ -98,6 +179,7 @@ func runSetup(cmd *cobra.Command, args []string) error {
 			}
 
 			if hasAccess {
+				accessible = append(accessible, vault)
 				fmt.Printf("  ✓ %s (access granted)\n", vault)
 			} else {
 				fmt.Printf("  ✗ %s (no access)\n", vault)
@@ -105,8 +187,116 @@ func runSetup(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if setupVerify && len(accessible) > 0 {
+		fmt.Println()
+		if err := verifyDecryption(g, secretsDir, email, secretKeyID, accessible); err != nil {
+			return err
+		}
+	}
+
 	fmt.Println()
 	fmt.Println("Setup complete!")
 
 	return nil
 }
+
+// verifyDecryption decrypts the first secret of the given vaults that is
+// encrypted for email, and explains the likely cause if that fails
+func verifyDecryption(g *gpg.GPG, secretsDir, email, secretKeyID string, vaults []string) error {
+	for _, vault := range vaults {
+		vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vault))
+		if err != nil {
+			continue
+		}
+		p := newPass(config.GetStoreDir(secretsDir, vault))
+		secrets, err := p.List()
+		if err != nil {
+			continue
+		}
+		for _, secret := range secrets {
+			if !isRecipient(secretRecipients(secretsDir, vaultCfg, secret), email) {
+				continue // in a subvault you are not a member of
+			}
+			if _, err := p.Show(secret); err != nil {
+				fmt.Printf("✗ Test decryption of %s/%s failed\n", vault, secret)
+				printDecryptionHints(g, p, secretKeyID, vault, secret)
+				return fmt.Errorf("you have access to %s but cannot decrypt its secrets: %w", vault, err)
+			}
+			fmt.Printf("✓ Test-decrypted %s/%s\n", vault, secret)
+			return nil
+		}
+	}
+	fmt.Println("⚠ Skipped the decryption check: your vaults have no secrets yet")
+	return nil
+}
+
+// isRecipient reports whether email is one of recipients, ignoring case
+func isRecipient(recipients []string, email string) bool {
+	for _, r := range recipients {
+		if strings.EqualFold(r, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// printDecryptionHints prints the likely reason a secret could not be
+// decrypted with the secret key secretKeyID
+func printDecryptionHints(g *gpg.GPG, p *pass.Pass, secretKeyID, vault, secret string) {
+	if !g.SecretKeyExists(secretKeyID) {
+		fmt.Println("  Your secret key is not in your GPG keyring. Import it with: secrets-cli setup --import-secret-key <file>")
+		return
+	}
+	if ids, err := g.RecipientKeyIDs(filepath.Join(p.StoreDir, secret+".gpg")); err == nil {
+		if keys, err := g.LookupKeys(secretKeyID); err == nil {
+			encryptedForYou := false
+			for _, id := range ids {
+				if keys[0].HasKeyID(id) {
+					encryptedForYou = true
+				}
+			}
+			if !encryptedForYou {
+				fmt.Printf("  %s is not encrypted for your key yet. Ask a member of %s to run: secrets-cli sync %s\n", secret, vault, vault)
+				return
+			}
+		}
+	}
+	fmt.Println("  Your secret key is present, so gpg-agent probably could not ask for your passphrase.")
+	fmt.Println("  Run 'export GPG_TTY=$(tty)', or set pinentry-program in ~/.gnupg/gpg-agent.conf,")
+	fmt.Println("  then restart the agent with 'gpgconf --kill gpg-agent' and run setup again.")
+}
+
+// presetAgentPassphrase checks the passphrase of the secret key id with a
+// test decryption, then caches it in gpg-agent for each of its keygrips
+func presetAgentPassphrase(g *gpg.GPG, id, email string) error {
+	grips, err := g.Keygrips(id)
+	if err != nil {
+		return err
+	}
+
+	passphrase := g.Passphrase
+	if passphrase == "" {
+		if passphrase, err = promptPassphrase(email); err != nil {
+			return err
+		}
+	}
+
+	check := *g
+	check.Batch = true
+	check.Passphrase = passphrase
+	ciphertext, err := g.Encrypt([]byte("secrets-cli preset check\n"), []string{id})
+	if err == nil {
+		_, err = check.DecryptBytes(ciphertext)
+	}
+	if err != nil {
+		return fmt.Errorf("passphrase check failed, nothing was cached: %w", err)
+	}
+
+	for _, grip := range grips {
+		if err := g.PresetPassphrase(grip, passphrase); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✓ Cached your passphrase in gpg-agent (%d keygrip(s))\n", len(grips))
+	return nil
+}
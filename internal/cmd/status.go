@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show uncommitted changes to the secrets store",
+	Long: `Show what changed in the secrets store since the last commit, according
+to git, in terms of vaults and secrets rather than file paths. Nothing is
+decrypted.
+
+Changes are grouped by vault (secrets added, modified or deleted, changed
+recipients and vault.yaml), followed by public keys and store settings.
+Staged and unstaged changes are shown alike.
+
+Use --exit-code in pre-commit hooks or scripts: the command then exits 1
+when there are uncommitted changes.
+
+Examples:
+  secrets-cli status
+  secrets-cli status --exit-code`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+var statusExitCode bool
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().BoolVar(&statusExitCode, "exit-code", false, "Exit 1 if there are uncommitted changes")
+}
+
+// statusChange is one uncommitted change, described in store terms
+type statusChange struct {
+	Section string // vault name, or "" for keys and store files
+	Item    string
+	Kind    string // added, modified, deleted or renamed
+}
+
+// statusLayout maps repository paths, relative to the git root and
+// slash-separated, back to vaults and secrets
+type statusLayout struct {
+	secretsDir string
+	stores     map[string]string // vault name -> password store
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	abs, err := filepath.Abs(secretsDir)
+	if err != nil {
+		return err
+	}
+	out, err := exec.Command("git", "-C", abs, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return fmt.Errorf("status needs the secrets directory to be in a git repository: %s is not in one", secretsDir)
+	}
+	gitRoot := strings.TrimSpace(string(out))
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+
+	layout := statusLayout{secretsDir: filepath.ToSlash(relToRoot(gitRoot, abs)), stores: map[string]string{}}
+	pathspecs := []string{layout.secretsDir}
+	vaults, err := config.ListVaults(secretsDir)
+	if err != nil {
+		return err
+	}
+	for _, vaultName := range vaults {
+		storeDir, err := filepath.Abs(config.GetStoreDir(secretsDir, vaultName))
+		if err != nil {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(storeDir); err == nil {
+			storeDir = resolved
+		}
+		if isOutside(gitRoot, storeDir) {
+			continue
+		}
+		rel := filepath.ToSlash(relToRoot(gitRoot, storeDir))
+		layout.stores[vaultName] = rel
+		if !strings.HasPrefix(rel+"/", layout.secretsDir+"/") {
+			pathspecs = append(pathspecs, rel)
+		}
+	}
+
+	gitCmd := exec.Command("git", append([]string{"-C", gitRoot, "status", "--porcelain", "-z", "--untracked-files=all", "--"}, pathspecs...)...)
+	var stdout, stderr bytes.Buffer
+	gitCmd.Stdout = &stdout
+	gitCmd.Stderr = &stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("git status failed: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	var changes []statusChange
+	for _, entry := range parseGitStatus(stdout.String()) {
+		changes = append(changes, layout.describe(entry[0], entry[1]))
+	}
+	if len(changes) == 0 {
+		fmt.Println("No uncommitted changes to the secrets store")
+		return nil
+	}
+	printStatus(changes)
+
+	if statusExitCode {
+		return fmt.Errorf("%d uncommitted change(s) in %s", len(changes), secretsDir)
+	}
+	return nil
+}
+
+// parseGitStatus parses 'git status --porcelain -z' output into pairs of
+// change kind and path. Renames are reported at their new path.
+func parseGitStatus(output string) [][2]string {
+	var entries [][2]string
+	records := strings.Split(output, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+		x, y, file := record[0], record[1], record[3:]
+
+		var kind string
+		switch {
+		case x == 'R' || x == 'C':
+			kind = "renamed"
+			i++ // the original path follows
+		case x == '?' || x == 'A':
+			kind = "added"
+		case x == 'D' || y == 'D':
+			kind = "deleted"
+		default:
+			kind = "modified"
+		}
+		entries = append(entries, [2]string{kind, file})
+	}
+	return entries
+}
+
+// describe translates a changed file into the vault, secret or setting it
+// belongs to
+func (l statusLayout) describe(kind, file string) statusChange {
+	// A password store may live outside the secrets directory; the
+	// deepest matching store wins
+	vault, store := "", ""
+	for name, dir := range l.stores {
+		if strings.HasPrefix(file, dir+"/") && len(dir) > len(store) {
+			vault, store = name, dir
+		}
+	}
+	if vault != "" {
+		return describeStoreFile(vault, strings.TrimPrefix(file, store+"/"), kind)
+	}
+
+	rel, ok := strings.CutPrefix(file, l.secretsDir+"/")
+	if !ok {
+		return statusChange{Item: file, Kind: kind}
+	}
+	parts := strings.SplitN(rel, "/", 3)
+	switch {
+	case len(parts) == 3 && parts[0] == "vaults" && parts[2] == "vault.yaml":
+		return statusChange{Section: parts[1], Item: "vault.yaml (members and settings)", Kind: kind}
+	case len(parts) == 3 && parts[0] == "vaults" && strings.HasPrefix(parts[2], ".password-store/"):
+		// The store of a deleted vault
+		return describeStoreFile(parts[1], strings.TrimPrefix(parts[2], ".password-store/"), kind)
+	case len(parts) == 2 && parts[0] == "keys" && strings.HasSuffix(parts[1], ".asc"):
+		return statusChange{Item: "key " + strings.TrimSuffix(parts[1], ".asc"), Kind: kind}
+	}
+	return statusChange{Item: rel, Kind: kind}
+}
+
+// describeStoreFile describes a file inside a vault's password store
+func describeStoreFile(vault, file, kind string) statusChange {
+	switch {
+	case strings.HasSuffix(file, ".gpg"):
+		return statusChange{Section: vault, Item: strings.TrimSuffix(file, ".gpg"), Kind: kind}
+	case path.Base(file) == ".gpg-id" && path.Dir(file) == ".":
+		return statusChange{Section: vault, Item: "recipients (.gpg-id)", Kind: kind}
+	case path.Base(file) == ".gpg-id":
+		return statusChange{Section: vault, Item: "recipients of " + path.Dir(file) + "/ (.gpg-id)", Kind: kind}
+	}
+	return statusChange{Section: vault, Item: file, Kind: kind}
+}
+
+// printStatus prints changes grouped by vault, then keys and store files
+func printStatus(changes []statusChange) {
+	bySection := map[string][]statusChange{}
+	var sections []string
+	for _, c := range changes {
+		if _, ok := bySection[c.Section]; !ok && c.Section != "" {
+			sections = append(sections, c.Section)
+		}
+		bySection[c.Section] = append(bySection[c.Section], c)
+	}
+	sort.Strings(sections)
+
+	printList := func(list []statusChange) {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Item < list[j].Item })
+		for _, c := range list {
+			fmt.Printf("  %-9s %s\n", c.Kind, c.Item)
+		}
+	}
+	for _, section := range sections {
+		fmt.Printf("Vault %s:\n", section)
+		printList(bySection[section])
+	}
+	if store := bySection[""]; len(store) > 0 {
+		fmt.Println("Store:")
+		printList(store)
+	}
+	fmt.Printf("\n%d uncommitted change(s)\n", len(changes))
+}
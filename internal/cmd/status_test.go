@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGitStatus(t *testing.T) {
+	output := "?? .secrets/keys/bob@example.com.asc\x00 M .secrets/config.yaml\x00D  .secrets/vaults/dev/.password-store/old.gpg\x00" +
+		"R  .secrets/vaults/dev/.password-store/new.gpg\x00.secrets/vaults/dev/.password-store/was.gpg\x00AM .secrets/vaults/dev/.password-store/db/pw.gpg\x00"
+	want := [][2]string{
+		{"added", ".secrets/keys/bob@example.com.asc"},
+		{"modified", ".secrets/config.yaml"},
+		{"deleted", ".secrets/vaults/dev/.password-store/old.gpg"},
+		{"renamed", ".secrets/vaults/dev/.password-store/new.gpg"},
+		{"added", ".secrets/vaults/dev/.password-store/db/pw.gpg"},
+	}
+	if got := parseGitStatus(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGitStatus() = %q, want %q", got, want)
+	}
+}
+
+func TestStatusLayoutDescribe(t *testing.T) {
+	layout := statusLayout{
+		secretsDir: ".secrets",
+		stores: map[string]string{
+			"dev":  ".secrets/vaults/dev/.password-store",
+			"prod": "store/prod",
+		},
+	}
+	tests := []struct {
+		file string
+		want statusChange
+	}{
+		{".secrets/vaults/dev/.password-store/db/pw.gpg", statusChange{Section: "dev", Item: "db/pw"}},
+		{".secrets/vaults/dev/.password-store/.gpg-id", statusChange{Section: "dev", Item: "recipients (.gpg-id)"}},
+		{".secrets/vaults/dev/.password-store/admin/.gpg-id", statusChange{Section: "dev", Item: "recipients of admin/ (.gpg-id)"}},
+		{".secrets/vaults/dev/vault.yaml", statusChange{Section: "dev", Item: "vault.yaml (members and settings)"}},
+		{"store/prod/api/key.gpg", statusChange{Section: "prod", Item: "api/key"}},
+		{".secrets/vaults/gone/.password-store/x.gpg", statusChange{Section: "gone", Item: "x"}},
+		{".secrets/keys/bob@example.com.asc", statusChange{Item: "key bob@example.com"}},
+		{".secrets/config.yaml", statusChange{Item: "config.yaml"}},
+	}
+	for _, tt := range tests {
+		tt.want.Kind = "modified"
+		if got := layout.describe("modified", tt.file); got != tt.want {
+			t.Errorf("describe(%q) = %+v, want %+v", tt.file, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var vaultSubvaultCmd = &cobra.Command{
+	Use:   "subvault <vault> <path>",
+	Short: "Restrict a subtree of a vault to some of its members",
+	Long: `Encrypt the secrets below <path> only for the given members of the vault,
+instead of for every member.
+
+pass supports a .gpg-id in any directory of a password store; the secrets
+below it are encrypted for the recipients it lists, those of the nearest
+such directory winning. This command writes that file and re-encrypts
+only the secrets under <path>. Subvault members must be members of the
+vault. Recovery keys and backup recipients are added as for the vault.
+
+The subvaults are recorded in vault.yaml, so sync restores their .gpg-id
+files and members removed from the vault are removed from its subvaults
+too. A member added to the vault does not get access to existing
+subvaults. Use --remove to encrypt the subtree for every member again.
+
+Other members can still see the names of the secrets in a subvault, and
+keep any value they decrypted before it was restricted.
+
+Examples:
+  secrets-cli vault subvault prod admin --members alice@example.com,bob@example.com
+  secrets-cli vault subvault prod admin --remove`,
+	Args: cobra.ExactArgs(2),
+	RunE: runVaultSubvault,
+}
+
+var (
+	subvaultMembers []string
+	subvaultRemove  bool
+)
+
+func init() {
+	vaultCmd.AddCommand(vaultSubvaultCmd)
+
+	vaultSubvaultCmd.Flags().StringSliceVar(&subvaultMembers, "members", nil, "Vault members to encrypt the subtree for (comma-separated or repeatable)")
+	vaultSubvaultCmd.Flags().BoolVar(&subvaultRemove, "remove", false, "Encrypt the subtree for every vault member again")
+}
+
+func runVaultSubvault(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName, path := args[0], strings.Trim(args[1], "/")
+
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+	if err := validateSecretName(path); err != nil {
+		return err
+	}
+	if (len(subvaultMembers) > 0) == subvaultRemove {
+		return validationErrorf("use exactly one of --members and --remove")
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+	if err := checkWriteAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	return config.WithVaultLock(vaultDir, func() error {
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config: %w", err)
+		}
+		if vaultCfg.Archived {
+			return archivedError(vaultName)
+		}
+
+		storeDir := config.GetStoreDir(secretsDir, vaultName)
+		p := newPass(storeDir)
+
+		if subvaultRemove {
+			if _, ok := vaultCfg.Subvaults[path]; !ok {
+				return notFoundErrorf("%s is not a subvault of %s", path, vaultName)
+			}
+			delete(vaultCfg.Subvaults, path)
+			vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+			if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+				return fmt.Errorf("failed to save vault config: %w", err)
+			}
+			if err := p.InitPath(path, nil); err != nil {
+				return fmt.Errorf("failed to re-encrypt %s: %w", path, err)
+			}
+			fmt.Printf("✓ Removed subvault %s/%s/; its secrets are encrypted like their parent again\n", vaultName, path)
+			recordChange("remove subvault %s/%s/", vaultName, path)
+			return nil
+		}
+
+		// Keep the spelling used in the member list
+		var members []string
+		seen := map[string]bool{}
+		for _, m := range subvaultMembers {
+			m = strings.TrimSpace(m)
+			if seen[strings.ToLower(m)] {
+				continue
+			}
+			seen[strings.ToLower(m)] = true
+			found := ""
+			for _, member := range vaultCfg.Members {
+				if strings.EqualFold(member, m) {
+					found = member
+					break
+				}
+			}
+			if found == "" {
+				return validationErrorf("%s is not a member of vault %s. Add them first with: secrets-cli vault add-member %s %s", m, vaultName, vaultName, m)
+			}
+			members = append(members, found)
+		}
+
+		if vaultCfg.Subvaults == nil {
+			vaultCfg.Subvaults = map[string][]string{}
+		}
+		vaultCfg.Subvaults[path] = members
+		vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to save vault config: %w", err)
+		}
+
+		recipients := subvaultRecipients(secretsDir, vaultCfg, path)
+		importRecipientKeys(newGPG(), secretsDir, recipients)
+		if err := p.InitPath(path, recipients); err != nil {
+			return fmt.Errorf("failed to re-encrypt %s: %w", path, err)
+		}
+
+		reencrypted, _ := p.ListCached()
+		n := 0
+		for _, secret := range reencrypted {
+			if strings.HasPrefix(secret, path+"/") {
+				n++
+			}
+		}
+		fmt.Printf("✓ Restricted %s/%s/ to %s\n", vaultName, path, strings.Join(members, ", "))
+		recordChange("restrict %s/%s/ to %s", vaultName, path, strings.Join(members, ", "))
+		fmt.Printf("✓ Re-encrypted %d secret(s)\n", n)
+		if email != "" && !seen[strings.ToLower(email)] {
+			fmt.Printf("⚠ You are not a member of this subvault and can no longer decrypt its secrets\n")
+		}
+		return nil
+	})
+}
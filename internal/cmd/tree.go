@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var treeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show every vault you can access with its secrets",
+	Long: `Print each vault you have access to followed by its secrets as a tree,
+for an overview of the whole store. Vaults you are not a member of are
+skipped. Each vault shows its member count and is marked [read-only] or
+[archived] where that applies.
+
+Use --depth to limit how deep the tree goes; directories cut off at the
+limit show how many secrets they hold. Nothing is decrypted.
+
+Examples:
+  secrets-cli tree
+  secrets-cli tree --depth 1`,
+	Args: cobra.NoArgs,
+	RunE: runTree,
+}
+
+var treeDepth int
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+
+	treeCmd.Flags().IntVar(&treeDepth, "depth", 0, "Only show this many levels below each vault (0 for all)")
+}
+
+func runTree(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+
+	if treeDepth < 0 {
+		return validationErrorf("--depth must not be negative")
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	vaults, err := config.ListVaults(secretsDir)
+	if err != nil {
+		return err
+	}
+
+	shown := 0
+	for _, vaultName := range vaults {
+		if checkReadAccess(secretsDir, vaultName, email) != nil {
+			continue
+		}
+		vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Skipped %s: %v\n", vaultName, err)
+			continue
+		}
+		secrets, err := newPass(config.GetStoreDir(secretsDir, vaultName)).List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Skipped %s: %v\n", vaultName, err)
+			continue
+		}
+
+		markers := ""
+		if email != "" && isVaultMember(vaultCfg, email) && vaultCfg.MemberRole(email) == config.RoleRead {
+			markers += " [read-only]"
+		}
+		if vaultCfg.Archived {
+			markers += " [archived]"
+		}
+
+		if shown > 0 {
+			fmt.Println()
+		}
+		shown++
+		fmt.Printf("%s (%d member(s))%s\n", vaultName, len(vaultCfg.Members), markers)
+		if len(secrets) == 0 {
+			fmt.Println("└── (no secrets)")
+			continue
+		}
+		printSecretTree(os.Stdout, secrets, "", treeDepth)
+	}
+
+	if shown == 0 {
+		fmt.Println("No vaults you can access")
+	}
+	return nil
+}
+
+// secretTree is a node in the hierarchy formed by splitting secret names on "/"
+type secretTree struct {
+	children map[string]*secretTree
+	leaf     bool // a secret exists at this exact path
+}
+
+// buildSecretTree arranges flat secret names into a tree
+func buildSecretTree(secrets []string) *secretTree {
+	root := &secretTree{children: map[string]*secretTree{}}
+	for _, secret := range secrets {
+		node := root
+		for _, part := range strings.Split(secret, "/") {
+			child, ok := node.children[part]
+			if !ok {
+				child = &secretTree{children: map[string]*secretTree{}}
+				node.children[part] = child
+			}
+			node = child
+		}
+		node.leaf = true
+	}
+	return root
+}
+
+// printSecretTree renders secrets as an indented tree, directories first and
+// siblings sorted alphabetically. With maxDepth above zero, directories at
+// that depth are not expanded and show their secret count instead. Nothing
+// is decrypted; only names are used.
+func printSecretTree(w io.Writer, secrets []string, indent string, maxDepth int) {
+	buildSecretTree(secrets).render(w, indent, maxDepth)
+}
+
+// count returns the number of secrets at or below t
+func (t *secretTree) count() int {
+	n := 0
+	if t.leaf {
+		n++
+	}
+	for _, child := range t.children {
+		n += child.count()
+	}
+	return n
+}
+
+func (t *secretTree) render(w io.Writer, prefix string, depth int) {
+	type entry struct {
+		name string
+		node *secretTree
+		dir  bool
+	}
+
+	var dirs, leaves []entry
+	for name, node := range t.children {
+		if len(node.children) > 0 {
+			dirs = append(dirs, entry{name, node, true})
+		}
+		// A path can be both a secret and a directory (e.g. "api" and "api/key")
+		if node.leaf {
+			leaves = append(leaves, entry{name, node, false})
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].name < dirs[j].name })
+	sort.Slice(leaves, func(i, j int) bool { return leaves[i].name < leaves[j].name })
+
+	entries := append(dirs, leaves...)
+	for i, e := range entries {
+		last := i == len(entries)-1
+		branch, next := "├── ", "│   "
+		if last {
+			branch, next = "└── ", "    "
+		}
+
+		switch {
+		case e.dir && depth == 1:
+			// Only the secrets below, not one that shares the directory's name
+			below := e.node.count()
+			if e.node.leaf {
+				below--
+			}
+			fmt.Fprintf(w, "%s%s%s/ (%d)\n", prefix, branch, e.name, below)
+		case e.dir:
+			fmt.Fprintf(w, "%s%s%s/\n", prefix, branch, e.name)
+			e.node.render(w, prefix+next, depth-1)
+		default:
+			fmt.Fprintf(w, "%s%s%s\n", prefix, branch, e.name)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintSecretTree(t *testing.T) {
+	secrets := []string{
+		"api/key",
+		"db/primary/password",
+		"apitoken",
+		"db/primary/user",
+		"db/replica/password",
+		"api",
+	}
+
+	var buf bytes.Buffer
+	printSecretTree(&buf, secrets, "", 0)
+
+	want := `├── api/
+│   └── key
+├── db/
+│   ├── primary/
+│   │   ├── password
+│   │   └── user
+│   └── replica/
+│       └── password
+├── api
+└── apitoken
+`
+	if got := buf.String(); got != want {
+		t.Errorf("printSecretTree() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintSecretTreeDepth(t *testing.T) {
+	secrets := []string{"api", "api/key", "db/primary/password", "db/primary/user", "token"}
+
+	var buf bytes.Buffer
+	printSecretTree(&buf, secrets, "", 1)
+
+	want := `├── api/ (1)
+├── db/ (2)
+├── api
+└── token
+`
+	if got := buf.String(); got != want {
+		t.Errorf("printSecretTree() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -53,3 +53,45 @@ func TestValidateSecretName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"valid email", "alice@example.com", false},
+		{"plus tag", "alice+ci@example.com", false},
+		{"subdomain", "bob@mail.corp.example.com", false},
+		{"empty", "", true},
+		{"no at sign", "notanemail", true},
+		{"missing local part", "@example.com", true},
+		{"missing domain", "alice@", true},
+		{"multiple at signs", "alice@bob@example.com", true},
+		{"space", "alice smith@example.com", true},
+		{"trailing dot", "alice@example.com.", true},
+		{"path traversal", "../alice@example.com", true},
+		{"leading hyphen", "-alice@example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateEmail(tt.input); (err != nil) != tt.wantErr {
+				t.Errorf("validateEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRecipient(t *testing.T) {
+	for _, id := range []string{"recovery@example.com", "0123456789ABCDEF0123456789abcdef01234567"} {
+		if err := validateRecipient(id); err != nil {
+			t.Errorf("validateRecipient(%q) error = %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "recovery", "-recovery@example.com", "../keys/x", "0123456789ABCDEF", "recovery key@example.com"} {
+		if err := validateRecipient(id); ExitCode(err) != ExitValidation {
+			t.Errorf("validateRecipient(%q) error = %v, want a validation error", id, err)
+		}
+	}
+}
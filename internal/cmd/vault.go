@@ -1,15 +1,19 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/NuevaNext/secrets-cli/internal/config"
 	"github.com/NuevaNext/secrets-cli/internal/gpg"
 	"github.com/NuevaNext/secrets-cli/internal/pass"
+	"github.com/NuevaNext/secrets-cli/internal/secretgen"
 	"github.com/spf13/cobra"
 )
 
@@ -34,7 +38,20 @@ var vaultListCmd = &cobra.Command{
 	Short: "List all vaults",
 	Long: `List all vaults and show your access status (✓/✗).
 
-If --email is set, access status is shown for each vault.`,
+If --email is set, access status is shown for each vault.
+
+Use --member to show only the vaults a given person can access, e.g. when
+offboarding someone. It does not depend on your own email. Add --count to
+print just the number of matching vaults.
+
+Archived vaults are hidden unless --all is given, which lists them marked
+[archived].
+
+Examples:
+  secrets-cli vault list
+  secrets-cli vault list --all
+  secrets-cli vault list --member alice@example.com
+  secrets-cli vault list --member alice@example.com --count`,
 	RunE: runVaultList,
 }
 
@@ -46,9 +63,33 @@ var vaultCreateCmd = &cobra.Command{
 The vault name should be short and descriptive (e.g., dev, staging, production).
 You will be automatically added as the first member.
 
+Use --gpg-id-extra to add a break-glass recovery key (e.g. an escrow key held
+by the organization). Secrets are always encrypted for recovery keys in
+addition to members, so they stay recoverable if every member leaves.
+Recovery keys are not members and cannot use the CLI to access the vault.
+
+Use --from to start from another vault as a template: every secret name in
+it is created in the new vault with the placeholder value CHANGEME, so the
+structure stays consistent. Values are never copied. Placeholders are real
+secrets, so export includes them (--keep-going only skips secrets that fail
+to decrypt); replace them with 'set' before exporting. Use --members-from to
+start with another vault's members instead of just you.
+
+Use --gpg-ids-file for members whose keys you do not have yet, e.g. in an
+air-gapped setup. Each line of the file is an email, a key fingerprint
+followed by its email, or a fingerprint alone if your keyring knows its
+email; blank lines and lines starting with # are skipped. The members are
+recorded by email and .gpg-id is written directly, pinning the
+fingerprints given, without checking any key. Nobody can add secrets or
+verify the vault until the members' public keys are imported; add them
+with 'key add', then run 'sync'.
+
 Examples:
   secrets-cli vault create dev
-  secrets-cli vault create production --description "Production credentials"`,
+  secrets-cli vault create production --description "Production credentials"
+  secrets-cli vault create production --gpg-id-extra recovery@example.com
+  secrets-cli vault create feature-x --from dev --members-from dev
+  secrets-cli vault create offline --gpg-ids-file ids.txt`,
 	Args: cobra.ExactArgs(1),
 	RunE: runVaultCreate,
 }
@@ -59,7 +100,14 @@ var vaultInfoCmd = &cobra.Command{
 	Long: `Display detailed information about a vault including:
   - Description and creation date
   - Number of secrets
-  - List of members with access`,
+  - List of members with access
+
+Use --secrets to also list the names of the secrets. Values are never
+shown.
+
+Examples:
+  secrets-cli vault info dev
+  secrets-cli vault info dev --secrets`,
 	Args: cobra.ExactArgs(1),
 	RunE: runVaultInfo,
 }
@@ -69,18 +117,38 @@ var vaultDeleteCmd = &cobra.Command{
 	Short: "Delete a vault and all its secrets",
 	Long: `Permanently delete a vault and all secrets it contains.
 
-This action cannot be undone. Use --force to confirm.`,
+Only the vault's owner can delete it (any member, for vaults created before
+owners existed). This action cannot be undone. Use --force to confirm.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runVaultDelete,
 }
 
 var vaultAddMemberCmd = &cobra.Command{
-	Use:   "add-member <vault> <email>",
+	Use:   "add-member <vault> <email|@group>",
 	Short: "Grant vault access to a team member",
 	Long: `Add a member to a vault, granting them read/write access.
 
 The member's GPG key must first be added with 'secrets-cli key add'.
-All secrets will be re-encrypted to include the new member.`,
+All secrets will be re-encrypted to include the new member.
+
+Use --role read to add a read-only member, who can get, list and export
+secrets but not change the vault. Change a role later with
+'secrets-cli vault set-role'. Roles are enforced by this CLI only: every
+member is a GPG recipient and can decrypt secrets with gpg directly.
+
+Use @group to add every member of a group defined with 'secrets-cli group'.
+The vault stores the individual members and remembers the group, so
+'secrets-cli sync' later adds or removes people whose group membership
+changed.
+
+Use --dry-run to review the plan before re-encrypting. Vaults with more
+than 50 secrets are re-encrypted four secrets at a time; --parallel does
+so for smaller vaults too.
+
+Examples:
+  secrets-cli vault add-member dev alice@example.com
+  secrets-cli vault add-member dev carol@example.com --role read
+  secrets-cli vault add-member dev @backend`,
 	Args: cobra.ExactArgs(2),
 	RunE: runVaultAddMember,
 }
@@ -91,14 +159,124 @@ var vaultRemoveMemberCmd = &cobra.Command{
 	Long: `Remove a member from a vault, revoking their access.
 
 All secrets will be re-encrypted to exclude the removed member.
-Note: The removed member may still have copies of secrets they previously viewed.`,
+Note: The removed member may still have copies of secrets they previously viewed,
+so the secrets they could read are listed afterwards as candidates for rotation.
+
+Only the owner can remove the vault's owner, and the owner cannot remove
+themselves: hand the vault over with 'vault transfer-ownership' first.
+
+Use --rotate <glob> to replace matching secrets with newly generated values
+(see 'set --generate' for --policy presets). '**' matches every secret.
+Only the first line of a multi-field secret is replaced; its fields stay.
+Services using rotated secrets must be updated with the new values.
+
+Use --dry-run to review the plan before re-encrypting.
+
+Examples:
+  secrets-cli vault remove-member production bob@example.com
+  secrets-cli vault remove-member production bob@example.com --rotate 'db/*'`,
 	Args: cobra.ExactArgs(2),
 	RunE: runVaultRemoveMember,
 }
 
+var vaultSetRoleCmd = &cobra.Command{
+	Use:   "set-role <vault> <email> <read|read-write>",
+	Short: "Change a member's access level",
+	Long: `Set a vault member's role.
+
+  read         get, list and export secrets
+  read-write   also set, delete, rename, import and manage members (default)
+
+Roles are a policy enforced by this CLI, not by encryption. A read-only
+member is still a GPG recipient of every secret, so they can decrypt
+secrets with gpg or pass directly, and nothing stops them from editing
+the repository by hand; review changes to vault.yaml and the store as
+usual. Secrets are not re-encrypted, since the recipients do not change.
+
+A vault always keeps at least one read-write member, and its owner is
+always read-write.
+
+Examples:
+  secrets-cli vault set-role dev carol@example.com read
+  secrets-cli vault set-role dev carol@example.com read-write`,
+	Args: cobra.ExactArgs(3),
+	RunE: runVaultSetRole,
+}
+
+var vaultTransferOwnershipCmd = &cobra.Command{
+	Use:   "transfer-ownership <vault> <email>",
+	Short: "Make another member the vault's owner",
+	Long: `Make another read-write member the owner of a vault.
+
+A vault's owner is its creator until ownership is transferred. Only the
+owner can delete the vault or remove the owner from it; other members
+can still manage the rest of the membership. Vaults created before owners
+existed have no owner and treat every member as one; any member can give
+such a vault an owner with this command.
+
+Examples:
+  secrets-cli vault transfer-ownership production bob@example.com`,
+	Args: cobra.ExactArgs(2),
+	RunE: runVaultTransferOwnership,
+}
+
+var vaultArchiveCmd = &cobra.Command{
+	Use:   "archive <vault>",
+	Short: "Hide a retired vault and make it read-only",
+	Long: `Archive a vault that is no longer in use but must be kept, e.g. for
+audits. Archived vaults are hidden from 'vault list' (see --all) and
+refuse set, delete, rename, copy, import and add-member. get, list and
+export keep working, and members can still be removed.
+
+Examples:
+  secrets-cli vault archive legacy
+  secrets-cli vault unarchive legacy`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVaultArchive,
+}
+
+var vaultUnarchiveCmd = &cobra.Command{
+	Use:   "unarchive <vault>",
+	Short: "Restore an archived vault",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runVaultArchive,
+}
+
+var vaultMembersCmd = &cobra.Command{
+	Use:   "members",
+	Short: "Inspect vault membership",
+}
+
+var vaultMembersDiffCmd = &cobra.Command{
+	Use:   "diff <vault-a> <vault-b>",
+	Short: "Compare the members of two vaults",
+	Long: `Show which members only have access to one of two vaults, and which
+have access to both. Emails are compared case-insensitively.
+
+Examples:
+  secrets-cli vault members diff staging production
+  secrets-cli vault members diff staging production --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runVaultMembersDiff,
+}
+
 var (
-	vaultDescription string
-	forceDelete      bool
+	vaultDescription  string
+	forceDelete       bool
+	membersDiffJSON   bool
+	dryRun            bool
+	vaultExtraGPGIDs  []string
+	vaultListMember   string
+	vaultListCount    bool
+	vaultListAll      bool
+	rotateGlob        string
+	rotatePolicy      string
+	vaultFrom         string
+	vaultMembersFrom  string
+	memberRole        string
+	vaultInfoSecrets  bool
+	vaultGPGIDsFile   string
+	reencryptParallel bool
 )
 
 func init() {
@@ -109,9 +287,30 @@ func init() {
 	vaultCmd.AddCommand(vaultDeleteCmd)
 	vaultCmd.AddCommand(vaultAddMemberCmd)
 	vaultCmd.AddCommand(vaultRemoveMemberCmd)
-
+	vaultCmd.AddCommand(vaultSetRoleCmd)
+	vaultCmd.AddCommand(vaultTransferOwnershipCmd)
+	vaultCmd.AddCommand(vaultArchiveCmd)
+	vaultCmd.AddCommand(vaultUnarchiveCmd)
+	vaultCmd.AddCommand(vaultMembersCmd)
+	vaultMembersCmd.AddCommand(vaultMembersDiffCmd)
+
+	vaultListCmd.Flags().StringVar(&vaultListMember, "member", "", "Only list vaults this email is a member of")
+	vaultListCmd.Flags().BoolVar(&vaultListCount, "count", false, "Print only the number of matching vaults")
+	vaultListCmd.Flags().BoolVar(&vaultListAll, "all", false, "Include archived vaults")
 	vaultCreateCmd.Flags().StringVarP(&vaultDescription, "description", "d", "", "Vault description")
+	vaultCreateCmd.Flags().StringSliceVar(&vaultExtraGPGIDs, "gpg-id-extra", nil, "Recovery key always included as a recipient (repeatable)")
+	vaultCreateCmd.Flags().StringVar(&vaultFrom, "from", "", "Create the secret names of this vault, with placeholder values")
+	vaultCreateCmd.Flags().StringVar(&vaultMembersFrom, "members-from", "", "Start with the members of this vault")
+	vaultCreateCmd.Flags().StringVar(&vaultGPGIDsFile, "gpg-ids-file", "", "Add the members listed in this file (emails or fingerprints) without checking their keys")
+	vaultInfoCmd.Flags().BoolVar(&vaultInfoSecrets, "secrets", false, "Also list the secret names")
 	vaultDeleteCmd.Flags().BoolVarP(&forceDelete, "force", "f", false, "Force delete without confirmation")
+	vaultMembersDiffCmd.Flags().BoolVar(&membersDiffJSON, "json", false, "Output as JSON")
+	vaultAddMemberCmd.Flags().StringVar(&memberRole, "role", config.RoleReadWrite, "Role of the new member: read, read-write")
+	vaultAddMemberCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and show the re-encryption plan without changing anything")
+	vaultAddMemberCmd.Flags().BoolVar(&reencryptParallel, "parallel", false, "Re-encrypt four secrets at a time, whatever the vault size")
+	vaultRemoveMemberCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and show the re-encryption plan without changing anything")
+	vaultRemoveMemberCmd.Flags().StringVar(&rotateGlob, "rotate", "", "Regenerate secrets matching this glob after removal")
+	vaultRemoveMemberCmd.Flags().StringVar(&rotatePolicy, "policy", secretgen.DefaultPolicy, "Policy for --rotate: "+strings.Join(secretgen.Names(), ", "))
 }
 
 func runVaultList(cmd *cobra.Command, args []string) error {
@@ -119,7 +318,7 @@ func runVaultList(cmd *cobra.Command, args []string) error {
 	email := GetUserEmail()
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	vaults, err := config.ListVaults(secretsDir)
@@ -127,45 +326,69 @@ func runVaultList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(vaults) == 0 {
+	type vaultEntry struct {
+		name string
+		cfg  *config.VaultConfig
+	}
+	var entries []vaultEntry
+	archived := 0
+	for _, vault := range vaults {
+		vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vault))
+		if vaultListMember != "" && (err != nil || !isVaultMember(vaultCfg, vaultListMember)) {
+			continue
+		}
+		if err == nil && vaultCfg.Archived && !vaultListAll {
+			archived++
+			continue
+		}
+		entries = append(entries, vaultEntry{name: vault, cfg: vaultCfg})
+	}
+
+	if vaultListCount {
+		fmt.Println(len(entries))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		if archived > 0 {
+			fmt.Printf("No active vaults found (%d archived; use --all to list them)\n", archived)
+			return nil
+		}
+		if vaultListMember != "" {
+			fmt.Printf("No vaults found with member %s\n", vaultListMember)
+			return nil
+		}
 		fmt.Println("No vaults found. Create one with: secrets-cli vault create <name>")
 		return nil
 	}
 
 	fmt.Println("Vaults:")
-	for _, vault := range vaults {
-		vaultDir := config.GetVaultDir(secretsDir, vault)
-		vaultCfg, err := config.LoadVaultConfig(vaultDir)
-		if err != nil {
-			fmt.Printf("  %s (error loading config)\n", vault)
+	for _, entry := range entries {
+		vaultCfg := entry.cfg
+		if vaultCfg == nil {
+			fmt.Printf("  %s (error loading config)\n", entry.name)
 			continue
 		}
 
-		hasAccess := false
-		if email != "" {
-			for _, member := range vaultCfg.Members {
-				if member == email {
-					hasAccess = true
-					break
-				}
-			}
-		}
-
 		status := ""
 		if email != "" {
-			if hasAccess {
+			if isVaultMember(vaultCfg, email) {
 				status = " ✓"
 			} else {
 				status = " ✗"
 			}
 		}
 
+		if vaultCfg.Archived {
+			status += " [archived]"
+		}
+
 		desc := ""
 		if vaultCfg.Description != "" {
 			desc = fmt.Sprintf(" - %s", vaultCfg.Description)
 		}
 
-		fmt.Printf("  %s%s%s\n", vault, status, desc)
+		fmt.Printf("  %s%s%s\n", entry.name, status, desc)
 	}
 
 	return nil
@@ -181,7 +404,7 @@ func runVaultCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
-		return fmt.Errorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
 	}
 
 	if email == "" {
@@ -194,12 +417,98 @@ func runVaultCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("vault already exists: %s", vaultName)
 	}
 
+	// With the shared layout the store directory lives outside the vault
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	if _, err := os.Stat(storeDir); !os.IsNotExist(err) {
+		return fmt.Errorf("password store directory already exists: %s", storeDir)
+	}
+
 	// Check GPG key exists
-	g := gpg.New(GetGPGBinary())
+	g := newGPG()
 	if !g.KeyExists(email) {
 		return fmt.Errorf("no GPG key found for %s", email)
 	}
 
+	// Recovery keys must be usable before anything is encrypted for them
+	keysDir := config.GetKeysDir(secretsDir)
+	for _, extra := range vaultExtraGPGIDs {
+		if err := validateRecipient(extra); err != nil {
+			return err
+		}
+		keyPath := filepath.Join(keysDir, extra+".asc")
+		if _, err := os.Stat(keyPath); err == nil {
+			if err := g.ImportKey(keyPath); err != nil {
+				return fmt.Errorf("failed to import recovery key %s: %w", extra, err)
+			}
+		}
+		if !g.KeyExists(extra) {
+			return fmt.Errorf("no GPG key found for recovery key %s. Add it with: secrets-cli key add %s", extra, extra)
+		}
+	}
+
+	// Members from --gpg-ids-file, whose keys are checked only later
+	var listed []gpgIDEntry
+	if vaultGPGIDsFile != "" {
+		if vaultFrom != "" || vaultMembersFrom != "" {
+			return validationErrorf("--gpg-ids-file cannot be combined with --from or --members-from")
+		}
+		data, err := os.ReadFile(vaultGPGIDsFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", vaultGPGIDsFile, err)
+		}
+		if listed, err = parseGPGIDsFile(string(data), func(fpr string) string { return recipientEmail(g, fpr) }); err != nil {
+			return err
+		}
+	}
+
+	// Secret names to create from a template vault
+	var templateSecrets []string
+	if vaultFrom != "" {
+		if err := checkTemplateVault(secretsDir, vaultFrom, email); err != nil {
+			return err
+		}
+		names, err := newPass(config.GetStoreDir(secretsDir, vaultFrom)).List()
+		if err != nil {
+			return fmt.Errorf("failed to list secrets in %s: %w", vaultFrom, err)
+		}
+		templateSecrets = names
+	}
+
+	members := []string{email}
+	var fromCfg *config.VaultConfig
+	if vaultMembersFrom != "" {
+		if err := checkTemplateVault(secretsDir, vaultMembersFrom, email); err != nil {
+			return err
+		}
+		var err error
+		fromCfg, err = config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultMembersFrom))
+		if err != nil {
+			return fmt.Errorf("failed to load vault config for %s: %w", vaultMembersFrom, err)
+		}
+		for _, member := range fromCfg.Members {
+			if strings.EqualFold(member, email) {
+				continue
+			}
+			keyPath := filepath.Join(keysDir, member+".asc")
+			if _, err := os.Stat(keyPath); err == nil {
+				if err := g.ImportKey(keyPath); err != nil {
+					return fmt.Errorf("failed to import key for %s: %w", member, err)
+				}
+			}
+			if !g.KeyExists(member) {
+				return notFoundErrorf("no GPG key found for %s (a member of %s). Add it with: secrets-cli key add %s", member, vaultMembersFrom, member)
+			}
+			members = append(members, member)
+		}
+	}
+	pinned := map[string]string{}
+	for _, entry := range listed {
+		pinned[strings.ToLower(entry.Email)] = entry.ID
+		if !strings.EqualFold(entry.Email, email) {
+			members = append(members, entry.Email)
+		}
+	}
+
 	// Create vault directory
 	if err := os.MkdirAll(vaultDir, 0755); err != nil {
 		return fmt.Errorf("failed to create vault directory: %w", err)
@@ -208,11 +517,20 @@ func runVaultCreate(cmd *cobra.Command, args []string) error {
 	// Create vault config
 	now := time.Now().UTC().Format(time.RFC3339)
 	vaultCfg := &config.VaultConfig{
-		Name:        vaultName,
-		Description: vaultDescription,
-		Members:     []string{email},
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Version:      config.CurrentVaultConfigVersion,
+		Name:         vaultName,
+		Description:  vaultDescription,
+		Owner:        email,
+		Members:      members,
+		RecoveryKeys: vaultExtraGPGIDs,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	// Copied members keep their roles; the creator is always read-write
+	if fromCfg != nil {
+		for _, member := range members[1:] {
+			vaultCfg.SetMemberRole(member, fromCfg.MemberRole(member))
+		}
 	}
 
 	if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
@@ -220,35 +538,162 @@ func runVaultCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create vault config: %w", err)
 	}
 
-	// Initialize password store
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	if err := os.MkdirAll(storeDir, 0700); err != nil {
-		os.RemoveAll(vaultDir)
-		return fmt.Errorf("failed to create password store: %w", err)
-	}
+	// Initialize password store, and fill it from the template, under the
+	// vault lock. Any failure leaves no half-created vault behind.
+	err := config.WithVaultLock(vaultDir, func() error {
+		if err := os.MkdirAll(storeDir, 0700); err != nil {
+			return fmt.Errorf("failed to create password store: %w", err)
+		}
 
-	p := pass.New(storeDir)
-	if err := p.Init([]string{email}); err != nil {
+		p := newPass(storeDir)
+		var err error
+		if len(listed) > 0 {
+			// The store is empty, so .gpg-id is written without any key
+			recipients := vaultRecipients(secretsDir, vaultCfg)
+			for i, r := range recipients {
+				if id, ok := pinned[strings.ToLower(r)]; ok {
+					recipients[i] = id
+				}
+			}
+			err = p.SetRecipients(recipients)
+		} else {
+			err = p.Init(vaultRecipients(secretsDir, vaultCfg))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to initialize password store: %w", err)
+		}
+
+		for _, secret := range templateSecrets {
+			if err := p.Insert(secret, templatePlaceholder); err != nil {
+				return fmt.Errorf("failed to create placeholder %s: %w", secret, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		os.RemoveAll(vaultDir)
-		return fmt.Errorf("failed to initialize password store: %w", err)
+		os.RemoveAll(storeDir)
+		return err
 	}
 
 	fmt.Printf("✓ Created vault: %s\n", vaultName)
+	recordChange("create vault %s", vaultName)
 	if vaultDescription != "" {
 		fmt.Printf("  Description: %s\n", vaultDescription)
 	}
 	fmt.Printf("  Owner: %s\n", email)
+	source := vaultMembersFrom
+	if len(listed) > 0 {
+		source = vaultGPGIDsFile
+	}
+	for _, member := range members[1:] {
+		fmt.Printf("  Member: %s (from %s)\n", member, source)
+	}
+	for _, extra := range vaultCfg.RecoveryKeys {
+		fmt.Printf("  Recovery key: %s\n", extra)
+	}
+	if len(templateSecrets) > 0 {
+		fmt.Printf("  Placeholders: %d secret(s) from %s set to %s; replace them with 'secrets-cli set %s <secret>'\n",
+			len(templateSecrets), vaultFrom, templatePlaceholder, vaultName)
+	}
+	var missing []string
+	for _, entry := range listed {
+		keyPath := filepath.Join(keysDir, entry.Email+".asc")
+		if _, err := os.Stat(keyPath); err != nil && !g.KeyExists(entry.ID) {
+			missing = append(missing, entry.Email)
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Printf("⚠ No public key yet for: %s\n", strings.Join(missing, ", "))
+		fmt.Printf("  Secrets cannot be added or verified until they are imported. Add each with 'secrets-cli key add <email>', then run 'secrets-cli sync %s'\n", vaultName)
+	}
 
 	return nil
 }
 
+// gpgIDEntry is a member listed in a --gpg-ids-file: an email and the
+// recipient to write to .gpg-id for it, a fingerprint or the email itself
+type gpgIDEntry struct {
+	Email string
+	ID    string
+}
+
+// parseGPGIDsFile parses a --gpg-ids-file. Each line holds an email, a
+// fingerprint and an email in either order, or a fingerprint alone, which
+// emailFor must map to an email. Every malformed line is reported.
+func parseGPGIDsFile(data string, emailFor func(fingerprint string) string) ([]gpgIDEntry, error) {
+	var entries []gpgIDEntry
+	var problems []string
+	seen := map[string]bool{}
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var entry gpgIDEntry
+		valid := len(fields) <= 2
+		for _, field := range fields {
+			switch {
+			case gpg.IsFingerprint(field) && entry.ID == "":
+				entry.ID = strings.ToUpper(field)
+			case validateEmail(field) == nil && entry.Email == "":
+				entry.Email = field
+			default:
+				valid = false
+			}
+		}
+		if !valid {
+			problems = append(problems, fmt.Sprintf("line %d: expected an email and/or a fingerprint, got %q", i+1, strings.TrimSpace(line)))
+			continue
+		}
+		if entry.Email == "" {
+			if entry.Email = emailFor(entry.ID); entry.Email == "" {
+				problems = append(problems, fmt.Sprintf("line %d: no email known for %s; write it as '%s <email>'", i+1, entry.ID, entry.ID))
+				continue
+			}
+		}
+		if entry.ID == "" {
+			entry.ID = entry.Email
+		}
+		if seen[strings.ToLower(entry.Email)] {
+			continue
+		}
+		seen[strings.ToLower(entry.Email)] = true
+		entries = append(entries, entry)
+	}
+
+	if len(problems) > 0 {
+		return nil, validationErrorf("invalid GPG IDs file:\n  %s", strings.Join(problems, "\n  "))
+	}
+	if len(entries) == 0 {
+		return nil, validationErrorf("the GPG IDs file lists no members")
+	}
+	return entries, nil
+}
+
+// templatePlaceholder is the value of secrets created by vault create --from
+const templatePlaceholder = "CHANGEME"
+
+// checkTemplateVault checks that a vault used by vault create --from or
+// --members-from exists and can be read by email
+func checkTemplateVault(secretsDir, vaultName, email string) error {
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+	if _, err := os.Stat(config.GetVaultDir(secretsDir, vaultName)); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+	return checkReadAccess(secretsDir, vaultName, email)
+}
+
 func runVaultInfo(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	vaultName := args[0]
 
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
 	vaultCfg, err := config.LoadVaultConfig(vaultDir)
@@ -257,14 +702,22 @@ func runVaultInfo(cmd *cobra.Command, args []string) error {
 	}
 
 	// Count secrets
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	p := newPass(storeDir)
 	secrets, _ := p.List()
 
 	fmt.Printf("Vault: %s\n", vaultCfg.Name)
+	if vaultCfg.Archived {
+		fmt.Println("Status: archived (read-only)")
+	}
 	if vaultCfg.Description != "" {
 		fmt.Printf("Description: %s\n", vaultCfg.Description)
 	}
+	if vaultCfg.Owner != "" {
+		fmt.Printf("Owner: %s\n", vaultCfg.Owner)
+	} else {
+		fmt.Println("Owner: none (every member may delete the vault; set one with 'vault transfer-ownership')")
+	}
 	fmt.Printf("Created: %s\n", vaultCfg.CreatedAt)
 	if vaultCfg.UpdatedAt != "" && vaultCfg.UpdatedAt != vaultCfg.CreatedAt {
 		fmt.Printf("Updated: %s\n", vaultCfg.UpdatedAt)
@@ -273,8 +726,47 @@ func runVaultInfo(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println("Members:")
 	for _, member := range vaultCfg.Members {
+		if vaultCfg.MemberRole(member) == config.RoleRead {
+			fmt.Printf("  - %s [read-only]\n", member)
+			continue
+		}
 		fmt.Printf("  - %s\n", member)
 	}
+	if len(vaultCfg.RecoveryKeys) > 0 {
+		fmt.Println()
+		fmt.Println("Recovery recipients (encrypted for, not members):")
+		for _, extra := range vaultCfg.RecoveryKeys {
+			fmt.Printf("  - %s [recovery]\n", extra)
+		}
+	}
+	if len(vaultCfg.GPGOpts) > 0 {
+		fmt.Println()
+		fmt.Printf("gpg options: %s\n", strings.Join(vaultCfg.GPGOpts, " "))
+	}
+	if len(vaultCfg.Subvaults) > 0 {
+		fmt.Println()
+		fmt.Println("Subvaults (encrypted only for these members):")
+		for _, path := range vaultCfg.SubvaultPaths() {
+			fmt.Printf("  %s/: %s\n", path, strings.Join(vaultCfg.Subvaults[path], ", "))
+		}
+	}
+	if cfg, err := config.LoadConfig(secretsDir); err == nil && len(cfg.BackupRecipients) > 0 {
+		fmt.Println()
+		fmt.Println("Backup recipients (store-wide, encrypted for, not members):")
+		for _, backup := range cfg.BackupRecipients {
+			fmt.Printf("  - %s [backup]\n", backup)
+		}
+	}
+	if vaultInfoSecrets {
+		fmt.Println()
+		fmt.Println("Secret names:")
+		if len(secrets) == 0 {
+			fmt.Println("  (none)")
+		}
+		for _, secret := range secrets {
+			fmt.Printf("  - %s\n", secret)
+		}
+	}
 
 	return nil
 }
@@ -285,98 +777,253 @@ func runVaultDelete(cmd *cobra.Command, args []string) error {
 
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
+	}
+
+	// A vault whose config cannot be read can still be deleted to clean up
+	email := GetUserEmail()
+	if vaultCfg, err := config.LoadVaultConfig(vaultDir); err == nil && email != "" && !vaultCfg.IsOwner(email) {
+		return ownerOnlyError(vaultCfg, vaultName, "delete it")
 	}
 
 	if !forceDelete {
 		return fmt.Errorf("use --force to confirm deletion of vault: %s", vaultName)
 	}
 
+	if err := os.RemoveAll(config.GetStoreDir(secretsDir, vaultName)); err != nil {
+		return fmt.Errorf("failed to delete vault: %w", err)
+	}
 	if err := os.RemoveAll(vaultDir); err != nil {
 		return fmt.Errorf("failed to delete vault: %w", err)
 	}
 
 	fmt.Printf("✓ Deleted vault: %s\n", vaultName)
+	recordChange("delete vault %s", vaultName)
 	return nil
 }
 
-func runVaultAddMember(cmd *cobra.Command, args []string) error {
+// runVaultArchive handles both archive and unarchive
+func runVaultArchive(cmd *cobra.Command, args []string) error {
 	secretsDir := GetSecretsDir()
 	email := GetUserEmail()
 	vaultName := args[0]
-	memberEmail := args[1]
+	archive := cmd.Name() == "archive"
 
 	if err := validateName(vaultName); err != nil {
 		return err
 	}
-	if err := validateName(memberEmail); err != nil {
-		return err
-	}
 
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
-	// Load vault config
-	vaultCfg, err := config.LoadVaultConfig(vaultDir)
-	if err != nil {
-		return fmt.Errorf("failed to load vault config: %w", err)
-	}
+	return config.WithVaultLock(vaultDir, func() error {
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config: %w", err)
+		}
 
-	// Check caller has access (is a member)
-	if email != "" {
-		hasAccess := false
-		for _, member := range vaultCfg.Members {
-			if member == email {
-				hasAccess = true
-				break
+		if email != "" && !isVaultMember(vaultCfg, email) {
+			return accessDeniedErrorf("access denied: you are not a member of vault %s", vaultName)
+		}
+
+		if vaultCfg.Archived == archive {
+			if archive {
+				fmt.Printf("Vault %s is already archived\n", vaultName)
+			} else {
+				fmt.Printf("Vault %s is not archived\n", vaultName)
 			}
+			return nil
+		}
+
+		vaultCfg.Archived = archive
+		vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to save vault config: %w", err)
 		}
-		if !hasAccess {
-			return fmt.Errorf("access denied: you are not a member of vault %s", vaultName)
+
+		if archive {
+			fmt.Printf("✓ Archived vault: %s\n", vaultName)
+			recordChange("archive vault %s", vaultName)
+			fmt.Println("  It is hidden from 'vault list' (use --all) and read-only until unarchived")
+		} else {
+			fmt.Printf("✓ Unarchived vault: %s\n", vaultName)
+			recordChange("unarchive vault %s", vaultName)
 		}
-	}
+		return nil
+	})
+}
 
-	// Check member's key exists
-	keysDir := config.GetKeysDir(secretsDir)
-	keyFile := filepath.Join(keysDir, memberEmail+".asc")
-	if _, err := os.Stat(keyFile); os.IsNotExist(err) {
-		return fmt.Errorf("key not found for %s. Add it with: secrets-cli key add %s", memberEmail, memberEmail)
+func runVaultAddMember(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName := args[0]
+	memberArg := args[1]
+
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+	if err := config.ValidateRole(memberRole); err != nil {
+		return validationErrorf("%w", err)
 	}
 
-	// Check not already a member
-	for _, m := range vaultCfg.Members {
-		if m == memberEmail {
-			return fmt.Errorf("%s is already a member of %s", memberEmail, vaultName)
+	// "@group" adds every member of a group from groups.yaml
+	groupName, isGroup := strings.CutPrefix(memberArg, "@")
+	newMembers := []string{memberArg}
+	if isGroup {
+		if err := validateName(groupName); err != nil {
+			return err
+		}
+		groups, err := config.LoadGroups(secretsDir)
+		if err != nil {
+			return err
+		}
+		if newMembers, err = config.ResolveMembers(groups, []string{memberArg}); err != nil {
+			return err
+		}
+		if len(newMembers) == 0 {
+			return fmt.Errorf("group %s has no members", groupName)
+		}
+	}
+	for _, member := range newMembers {
+		if err := validateEmail(member); err != nil {
+			return err
 		}
 	}
 
-	// Import the member's key to GPG
-	g := gpg.New(GetGPGBinary())
-	if err := g.ImportKey(keyFile); err != nil {
-		return fmt.Errorf("failed to import key: %w", err)
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
-	// Add member
-	vaultCfg.Members = append(vaultCfg.Members, memberEmail)
-	vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	return config.WithVaultLock(vaultDir, func() error {
+		// Load vault config
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config: %w", err)
+		}
+
+		// Check caller has access (is a member)
+		if email != "" {
+			hasAccess := false
+			for _, member := range vaultCfg.Members {
+				if member == email {
+					hasAccess = true
+					break
+				}
+			}
+			if !hasAccess {
+				return accessDeniedErrorf("access denied: you are not a member of vault %s", vaultName)
+			}
+			if vaultCfg.MemberRole(email) == config.RoleRead {
+				return accessDeniedErrorf("access denied: you have read-only access to vault %s", vaultName)
+			}
+		}
 
-	if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
-		return fmt.Errorf("failed to save vault config: %w", err)
-	}
+		if vaultCfg.Archived {
+			return archivedError(vaultName)
+		}
 
-	// Re-encrypt secrets with new member
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
-	if err := p.ReInit(vaultCfg.Members); err != nil {
-		return fmt.Errorf("failed to re-encrypt secrets: %w", err)
-	}
+		// Check members' keys exist
+		keysDir := config.GetKeysDir(secretsDir)
+		for _, member := range newMembers {
+			keyFile := filepath.Join(keysDir, member+".asc")
+			if _, err := os.Stat(keyFile); os.IsNotExist(err) {
+				return notFoundErrorf("key not found for %s. Add it with: secrets-cli key add %s", member, member)
+			}
+		}
 
-	fmt.Printf("✓ Added %s to vault %s\n", memberEmail, vaultName)
-	fmt.Printf("✓ Re-encrypted %d secret(s)\n", countSecrets(storeDir))
+		// Check not already a member; a group only adds the missing ones
+		var toAdd []string
+		for _, member := range newMembers {
+			if isVaultMember(vaultCfg, member) {
+				if !isGroup {
+					return fmt.Errorf("%s is already a member of %s", member, vaultName)
+				}
+				continue
+			}
+			toAdd = append(toAdd, member)
+		}
+		if isGroup && len(toAdd) == 0 && vaultCfg.Groups[groupName] != nil {
+			return fmt.Errorf("every member of @%s is already a member of %s", groupName, vaultName)
+		}
 
-	return nil
+		// The stored key must be the only one for the email, or gpg may
+		// encrypt to another key of the same name in the keyring
+		g := newGPG()
+		for _, member := range toAdd {
+			if err := checkUniqueMemberKey(g, keysDir, member); err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			planned := *vaultCfg
+			planned.Members = append(append([]string{}, vaultCfg.Members...), toAdd...)
+			for _, member := range toAdd {
+				fmt.Printf("Would add %s to vault %s (%s)\n", member, vaultName, memberRole)
+			}
+			printReencryptPlan(config.GetStoreDir(secretsDir, vaultName), vaultRecipients(secretsDir, &planned))
+			return nil
+		}
+
+		// Import the members' keys to GPG
+		for _, member := range toAdd {
+			if err := g.ImportKey(filepath.Join(keysDir, member+".asc")); err != nil {
+				return fmt.Errorf("failed to import key for %s: %w", member, err)
+			}
+		}
+
+		// Add members, remembering the group's expansion for sync
+		vaultCfg.Members = append(vaultCfg.Members, toAdd...)
+		for _, member := range toAdd {
+			vaultCfg.SetMemberRole(member, memberRole)
+		}
+		if isGroup {
+			if vaultCfg.Groups == nil {
+				vaultCfg.Groups = map[string][]string{}
+			}
+			vaultCfg.Groups[groupName] = newMembers
+		}
+		vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+		if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to save vault config: %w", err)
+		}
+
+		// Re-encrypt secrets with new members
+		storeDir := config.GetStoreDir(secretsDir, vaultName)
+		p := newPass(storeDir)
+		if err := reencryptVault(p, secretsDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to re-encrypt secrets: %w", err)
+		}
+
+		for _, member := range toAdd {
+			fmt.Printf("✓ Added %s to vault %s (%s)\n", member, vaultName, memberRole)
+			recordChange("add %s to %s", member, vaultName)
+		}
+		fmt.Printf("✓ Re-encrypted %d secret(s)\n", countListed(p))
+
+		return nil
+	})
+}
+
+// checkUniqueMemberKey fails if the keyring holds a key for member other
+// than the one in keys/<member>.asc, listing the candidates
+func checkUniqueMemberKey(g *gpg.GPG, keysDir, member string) error {
+	stored, err := g.ShowKeyFile(filepath.Join(keysDir, member+".asc"))
+	if err != nil {
+		return err
+	}
+	storedFprs := gpg.EmailFingerprints(stored, member)
+	keyring, _ := g.LookupKeys(member) // no key in the keyring yet is fine
+	all := gpg.EmailFingerprints(append(stored, keyring...), member)
+	if len(all) <= 1 {
+		return nil
+	}
+	return validationErrorf("several keys found for %s: %s; keys/%s.asc holds %s. Remove the others from your keyring with 'gpg --delete-keys <fingerprint>', or replace the stored key with 'secrets-cli key remove %s' and 'secrets-cli key add %s --key-id <fingerprint>'",
+		member, strings.Join(all, ", "), member, strings.Join(storedFprs, ", "), member, member)
 }
 
 func runVaultRemoveMember(cmd *cobra.Command, args []string) error {
@@ -387,73 +1034,558 @@ func runVaultRemoveMember(cmd *cobra.Command, args []string) error {
 
 	vaultDir := config.GetVaultDir(secretsDir, vaultName)
 	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
-		return fmt.Errorf("vault not found: %s", vaultName)
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
-	// Load vault config
-	vaultCfg, err := config.LoadVaultConfig(vaultDir)
-	if err != nil {
-		return fmt.Errorf("failed to load vault config: %w", err)
+	if rotateGlob != "" {
+		if err := secretgen.Validate(rotatePolicy); err != nil {
+			return err
+		}
+		if _, err := path.Match(rotateGlob, ""); err != nil {
+			return validationErrorf("invalid --rotate glob %q: %v", rotateGlob, err)
+		}
 	}
 
-	// Check caller has access
-	if email != "" {
-		hasAccess := false
-		for _, member := range vaultCfg.Members {
-			if member == email {
-				hasAccess = true
+	return config.WithVaultLock(vaultDir, func() error {
+		// Load vault config
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config: %w", err)
+		}
+
+		// Check caller has access
+		if email != "" {
+			hasAccess := false
+			for _, member := range vaultCfg.Members {
+				if member == email {
+					hasAccess = true
+					break
+				}
+			}
+			if !hasAccess {
+				return accessDeniedErrorf("access denied: you are not a member of vault %s", vaultName)
+			}
+			if vaultCfg.MemberRole(email) == config.RoleRead {
+				return accessDeniedErrorf("access denied: you have read-only access to vault %s", vaultName)
+			}
+		}
+
+		// Check is a member
+		memberIndex := -1
+		for i, m := range vaultCfg.Members {
+			if m == memberEmail {
+				memberIndex = i
 				break
 			}
 		}
-		if !hasAccess {
-			return fmt.Errorf("access denied: you are not a member of vault %s", vaultName)
+		if memberIndex == -1 {
+			return notFoundErrorf("%s is not a member of %s", memberEmail, vaultName)
+		}
+		if vaultCfg.Owner != "" && strings.EqualFold(vaultCfg.Owner, memberEmail) {
+			if email != "" && !vaultCfg.IsOwner(email) {
+				return ownerOnlyError(vaultCfg, vaultName, "remove the owner")
+			}
+			return fmt.Errorf("cannot remove the owner of vault %s. Hand it over first with: secrets-cli vault transfer-ownership %s <email>", vaultName, vaultName)
+		}
+
+		// Cannot remove last member
+		if len(vaultCfg.Members) == 1 {
+			return fmt.Errorf("cannot remove the last member from a vault")
+		}
+		if writers := vaultCfg.Writers(); len(writers) == 1 && strings.EqualFold(writers[0], memberEmail) {
+			return fmt.Errorf("cannot remove the last read-write member from a vault. Give another member read-write access first with: secrets-cli vault set-role %s <email> read-write", vaultName)
+		}
+
+		storeDir := config.GetStoreDir(secretsDir, vaultName)
+		p := newPass(storeDir)
+		exposed, _ := p.List()
+		var toRotate []string
+		if rotateGlob != "" {
+			for _, secret := range exposed {
+				if matchSecretGlob(rotateGlob, secret) {
+					toRotate = append(toRotate, secret)
+				}
+			}
+		}
+
+		if dryRun {
+			planned := *vaultCfg
+			planned.Members = append(append([]string{}, vaultCfg.Members[:memberIndex]...), vaultCfg.Members[memberIndex+1:]...)
+			fmt.Printf("Would remove %s from vault %s\n", memberEmail, vaultName)
+			printReencryptPlan(storeDir, vaultRecipients(secretsDir, &planned))
+			if rotateGlob != "" {
+				fmt.Printf("Would rotate %d secret(s) matching %s\n", len(toRotate), rotateGlob)
+			}
+			return nil
+		}
+
+		// Remove member, from the subvaults too
+		vaultCfg.Members = append(vaultCfg.Members[:memberIndex], vaultCfg.Members[memberIndex+1:]...)
+		if emptied := vaultCfg.PruneSubvaults(); len(emptied) > 0 {
+			return fmt.Errorf("cannot remove the last member of subvault %s. Remove the subvault first with: secrets-cli vault subvault %s %s --remove", emptied[0], vaultName, emptied[0])
+		}
+		vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+		if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to save vault config: %w", err)
+		}
+
+		// Re-encrypt secrets without removed member
+		if err := reencryptVault(p, secretsDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to re-encrypt secrets: %w", err)
+		}
+
+		fmt.Printf("✓ Removed %s from vault %s\n", memberEmail, vaultName)
+		recordChange("remove %s from %s", memberEmail, vaultName)
+		fmt.Printf("✓ Re-encrypted %d secret(s)\n", countListed(p))
+
+		// Re-encryption doesn't revoke values the member has already seen.
+		// Only the password line is regenerated; other fields are kept.
+		for _, secret := range toRotate {
+			value, err := secretgen.Generate(rotatePolicy)
+			if err != nil {
+				return err
+			}
+			current, err := p.Show(secret)
+			if err != nil {
+				return fmt.Errorf("failed to rotate %s: %w", secret, err)
+			}
+			if err := p.Insert(secret, replacePassword(current, value)); err != nil {
+				return fmt.Errorf("failed to rotate %s: %w", secret, err)
+			}
+		}
+		if rotateGlob != "" {
+			fmt.Printf("✓ Rotated %d secret(s) matching %s. Update the services that use them.\n", len(toRotate), rotateGlob)
+			recordChange("rotate %s/%s", vaultName, rotateGlob)
+		}
+
+		rotated := make(map[string]bool, len(toRotate))
+		for _, secret := range toRotate {
+			rotated[secret] = true
+		}
+		var remaining []string
+		for _, secret := range exposed {
+			if !rotated[secret] {
+				remaining = append(remaining, secret)
+			}
+		}
+		if len(remaining) > 0 {
+			fmt.Printf("\n⚠ %s could read %d secret(s) that still have their old values:\n", memberEmail, len(remaining))
+			for _, secret := range remaining {
+				fmt.Printf("  %s\n", secret)
+			}
+			fmt.Printf("Consider rotating them, e.g. with: secrets-cli set %s <secret> --generate\n", vaultName)
 		}
+
+		return nil
+	})
+}
+
+func runVaultSetRole(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName, memberEmail, role := args[0], args[1], args[2]
+
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+	if err := config.ValidateRole(role); err != nil {
+		return validationErrorf("%w", err)
+	}
+
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
+	if err := checkWriteAccess(secretsDir, vaultName, email); err != nil {
+		return err
+	}
+
+	return config.WithVaultLock(vaultDir, func() error {
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config: %w", err)
+		}
+		if vaultCfg.Archived {
+			return archivedError(vaultName)
+		}
+		if !isVaultMember(vaultCfg, memberEmail) {
+			return notFoundErrorf("%s is not a member of %s", memberEmail, vaultName)
+		}
+
+		if vaultCfg.MemberRole(memberEmail) == role {
+			fmt.Printf("%s already has the %s role in vault %s\n", memberEmail, role, vaultName)
+			return nil
+		}
+		if writers := vaultCfg.Writers(); role == config.RoleRead && len(writers) == 1 && strings.EqualFold(writers[0], memberEmail) {
+			return fmt.Errorf("cannot make the last read-write member of %s read-only", vaultName)
+		}
+		if role == config.RoleRead && strings.EqualFold(vaultCfg.Owner, memberEmail) {
+			return fmt.Errorf("cannot make the owner of %s read-only. Transfer ownership first with: secrets-cli vault transfer-ownership %s <email>", vaultName, vaultName)
+		}
 
-	// Check is a member
-	memberIndex := -1
-	for i, m := range vaultCfg.Members {
-		if m == memberEmail {
-			memberIndex = i
-			break
+		vaultCfg.SetMemberRole(memberEmail, role)
+		vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to save vault config: %w", err)
 		}
+
+		fmt.Printf("✓ Set role of %s in vault %s to %s\n", memberEmail, vaultName, role)
+		recordChange("set role of %s in %s to %s", memberEmail, vaultName, role)
+		return nil
+	})
+}
+
+// ownerOnlyError denies an action reserved to the vault's owner
+func ownerOnlyError(vaultCfg *config.VaultConfig, vaultName, action string) error {
+	if vaultCfg.Owner == "" {
+		return accessDeniedErrorf("access denied: you are not a member of vault %s", vaultName)
 	}
-	if memberIndex == -1 {
-		return fmt.Errorf("%s is not a member of %s", memberEmail, vaultName)
+	return accessDeniedErrorf("access denied: only the owner of vault %s (%s) can %s", vaultName, vaultCfg.Owner, action)
+}
+
+func runVaultTransferOwnership(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	email := GetUserEmail()
+	vaultName, newOwner := args[0], args[1]
+
+	if err := validateName(vaultName); err != nil {
+		return err
+	}
+	if err := validateEmail(newOwner); err != nil {
+		return err
 	}
 
-	// Cannot remove last member
-	if len(vaultCfg.Members) == 1 {
-		return fmt.Errorf("cannot remove the last member from a vault")
+	vaultDir := config.GetVaultDir(secretsDir, vaultName)
+	if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+		return notFoundErrorf("vault not found: %s", vaultName)
 	}
 
-	// Remove member
-	vaultCfg.Members = append(vaultCfg.Members[:memberIndex], vaultCfg.Members[memberIndex+1:]...)
-	vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	return config.WithVaultLock(vaultDir, func() error {
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config: %w", err)
+		}
+		if email != "" && !vaultCfg.IsOwner(email) {
+			return ownerOnlyError(vaultCfg, vaultName, "transfer it")
+		}
+		if vaultCfg.Archived {
+			return archivedError(vaultName)
+		}
+		if !isVaultMember(vaultCfg, newOwner) {
+			return notFoundErrorf("%s is not a member of %s. Add them first with: secrets-cli vault add-member %s %s", newOwner, vaultName, vaultName, newOwner)
+		}
+		if vaultCfg.MemberRole(newOwner) == config.RoleRead {
+			return validationErrorf("%s has read-only access to %s. Give them read-write access first with: secrets-cli vault set-role %s %s read-write", newOwner, vaultName, vaultName, newOwner)
+		}
+		if strings.EqualFold(vaultCfg.Owner, newOwner) {
+			fmt.Printf("%s already owns vault %s\n", newOwner, vaultName)
+			return nil
+		}
 
-	if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
-		return fmt.Errorf("failed to save vault config: %w", err)
+		// Store the email as it is spelled in the members list
+		for _, member := range vaultCfg.Members {
+			if strings.EqualFold(member, newOwner) {
+				vaultCfg.Owner = member
+			}
+		}
+		vaultCfg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		if err := config.SaveVaultConfig(vaultDir, vaultCfg); err != nil {
+			return fmt.Errorf("failed to save vault config: %w", err)
+		}
+
+		fmt.Printf("✓ %s now owns vault %s\n", vaultCfg.Owner, vaultName)
+		recordChange("transfer %s to %s", vaultName, vaultCfg.Owner)
+		return nil
+	})
+}
+
+func runVaultMembersDiff(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+	vaultA, vaultB := args[0], args[1]
+
+	if vaultA == vaultB {
+		return validationErrorf("cannot compare vault %s with itself", vaultA)
 	}
 
-	// Re-encrypt secrets without removed member
-	storeDir := filepath.Join(vaultDir, ".password-store")
-	p := pass.New(storeDir)
-	if err := p.ReInit(vaultCfg.Members); err != nil {
-		return fmt.Errorf("failed to re-encrypt secrets: %w", err)
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	var members [2][]string
+	for i, vaultName := range []string{vaultA, vaultB} {
+		if err := validateName(vaultName); err != nil {
+			return err
+		}
+		vaultDir := config.GetVaultDir(secretsDir, vaultName)
+		if _, err := os.Stat(vaultDir); os.IsNotExist(err) {
+			return notFoundErrorf("vault not found: %s", vaultName)
+		}
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			return fmt.Errorf("failed to load vault config for %s: %w", vaultName, err)
+		}
+		members[i] = vaultCfg.Members
 	}
 
-	fmt.Printf("✓ Removed %s from vault %s\n", memberEmail, vaultName)
-	fmt.Printf("✓ Re-encrypted %d secret(s)\n", countSecrets(storeDir))
+	onlyA, onlyB, common := diffMembers(members[0], members[1])
+
+	if membersDiffJSON {
+		out := map[string][]string{
+			"only_" + vaultA: nonNil(onlyA),
+			"only_" + vaultB: nonNil(onlyB),
+			"common":         nonNil(common),
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printList := func(title string, list []string) {
+		fmt.Printf("%s (%d):\n", title, len(list))
+		if len(list) == 0 {
+			fmt.Println("  (none)")
+		}
+		for _, m := range list {
+			fmt.Printf("  %s\n", m)
+		}
+	}
+	printList("Only in "+vaultA, onlyA)
+	fmt.Println()
+	printList("Only in "+vaultB, onlyB)
+	fmt.Println()
+	printList("In both", common)
 
 	return nil
 }
 
+// diffMembers compares two member lists case-insensitively, returning members
+// only in a, only in b, and in both (using a's spelling), each sorted
+func diffMembers(a, b []string) (onlyA, onlyB, common []string) {
+	inB := make(map[string]bool, len(b))
+	for _, m := range b {
+		inB[strings.ToLower(m)] = true
+	}
+	inA := make(map[string]bool, len(a))
+	for _, m := range a {
+		inA[strings.ToLower(m)] = true
+		if inB[strings.ToLower(m)] {
+			common = append(common, m)
+		} else {
+			onlyA = append(onlyA, m)
+		}
+	}
+	for _, m := range b {
+		if !inA[strings.ToLower(m)] {
+			onlyB = append(onlyB, m)
+		}
+	}
+
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	sort.Strings(common)
+	return onlyA, onlyB, common
+}
+
+// nonNil returns an empty slice instead of nil so JSON encodes [] not null
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// printReencryptPlan describes what re-encrypting a store for recipients would
+// change, for --dry-run
+func printReencryptPlan(storeDir string, recipients []string) {
+	p := newPass(storeDir)
+	current, _ := p.GetGPGIDs()
+	if resolved, err := p.ResolveGPGIDs(recipients); err == nil {
+		recipients = resolved
+	}
+
+	fmt.Printf("  Secrets to re-encrypt: %d\n", countSecrets(storeDir))
+	fmt.Println("  Recipients:")
+	for _, r := range recipients {
+		marker := "+"
+		for _, c := range current {
+			if strings.EqualFold(c, r) {
+				marker = " "
+				break
+			}
+		}
+		fmt.Printf("    %s %s\n", marker, r)
+	}
+	for _, c := range current {
+		kept := false
+		for _, r := range recipients {
+			if strings.EqualFold(c, r) {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			fmt.Printf("    - %s\n", c)
+		}
+	}
+	fmt.Println("Dry run: no changes made")
+}
+
+// countListed returns the number of secrets in p's store, reusing its last
+// listing
+func countListed(p *pass.Pass) int {
+	secrets, _ := p.ListCached()
+	return len(secrets)
+}
+
 func countSecrets(storeDir string) int {
-	p := pass.New(storeDir)
+	p := newPass(storeDir)
 	secrets, _ := p.List()
 	return len(secrets)
 }
 
+const (
+	// parallelReencryptThreshold is the number of secrets above which a
+	// vault is re-encrypted in parallel even without --parallel
+	parallelReencryptThreshold = 50
+	// reencryptWorkers is the number of secrets re-encrypted at a time
+	reencryptWorkers = 4
+)
+
+// reencryptVault re-encrypts a vault's store for its recipients, then each
+// subvault whose .gpg-id is out of date for its own. An empty store has
+// nothing to re-encrypt, so only its .gpg-id is rewritten. Backup
+// recipients and recovery keys missing from the keyring are imported from
+// keys/ first.
+func reencryptVault(p *pass.Pass, secretsDir string, vaultCfg *config.VaultConfig) error {
+	recipients := vaultRecipients(secretsDir, vaultCfg)
+	importRecipientKeys(newGPG(), secretsDir, recipients)
+	secrets, _ := p.ListCached()
+	switch {
+	case len(secrets) == 0:
+		if err := p.SetRecipients(recipients); err != nil {
+			return err
+		}
+	case reencryptParallel || len(secrets) > parallelReencryptThreshold:
+		if err := p.ReInitParallel(recipients, reencryptWorkers); err != nil {
+			return err
+		}
+	default:
+		if err := p.ReInit(recipients); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range vaultCfg.SubvaultPaths() {
+		ids, err := p.ResolveGPGIDs(subvaultRecipients(secretsDir, vaultCfg, path))
+		if err != nil {
+			return err
+		}
+		current, err := p.PathGPGIDs(path)
+		if err == nil && sameIDs(current, ids) {
+			continue
+		}
+		if err := p.InitPath(path, ids); err != nil {
+			return fmt.Errorf("failed to re-encrypt subvault %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// subvaultRecipients returns the GPG recipients for a subvault's secrets: its
+// members, then the vault's recovery keys and the store's backup recipients
+func subvaultRecipients(secretsDir string, vaultCfg *config.VaultConfig, path string) []string {
+	restricted := *vaultCfg
+	restricted.Members = vaultCfg.Subvaults[path]
+	return vaultRecipients(secretsDir, &restricted)
+}
+
+// secretRecipients returns the GPG recipients a secret must be encrypted for,
+// those of the subvault it belongs to or else the vault's
+func secretRecipients(secretsDir string, vaultCfg *config.VaultConfig, secret string) []string {
+	if path, ok := vaultCfg.SubvaultFor(secret); ok {
+		return subvaultRecipients(secretsDir, vaultCfg, path)
+	}
+	return vaultRecipients(secretsDir, vaultCfg)
+}
+
+// vaultRecipients returns the GPG recipients for a vault's secrets: its
+// members, then its recovery keys and the store's backup recipients that
+// are not members already
+func vaultRecipients(secretsDir string, vaultCfg *config.VaultConfig) []string {
+	extras := append([]string{}, vaultCfg.RecoveryKeys...)
+	if cfg, err := config.LoadConfig(secretsDir); err == nil {
+		extras = append(extras, cfg.BackupRecipients...)
+	}
+
+	recipients := append([]string{}, vaultCfg.Members...)
+	for _, extra := range extras {
+		found := false
+		for _, r := range recipients {
+			if strings.EqualFold(r, extra) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			recipients = append(recipients, extra)
+		}
+	}
+	return recipients
+}
+
+// checkReadAccess enforces vault membership for read-only commands, unless the
+// store is configured to leave read access control to GPG decryption alone
+func checkReadAccess(secretsDir, vaultName, email string) error {
+	if cfg, err := config.LoadConfig(secretsDir); err == nil && cfg.AccessControl == config.AccessControlGPGOnly {
+		return nil
+	}
+	if !hasVaultAccess(secretsDir, vaultName, email) && email != "" {
+		// A vault.yaml that is refused, say for its gpg_opts, is not a
+		// matter of membership
+		if _, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName)); err != nil {
+			return err
+		}
+		return accessDeniedErrorf("Access denied: you are not a member of vault %s", vaultName)
+	}
+	return nil
+}
+
+// checkWriteAccess enforces vault membership with the read-write role for
+// commands that change a vault. Roles are a CLI policy only: read-only
+// members are still GPG recipients and can decrypt every secret.
+func checkWriteAccess(secretsDir, vaultName, email string) error {
+	if email == "" || hasWriteAccess(secretsDir, vaultName, email) {
+		return nil
+	}
+	if hasVaultAccess(secretsDir, vaultName, email) {
+		return accessDeniedErrorf("Access denied: you have read-only access to vault %s", vaultName)
+	}
+	if _, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName)); err != nil {
+		return err
+	}
+	return accessDeniedErrorf("Access denied: you are not a member of vault %s", vaultName)
+}
+
+// checkNotArchived refuses writes to an archived vault
+func checkNotArchived(secretsDir, vaultName string) error {
+	vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+	if err != nil {
+		return fmt.Errorf("failed to load vault config: %w", err)
+	}
+	if vaultCfg.Archived {
+		return archivedError(vaultName)
+	}
+	return nil
+}
+
+func archivedError(vaultName string) error {
+	return accessDeniedErrorf("vault %s is archived and read-only. Restore it with: secrets-cli vault unarchive %s", vaultName, vaultName)
+}
+
 // hasVaultAccess checks if an email has access to a vault
 func hasVaultAccess(secretsDir, vaultName, email string) bool {
 	if email == "" {
@@ -464,6 +1596,42 @@ func hasVaultAccess(secretsDir, vaultName, email string) bool {
 	if err != nil {
 		return false
 	}
+	return isVaultMember(vaultCfg, email)
+}
+
+// hasWriteAccess checks if an email is a read-write member of a vault
+func hasWriteAccess(secretsDir, vaultName, email string) bool {
+	if email == "" {
+		return false
+	}
+	vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+	if err != nil {
+		return false
+	}
+	return isVaultMember(vaultCfg, email) && vaultCfg.MemberRole(email) == config.RoleReadWrite
+}
+
+// replacePassword returns a secret's content with its first line, the
+// password, replaced and any following field lines kept
+func replacePassword(content, password string) string {
+	if _, fields, ok := strings.Cut(content, "\n"); ok {
+		return password + "\n" + fields
+	}
+	return password
+}
+
+// matchSecretGlob matches a secret name against a path.Match pattern, where
+// "*" does not cross "/". The pattern "**" matches every secret.
+func matchSecretGlob(pattern, name string) bool {
+	if pattern == "**" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// isVaultMember reports whether email is a member of the vault, ignoring case
+func isVaultMember(vaultCfg *config.VaultConfig, email string) bool {
 	for _, member := range vaultCfg.Members {
 		if strings.EqualFold(member, email) {
 			return true
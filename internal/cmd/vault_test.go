@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+)
+
+func TestDiffMembers(t *testing.T) {
+	a := []string{"carol@example.com", "Alice@example.com", "bob@example.com"}
+	b := []string{"alice@example.com", "dave@example.com"}
+
+	onlyA, onlyB, common := diffMembers(a, b)
+
+	if want := []string{"bob@example.com", "carol@example.com"}; !reflect.DeepEqual(onlyA, want) {
+		t.Errorf("onlyA = %v, want %v", onlyA, want)
+	}
+	if want := []string{"dave@example.com"}; !reflect.DeepEqual(onlyB, want) {
+		t.Errorf("onlyB = %v, want %v", onlyB, want)
+	}
+	if want := []string{"Alice@example.com"}; !reflect.DeepEqual(common, want) {
+		t.Errorf("common = %v, want %v", common, want)
+	}
+}
+
+func TestIsVaultMember(t *testing.T) {
+	cfg := &config.VaultConfig{Members: []string{"Alice@example.com", "bob@example.com"}}
+
+	for email, want := range map[string]bool{
+		"alice@example.com": true,
+		"BOB@EXAMPLE.COM":   true,
+		"carol@example.com": false,
+		"":                  false,
+	} {
+		if got := isVaultMember(cfg, email); got != want {
+			t.Errorf("isVaultMember(%q) = %v, want %v", email, got, want)
+		}
+	}
+}
+
+func TestReplacePassword(t *testing.T) {
+	tests := []struct{ content, want string }{
+		{"old", "new"},
+		{"", "new"},
+		{"old\nusername: admin\nurl: db.internal", "new\nusername: admin\nurl: db.internal"},
+		{"\nusername: admin", "new\nusername: admin"},
+	}
+	for _, tt := range tests {
+		if got := replacePassword(tt.content, "new"); got != tt.want {
+			t.Errorf("replacePassword(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestMatchSecretGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"db/*", "db/password", true},
+		{"db/*", "db/replica/user", false},
+		{"*", "db/password", false},
+		{"**", "db/replica/user", true},
+		{"api/key", "api/key", true},
+	}
+	for _, tt := range tests {
+		if got := matchSecretGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchSecretGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseGPGIDsFile(t *testing.T) {
+	const fpr = "0123456789ABCDEF0123456789ABCDEF01234567"
+	const other = "89ABCDEF0123456789ABCDEF0123456789ABCDEF"
+	emailFor := func(fingerprint string) string {
+		if fingerprint == other {
+			return "carol@example.com"
+		}
+		return ""
+	}
+
+	data := "# team\n\nalice@example.com\n" + fpr + " bob@example.com\n" + other + "\nAlice@example.com\n"
+	got, err := parseGPGIDsFile(data, emailFor)
+	if err != nil {
+		t.Fatalf("parseGPGIDsFile() error = %v", err)
+	}
+	want := []gpgIDEntry{
+		{Email: "alice@example.com", ID: "alice@example.com"},
+		{Email: "bob@example.com", ID: fpr},
+		{Email: "carol@example.com", ID: other},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGPGIDsFile() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"", "not-an-email\n", fpr + "\n", "a@x.com b@x.com\n"} {
+		if _, err := parseGPGIDsFile(bad, emailFor); ExitCode(err) != ExitValidation {
+			t.Errorf("parseGPGIDsFile(%q) error = %v, want a validation error", bad, err)
+		}
+	}
+}
+
+// generateTestKey creates an unprotected key for email in $GNUPGHOME
+func generateTestKey(t *testing.T, email string) {
+	t.Helper()
+	out, err := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", email, "ed25519", "default", "never").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to generate a key for %s: %v\n%s", email, err, out)
+	}
+}
+
+// fakePass puts a pass on PATH that stores values unencrypted: init writes
+// .gpg-id, insert writes <name>.gpg and show prints it. insert fails for
+// names containing "fail", and whenever the file $FAKE_PASS_LOCK does not
+// exist.
+func fakePass(t *testing.T) {
+	t.Helper()
+	binDir := t.TempDir()
+	script := `#!/bin/sh
+cmd=$1
+for name; do :; done
+case $cmd in
+init)
+	shift 2
+	printf '%s\n' "$@" > "$PASSWORD_STORE_DIR/.gpg-id" ;;
+insert)
+	case $name in *fail*) echo "insert failed" >&2; exit 1 ;; esac
+	if [ -n "$FAKE_PASS_LOCK" ] && [ ! -e "$FAKE_PASS_LOCK" ]; then
+		echo "vault not locked" >&2
+		exit 1
+	fi
+	mkdir -p "$(dirname "$PASSWORD_STORE_DIR/$name")"
+	cat > "$PASSWORD_STORE_DIR/$name.gpg" ;;
+show)
+	cat "$PASSWORD_STORE_DIR/$name.gpg" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRunVaultCreateFrom(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	gnupgHome := t.TempDir()
+	t.Setenv("GNUPGHOME", gnupgHome)
+	t.Cleanup(func() { exec.Command("gpgconf", "--kill", "gpg-agent").Run() })
+	generateTestKey(t, "alice@example.com")
+	generateTestKey(t, "bob@example.com")
+	fakePass(t)
+
+	secretsDir := t.TempDir()
+	t.Setenv("SECRETS_DIR", secretsDir)
+	t.Setenv("USER_EMAIL", "alice@example.com")
+	if err := config.SaveConfig(secretsDir, &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	template := &config.VaultConfig{Name: "dev", Members: []string{"alice@example.com", "bob@example.com"}}
+	template.SetMemberRole("bob@example.com", config.RoleRead)
+	templateStore := config.GetStoreDir(secretsDir, "dev")
+	for _, file := range []string{"db/password.gpg", "api/token.gpg"} {
+		path := filepath.Join(templateStore, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("real value"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.SaveVaultConfig(config.GetVaultDir(secretsDir, "dev"), template); err != nil {
+		t.Fatal(err)
+	}
+
+	vaultFrom, vaultMembersFrom = "dev", "dev"
+	defer func() { vaultFrom, vaultMembersFrom = "", "" }()
+	t.Setenv("FAKE_PASS_LOCK", filepath.Join(config.GetVaultDir(secretsDir, "staging"), ".lock"))
+	if _, err := captureStdout(t, func() error { return runVaultCreate(vaultCreateCmd, []string{"staging"}) }); err != nil {
+		t.Fatalf("runVaultCreate() error = %v", err)
+	}
+
+	storeDir := config.GetStoreDir(secretsDir, "staging")
+	for _, name := range []string{"db/password", "api/token"} {
+		data, err := os.ReadFile(filepath.Join(storeDir, name+".gpg"))
+		if err != nil || string(data) != templatePlaceholder {
+			t.Errorf("placeholder %s = %q, %v, want %q", name, data, err, templatePlaceholder)
+		}
+	}
+	vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, "staging"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vaultCfg.Members, []string{"alice@example.com", "bob@example.com"}) {
+		t.Errorf("members = %v, want them inherited from dev", vaultCfg.Members)
+	}
+	if role := vaultCfg.MemberRole("bob@example.com"); role != config.RoleRead {
+		t.Errorf("bob's role = %q, want %q", role, config.RoleRead)
+	}
+	if gpgIDs, _ := os.ReadFile(filepath.Join(storeDir, ".gpg-id")); string(gpgIDs) != "alice@example.com\nbob@example.com\n" {
+		t.Errorf(".gpg-id = %q", gpgIDs)
+	}
+
+	// A placeholder that cannot be created leaves no vault behind
+	path := filepath.Join(templateStore, "fail.gpg")
+	if err := os.WriteFile(path, []byte("real value"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FAKE_PASS_LOCK", "")
+	if _, err := captureStdout(t, func() error { return runVaultCreate(vaultCreateCmd, []string{"qa"}) }); err == nil || !strings.Contains(err.Error(), "failed to create placeholder fail") {
+		t.Fatalf("runVaultCreate() with a failing placeholder: err = %v", err)
+	}
+	for _, dir := range []string{config.GetVaultDir(secretsDir, "qa"), config.GetStoreDir(secretsDir, "qa")} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("%s left behind: %v", dir, err)
+		}
+	}
+}
+
+func TestReencryptVaultImportsBackupKeys(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	secretsDir := t.TempDir()
+	keysDir := config.GetKeysDir(secretsDir)
+	if err := os.MkdirAll(keysDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// The backup key is only in keys/, not in the keyring of whoever
+	// changes the vault
+	t.Setenv("GNUPGHOME", t.TempDir())
+	generateTestKey(t, "backup@example.com")
+	exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+	armored, err := exec.Command("gpg", "--armor", "--export", "backup@example.com").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(keysDir, "backup@example.com.asc"), armored, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GNUPGHOME", t.TempDir())
+	t.Cleanup(func() { exec.Command("gpgconf", "--kill", "gpg-agent").Run() })
+
+	if err := config.SaveConfig(secretsDir, &config.Config{BackupRecipients: []string{"backup@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	storeDir := config.GetStoreDir(secretsDir, "dev")
+	if err := os.MkdirAll(storeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	vaultCfg := &config.VaultConfig{Name: "dev", Members: []string{"alice@example.com"}}
+	if err := reencryptVault(newPass(storeDir), secretsDir, vaultCfg); err != nil {
+		t.Fatalf("reencryptVault() error = %v", err)
+	}
+	if !newGPG().KeyExists("backup@example.com") {
+		t.Error("the backup recipient's key was not imported from keys/")
+	}
+}
+
+func TestRunVaultArchive(t *testing.T) {
+	secretsDir := t.TempDir()
+	t.Setenv("SECRETS_DIR", secretsDir)
+	t.Setenv("USER_EMAIL", "alice@example.com")
+	if err := config.SaveConfig(secretsDir, &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	storeDir := config.GetStoreDir(secretsDir, "legacy")
+	if err := os.MkdirAll(storeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "db.gpg"), []byte("not encrypted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	vaultDir := config.GetVaultDir(secretsDir, "legacy")
+	if err := config.SaveVaultConfig(vaultDir, &config.VaultConfig{Name: "legacy", Members: []string{"alice@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	archived := func() bool {
+		vaultCfg, err := config.LoadVaultConfig(vaultDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vaultCfg.Archived
+	}
+
+	// Only members may archive a vault
+	t.Setenv("USER_EMAIL", "mallory@example.com")
+	if err := runVaultArchive(vaultArchiveCmd, []string{"legacy"}); ExitCode(err) != ExitAccessDenied {
+		t.Errorf("archive by a non-member: err = %v, want access denied", err)
+	}
+	t.Setenv("USER_EMAIL", "alice@example.com")
+
+	out, err := captureStdout(t, func() error { return runVaultArchive(vaultArchiveCmd, []string{"legacy"}) })
+	if err != nil || !archived() || !strings.Contains(out, "✓ Archived vault: legacy") {
+		t.Fatalf("archive: out = %q, err = %v, archived = %v", out, err, archived())
+	}
+	if out, err := captureStdout(t, func() error { return runVaultArchive(vaultArchiveCmd, []string{"legacy"}) }); err != nil || !strings.Contains(out, "already archived") {
+		t.Errorf("archive twice: out = %q, err = %v", out, err)
+	}
+
+	// Hidden from vault list unless --all is given
+	out, _ = captureStdout(t, func() error { return runVaultList(vaultListCmd, nil) })
+	if strings.Contains(out, "  legacy") || !strings.Contains(out, "1 archived") {
+		t.Errorf("vault list = %q, want legacy hidden", out)
+	}
+	setFlag(t, vaultListCmd, "all", "true")
+	out, _ = captureStdout(t, func() error { return runVaultList(vaultListCmd, nil) })
+	if !strings.Contains(out, "legacy ✓ [archived]") {
+		t.Errorf("vault list --all = %q, want legacy marked archived", out)
+	}
+
+	// Read-only: writes are refused, reads are not
+	if err := runSet(setCmd, []string{"legacy", "db"}); ExitCode(err) != ExitAccessDenied || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("set: err = %v, want the vault to be read-only", err)
+	}
+	if err := runDelete(deleteCmd, []string{"legacy", "db"}); ExitCode(err) != ExitAccessDenied || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("delete: err = %v, want the vault to be read-only", err)
+	}
+	if err := runVaultAddMember(vaultAddMemberCmd, []string{"legacy", "bob@example.com"}); ExitCode(err) != ExitAccessDenied || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("add-member: err = %v, want the vault to be read-only", err)
+	}
+	if out, err := captureStdout(t, func() error { return runList(listCmd, []string{"legacy"}) }); err != nil || !strings.Contains(out, "db") {
+		t.Errorf("list: out = %q, err = %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, "db.gpg")); err != nil {
+		t.Errorf("db was removed from the archived vault: %v", err)
+	}
+
+	out, err = captureStdout(t, func() error { return runVaultArchive(vaultUnarchiveCmd, []string{"legacy"}) })
+	if err != nil || archived() || !strings.Contains(out, "✓ Unarchived vault: legacy") {
+		t.Fatalf("unarchive: out = %q, err = %v, archived = %v", out, err, archived())
+	}
+	if err := checkNotArchived(secretsDir, "legacy"); err != nil {
+		t.Errorf("writes after unarchive: %v", err)
+	}
+}
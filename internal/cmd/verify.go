@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NuevaNext/secrets-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [vault...]",
+	Short: "Check that the secrets store is consistent, for CI",
+	Long: `Check every vault, or the given vaults, and exit nonzero if anything is
+inconsistent. Meant as a merge gate in CI or a pre-commit hook.
+
+Checks:
+  missing key       a member without a keys/<email>.asc file
+  not recipient     a member, recovery key or backup recipient missing from .gpg-id
+  stale recipient   a .gpg-id entry that is no longer expected
+  missing gpg id    a password store without a .gpg-id
+  wrong recipients  a secret not encrypted for exactly the vault's recipients
+  plaintext file    an unencrypted file inside a password store
+
+Recipients are read from the encrypted files, so no private key is needed.
+Public keys from keys/ are imported into the keyring to match them; a
+vault whose recipients do not all have a key is not checked further. With
+--loose only the number of recipients is compared.
+
+Every issue is reported, not only the first. Use --format json for tooling.
+
+Examples:
+  secrets-cli verify
+  secrets-cli verify production
+  secrets-cli verify --format json`,
+	RunE: runVerify,
+}
+
+var verifyFormat string
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVar(&verifyFormat, "format", "text", "Output format: text, json")
+}
+
+// verifyReport is the result of verify across the checked vaults
+type verifyReport struct {
+	OK     bool                `json:"ok"`
+	Vaults []string            `json:"vaults"`
+	Issues []config.VaultIssue `json:"issues"`
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	secretsDir := GetSecretsDir()
+
+	if verifyFormat != "text" && verifyFormat != "json" {
+		return fmt.Errorf("unknown format: %s (use text or json)", verifyFormat)
+	}
+
+	if _, err := os.Stat(secretsDir); os.IsNotExist(err) {
+		return notFoundErrorf("✗ Secrets directory not found: %s. Run 'secrets-cli init' first", secretsDir)
+	}
+
+	vaults := args
+	if len(vaults) == 0 {
+		var err error
+		if vaults, err = config.ListVaults(secretsDir); err != nil {
+			return err
+		}
+	}
+	for _, vaultName := range vaults {
+		if err := validateName(vaultName); err != nil {
+			return err
+		}
+		if _, err := os.Stat(config.GetVaultDir(secretsDir, vaultName)); os.IsNotExist(err) {
+			return notFoundErrorf("vault not found: %s", vaultName)
+		}
+	}
+
+	report := verifyReport{Vaults: vaults, Issues: []config.VaultIssue{}}
+	for _, vaultName := range vaults {
+		issues, err := verifyVault(secretsDir, vaultName)
+		if err != nil {
+			return fmt.Errorf("failed to verify vault %s: %w", vaultName, err)
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+	report.OK = len(report.Issues) == 0
+
+	if verifyFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		byVault := map[string][]config.VaultIssue{}
+		for _, issue := range report.Issues {
+			byVault[issue.Vault] = append(byVault[issue.Vault], issue)
+		}
+		for _, vaultName := range vaults {
+			if len(byVault[vaultName]) == 0 {
+				fmt.Printf("✓ %s\n", vaultName)
+				continue
+			}
+			fmt.Printf("✗ %s\n", vaultName)
+			for _, issue := range byVault[vaultName] {
+				fmt.Printf("  %s: %s\n", strings.ReplaceAll(issue.Kind, "_", " "), issue.Message)
+			}
+		}
+	}
+
+	if !report.OK {
+		return fmt.Errorf("verification failed: %d issue(s) in %d vault(s)", len(report.Issues), countIssueVaults(report.Issues))
+	}
+	if verifyFormat == "text" {
+		fmt.Printf("\n✓ Verified %d vault(s)\n", len(vaults))
+	}
+	return nil
+}
+
+// verifyVault runs every verify check on one vault
+func verifyVault(secretsDir, vaultName string) ([]config.VaultIssue, error) {
+	issues, err := config.ValidateVault(secretsDir, vaultName)
+	if err != nil {
+		return nil, err
+	}
+	add := func(kind, format string, args ...interface{}) {
+		issues = append(issues, config.VaultIssue{
+			Vault:   vaultName,
+			Kind:    kind,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	storeDir := config.GetStoreDir(secretsDir, vaultName)
+	plaintext, err := config.FindPlaintextFiles(storeDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range plaintext {
+		add(config.IssuePlaintextFile, "%s is not encrypted; remove it from the password store", filepath.Join(storeDir, file))
+	}
+
+	vaultCfg, err := config.LoadVaultConfig(config.GetVaultDir(secretsDir, vaultName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault config: %w", err)
+	}
+	recipients := vaultRecipients(secretsDir, vaultCfg)
+	g := newGPG()
+	importRecipientKeys(g, secretsDir, recipients)
+
+	// Encryption can only be checked once every recipient has a key
+	reported := map[string]bool{}
+	for _, issue := range issues {
+		if issue.Kind == config.IssueMissingKey {
+			reported[strings.ToLower(issue.Email)] = true
+		}
+	}
+	complete := true
+	for _, recipient := range recipients {
+		if g.KeyExists(recipient) {
+			continue
+		}
+		complete = false
+		if !reported[strings.ToLower(recipient)] {
+			issues = append(issues, config.VaultIssue{
+				Vault:   vaultName,
+				Kind:    config.IssueMissingKey,
+				Email:   recipient,
+				Message: fmt.Sprintf("no public key for %s in keys/ or the keyring", recipient),
+			})
+		}
+	}
+	if !complete {
+		return issues, nil
+	}
+
+	// Secrets in a subvault are checked against its recipients instead
+	p := newPass(storeDir)
+	expected := map[string][]string{}
+	for _, path := range append([]string{""}, vaultCfg.SubvaultPaths()...) {
+		ids := recipients
+		if path != "" {
+			ids = subvaultRecipients(secretsDir, vaultCfg, path)
+		}
+		if expected[path], err = p.ResolveGPGIDs(ids); err != nil {
+			add(config.IssueWrongRecipients, "cannot resolve the recipients of %s: %s", vaultName, strings.Join(strings.Fields(err.Error()), " "))
+			return issues, nil
+		}
+	}
+	secrets, _ := p.List()
+	for _, secret := range secrets {
+		path, _ := vaultCfg.SubvaultFor(secret)
+		if err := p.VerifyEncryption(secret, expected[path]); err != nil {
+			add(config.IssueWrongRecipients, "%v; run 'secrets-cli sync %s'", err, vaultName)
+		}
+	}
+
+	return issues, nil
+}
+
+// countIssueVaults returns the number of distinct vaults with issues
+func countIssueVaults(issues []config.VaultIssue) int {
+	vaults := map[string]bool{}
+	for _, issue := range issues {
+		vaults[issue.Vault] = true
+	}
+	return len(vaults)
+}
@@ -5,23 +5,109 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Access control modes for read commands
+const (
+	// AccessControlMembership requires the user to be a vault member (default)
+	AccessControlMembership = "membership"
+	// AccessControlGPGOnly skips membership checks on reads and relies on
+	// GPG decryption alone to decide who can read a secret
+	AccessControlGPGOnly = "gpg-only"
+)
+
+// TrustModelAlways is the default gpg trust model for encryption. Team keys
+// are vetted by being committed to keys/, so requiring web-of-trust
+// signatures on them would only make every member sign every other key.
+const TrustModelAlways = "always"
+
+// trustModels are the values gpg accepts for --trust-model
+var trustModels = []string{"always", "direct", "pgp", "classic", "tofu", "tofu+pgp", "auto"}
+
+// Password store layouts
+const (
+	// StoreLayoutPerVault keeps a password store inside each vault directory (default)
+	StoreLayoutPerVault = "per-vault"
+	// StoreLayoutShared maps each vault to a subdirectory of one password
+	// store, with its own .gpg-id per subdirectory
+	StoreLayoutShared = "shared"
+)
+
+// Recipient formats for .gpg-id files
+const (
+	// RecipientFormatEmail lists recipients by email address (default)
+	RecipientFormatEmail = "email"
+	// RecipientFormatFingerprint lists recipients by full key fingerprint,
+	// so secrets are never encrypted for another key with the same email
+	RecipientFormatFingerprint = "fingerprint"
+)
+
 // Config represents the global secrets configuration (.secrets/config.yaml)
 type Config struct {
-	Version string `yaml:"version"`
-	Owner   string `yaml:"owner"`
+	Version       string `yaml:"version" json:"version"`
+	Owner         string `yaml:"owner" json:"owner"`
+	AccessControl string `yaml:"access_control,omitempty" json:"access_control,omitempty"`
+	// DefaultVault is used by commands whose vault argument is omitted
+	DefaultVault string `yaml:"default_vault,omitempty" json:"default_vault,omitempty"`
+	// StoreLayout selects where vault password stores live: per-vault or shared
+	StoreLayout string `yaml:"store_layout,omitempty" json:"store_layout,omitempty"`
+	// SharedStore is the password store used by the shared layout. Relative
+	// paths are resolved against the secrets directory and "~/" against the
+	// home directory (default: password-store).
+	SharedStore string `yaml:"shared_store,omitempty" json:"shared_store,omitempty"`
+	// BackupRecipients are offline break-glass keys that every secret in
+	// every vault is encrypted for. Like recovery keys they grant no CLI
+	// access and are not shown as members.
+	BackupRecipients []string `yaml:"backup_recipients,omitempty" json:"backup_recipients,omitempty"`
+	// RecipientFormat selects how recipients are written to .gpg-id files:
+	// email or fingerprint
+	RecipientFormat string `yaml:"recipient_format,omitempty" json:"recipient_format,omitempty"`
+	// TrustModel is passed to gpg as --trust-model when encrypting (default:
+	// always)
+	TrustModel string `yaml:"trust_model,omitempty" json:"trust_model,omitempty"`
+	// AutoCommit commits the secrets directory after every change, and
+	// AutoPush also pushes it, as with --commit and --push
+	AutoCommit bool `yaml:"auto_commit,omitempty" json:"auto_commit,omitempty"`
+	AutoPush   bool `yaml:"auto_push,omitempty" json:"auto_push,omitempty"`
 }
 
 // VaultConfig represents a vault's configuration (vault.yaml)
 type VaultConfig struct {
-	Name        string   `yaml:"name"`
-	Description string   `yaml:"description,omitempty"`
-	Members     []string `yaml:"members"`
-	CreatedAt   string   `yaml:"created_at"`
-	UpdatedAt   string   `yaml:"updated_at,omitempty"`
+	// Version is the schema version, see CurrentVaultConfigVersion
+	Version     int    `yaml:"version"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// Owner alone may delete the vault or remove the owner from it, see
+	// IsOwner. Vaults created before owners existed have none.
+	Owner string `yaml:"owner,omitempty"`
+	// Members are stored in vault.yaml together with their roles, see
+	// MarshalYAML
+	Members []string `yaml:"-"`
+	// Roles holds the members whose role is not RoleReadWrite, keyed by
+	// lowercased email
+	Roles map[string]string `yaml:"-"`
+	// RecoveryKeys are break-glass recipients that every secret is encrypted
+	// for, independent of membership. They do not grant CLI access.
+	RecoveryKeys []string `yaml:"recovery_keys,omitempty"`
+	CreatedAt    string   `yaml:"created_at"`
+	UpdatedAt    string   `yaml:"updated_at,omitempty"`
+	// Archived vaults are hidden from vault list and read-only
+	Archived bool `yaml:"archived,omitempty"`
+	// Groups records the members each group added with "@group" expanded
+	// to when last applied, so sync can follow later changes to groups.yaml
+	Groups map[string][]string `yaml:"groups,omitempty"`
+	// Subvaults restrict subtrees of the password store to some of the
+	// members, keyed by path. Each has its own .gpg-id, see SubvaultFor.
+	Subvaults map[string][]string `yaml:"subvaults,omitempty"`
+	// GPGOpts are extra gpg options used whenever this vault's secrets
+	// are encrypted, e.g. ["--compress-algo", "none"]. Only the options
+	// in vaultGPGOptions are allowed, see ValidateVaultGPGOpts.
+	GPGOpts []string `yaml:"gpg_opts,omitempty"`
 }
 
 // LoadConfig loads the global config from .secrets/config.yaml
@@ -63,10 +149,26 @@ func LoadVaultConfig(vaultDir string) (*VaultConfig, error) {
 		return nil, fmt.Errorf("failed to read vault config: %w", err)
 	}
 
+	// Unknown fields from newer releases are ignored
 	var cfg VaultConfig
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse vault config: %w", err)
 	}
+	if cfg.Name == "" {
+		cfg.Name = filepath.Base(vaultDir)
+	}
+
+	if err := migrateOnLoad(vaultDir, &cfg); err != nil {
+		return nil, err
+	}
+	if _, err := ValidateVaultGPGOpts(cfg.GPGOpts); err != nil {
+		return nil, fmt.Errorf("invalid gpg_opts in %s: %w", path, err)
+	}
+	for _, subvault := range cfg.SubvaultPaths() {
+		if err := ValidateSubvaultPath(subvault); err != nil {
+			return nil, fmt.Errorf("invalid subvaults in %s: %w", path, err)
+		}
+	}
 
 	return &cfg, nil
 }
@@ -98,12 +200,146 @@ func GetVaultDir(secretsDir, vaultName string) string {
 	return filepath.Join(secretsDir, "vaults", vaultName)
 }
 
+// GetStoreDir returns the path to a vault's password store according to the
+// store layout in config.yaml. Vault configs always stay in GetVaultDir.
+func GetStoreDir(secretsDir, vaultName string) string {
+	cfg, err := LoadConfig(secretsDir)
+	if err != nil {
+		cfg = &Config{}
+	}
+	return StoreDirForLayout(secretsDir, vaultName, cfg.StoreLayout, cfg.SharedStore)
+}
+
+// StoreDirForLayout returns the path to a vault's password store for the
+// given layout and shared store
+func StoreDirForLayout(secretsDir, vaultName, layout, sharedStore string) string {
+	if layout == StoreLayoutShared {
+		return filepath.Join(SharedStoreDir(secretsDir, sharedStore), vaultName)
+	}
+	return filepath.Join(GetVaultDir(secretsDir, vaultName), ".password-store")
+}
+
+// SharedStoreDir resolves the shared password store path
+func SharedStoreDir(secretsDir, sharedStore string) string {
+	switch {
+	case sharedStore == "":
+		return filepath.Join(secretsDir, "password-store")
+	case strings.HasPrefix(sharedStore, "~/"):
+		return ExpandHome(sharedStore)
+	case !filepath.IsAbs(sharedStore):
+		return filepath.Join(secretsDir, sharedStore)
+	}
+	return sharedStore
+}
+
+// ValidateStoreLayout checks that a store layout name is known
+func ValidateStoreLayout(layout string) error {
+	switch layout {
+	case "", StoreLayoutPerVault, StoreLayoutShared:
+		return nil
+	}
+	return fmt.Errorf("unknown store layout: %s (use %s or %s)", layout, StoreLayoutPerVault, StoreLayoutShared)
+}
+
+// ValidateAccessControl checks that an access control mode is known
+func ValidateAccessControl(mode string) error {
+	switch mode {
+	case "", AccessControlMembership, AccessControlGPGOnly:
+		return nil
+	}
+	return fmt.Errorf("unknown access control mode: %s (use %s or %s)", mode, AccessControlMembership, AccessControlGPGOnly)
+}
+
+// ValidateTrustModel checks that gpg knows a trust model
+func ValidateTrustModel(model string) error {
+	if model == "" {
+		return nil
+	}
+	for _, m := range trustModels {
+		if model == m {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown trust model: %s (use %s)", model, strings.Join(trustModels, ", "))
+}
+
+// vaultGPGOptions are the gpg options vault.yaml may set in gpg_opts. They
+// only choose algorithms: vault.yaml is committed, so options that add
+// recipients, change the trust model, the keyring or the output would let
+// anyone who can push redirect where secrets are encrypted to.
+var vaultGPGOptions = map[string]bool{
+	"--cipher-algo":     true,
+	"--compress-algo":   true,
+	"--digest-algo":     true,
+	"--s2k-cipher-algo": true,
+	"--s2k-digest-algo": true,
+	"--s2k-mode":        true,
+	"--s2k-count":       true,
+}
+
+// gpgOptionValue matches an algorithm name or number
+var gpgOptionValue = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ValidateVaultGPGOpts checks the gpg_opts of a vault and returns them as
+// separate words, as pass splits them. Each option takes a value, given
+// as the next word or after "=".
+func ValidateVaultGPGOpts(opts []string) ([]string, error) {
+	var words []string
+	for _, opt := range opts {
+		words = append(words, strings.Fields(opt)...)
+	}
+	for i := 0; i < len(words); i++ {
+		name, value, inline := strings.Cut(words[i], "=")
+		if !vaultGPGOptions[name] {
+			return nil, fmt.Errorf("gpg option %s is not allowed in vault.yaml (allowed: %s); pass it locally with --gpg-opts", name, strings.Join(sortedKeys(vaultGPGOptions), ", "))
+		}
+		if !inline {
+			if i+1 == len(words) {
+				return nil, fmt.Errorf("gpg option %s needs a value", name)
+			}
+			i++
+			value = words[i]
+		}
+		if !gpgOptionValue.MatchString(value) || strings.HasPrefix(value, "-") {
+			return nil, fmt.Errorf("invalid value for gpg option %s: %q", name, value)
+		}
+	}
+	return words, nil
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateRecipientFormat checks that a recipient format name is known
+func ValidateRecipientFormat(format string) error {
+	switch format {
+	case "", RecipientFormatEmail, RecipientFormatFingerprint:
+		return nil
+	}
+	return fmt.Errorf("unknown recipient format: %s (use %s or %s)", format, RecipientFormatEmail, RecipientFormatFingerprint)
+}
+
+// UsesFingerprints reports whether the store writes fingerprints to .gpg-id
+func UsesFingerprints(secretsDir string) bool {
+	cfg, err := LoadConfig(secretsDir)
+	return err == nil && cfg.RecipientFormat == RecipientFormatFingerprint
+}
+
 // GetKeysDir returns the path to the keys directory
 func GetKeysDir(secretsDir string) string {
 	return filepath.Join(secretsDir, "keys")
 }
 
-// ListVaults returns a list of all vault names
+// ListVaults returns the names of all vaults, sorted. Only directories with
+// a vault.yaml count as vaults; hidden directories and stray entries are
+// skipped, so callers never see a vault that cannot be loaded.
 func ListVaults(secretsDir string) ([]string, error) {
 	vaultsDir := filepath.Join(secretsDir, "vaults")
 	entries, err := os.ReadDir(vaultsDir)
@@ -114,12 +350,17 @@ func ListVaults(secretsDir string) ([]string, error) {
 		return nil, fmt.Errorf("failed to list vaults: %w", err)
 	}
 
-	var vaults []string
+	vaults := []string{}
 	for _, entry := range entries {
-		if entry.IsDir() {
-			vaults = append(vaults, entry.Name())
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(vaultsDir, entry.Name(), "vault.yaml")); err != nil {
+			continue
 		}
+		vaults = append(vaults, entry.Name())
 	}
+	sort.Strings(vaults)
 
 	return vaults, nil
 }
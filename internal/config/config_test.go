@@ -0,0 +1,194 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGetStoreDir(t *testing.T) {
+	secretsDir := t.TempDir()
+	if err := SaveConfig(secretsDir, &Config{Version: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := GetStoreDir(secretsDir, "dev"), filepath.Join(secretsDir, "vaults", "dev", ".password-store"); got != want {
+		t.Errorf("per-vault GetStoreDir() = %q, want %q", got, want)
+	}
+
+	if err := SaveConfig(secretsDir, &Config{Version: "1", StoreLayout: StoreLayoutShared}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := GetStoreDir(secretsDir, "dev"), filepath.Join(secretsDir, "password-store", "dev"); got != want {
+		t.Errorf("shared GetStoreDir() = %q, want %q", got, want)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	if got, want := StoreDirForLayout(secretsDir, "dev", StoreLayoutShared, "~/.password-store"), filepath.Join(home, ".password-store", "dev"); got != want {
+		t.Errorf("StoreDirForLayout(~/.password-store) = %q, want %q", got, want)
+	}
+}
+
+func TestLoadVaultConfigMigratesV0(t *testing.T) {
+	vaultDir := filepath.Join(t.TempDir(), "dev")
+	if err := os.MkdirAll(vaultDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	v0 := "name: dev\nmembers:\n  - alice@example.com\n  - bob@example.com\ncreated_at: \"2024-01-01T00:00:00Z\"\nfuture_field: ignored\n"
+	path := filepath.Join(vaultDir, "vault.yaml")
+	if err := os.WriteFile(path, []byte(v0), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadVaultConfig(vaultDir)
+	if err != nil {
+		t.Fatalf("LoadVaultConfig() error = %v", err)
+	}
+	if cfg.Version != 1 {
+		t.Errorf("Version = %d, want 1", cfg.Version)
+	}
+	if len(cfg.Members) != 2 || cfg.Members[0] != "alice@example.com" || cfg.Members[1] != "bob@example.com" {
+		t.Errorf("Members = %v, want both members preserved", cfg.Members)
+	}
+
+	// Without SetRewriteMigrated the file is left alone
+	if data, _ := os.ReadFile(path); string(data) != v0 {
+		t.Errorf("vault.yaml was rewritten without --migrate")
+	}
+
+	SetRewriteMigrated(true)
+	defer SetRewriteMigrated(false)
+	if _, err := LoadVaultConfig(vaultDir); err != nil {
+		t.Fatalf("LoadVaultConfig() error = %v", err)
+	}
+	var onDisk VaultConfig
+	data, _ := os.ReadFile(path)
+	if err := yaml.Unmarshal(data, &onDisk); err != nil {
+		t.Fatal(err)
+	}
+	if onDisk.Version != CurrentVaultConfigVersion || len(onDisk.Members) != 2 {
+		t.Errorf("rewritten config = %+v", onDisk)
+	}
+}
+
+func TestListVaults(t *testing.T) {
+	secretsDir := t.TempDir()
+	for _, name := range []string{"staging", "dev"} {
+		vaultDir := GetVaultDir(secretsDir, name)
+		if err := os.MkdirAll(vaultDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveVaultConfig(vaultDir, &VaultConfig{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A stray directory, a hidden one and a file are not vaults
+	for _, dir := range []string{"stray", ".hidden"} {
+		if err := os.MkdirAll(filepath.Join(secretsDir, "vaults", dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(secretsDir, "vaults", "README"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ListVaults(secretsDir)
+	if err != nil {
+		t.Fatalf("ListVaults() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "dev" || got[1] != "staging" {
+		t.Errorf("ListVaults() = %v, want [dev staging]", got)
+	}
+}
+
+func TestVaultConfigMemberRoles(t *testing.T) {
+	// Plain-string members from before roles existed are read-write
+	var cfg VaultConfig
+	data := "name: dev\nmembers:\n  - alice@example.com\n  - email: bob@example.com\n    role: read\n"
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(cfg.Members) != 2 || cfg.Members[1] != "bob@example.com" {
+		t.Fatalf("Members = %v", cfg.Members)
+	}
+	if got := cfg.MemberRole("alice@example.com"); got != RoleReadWrite {
+		t.Errorf("MemberRole(alice) = %q, want %q", got, RoleReadWrite)
+	}
+	if got := cfg.MemberRole("Bob@Example.com"); got != RoleRead {
+		t.Errorf("MemberRole(Bob) = %q, want %q", got, RoleRead)
+	}
+	if got := cfg.Writers(); len(got) != 1 || got[0] != "alice@example.com" {
+		t.Errorf("Writers() = %v", got)
+	}
+
+	// Read-write members are written back as plain emails
+	out, err := yaml.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := "members:\n    - alice@example.com\n    - email: bob@example.com\n      role: read\n"
+	if !strings.Contains(string(out), want) {
+		t.Errorf("Marshal() =\n%s\nwant members:\n%s", out, want)
+	}
+
+	cfg.SetMemberRole("bob@example.com", RoleReadWrite)
+	if len(cfg.Roles) != 0 {
+		t.Errorf("Roles = %v after resetting to read-write", cfg.Roles)
+	}
+
+	if err := yaml.Unmarshal([]byte("members:\n  - email: carol@example.com\n    role: admin\n"), &cfg); err == nil {
+		t.Error("Unmarshal() accepted an unknown role")
+	}
+}
+
+func TestVaultConfigIsOwner(t *testing.T) {
+	// Without an owner, as in vaults created before owners existed, every
+	// member is one
+	cfg := &VaultConfig{Members: []string{"alice@example.com", "bob@example.com"}}
+	if !cfg.IsOwner("Bob@example.com") || cfg.IsOwner("carol@example.com") {
+		t.Errorf("IsOwner() without an owner: bob %v, carol %v", cfg.IsOwner("Bob@example.com"), cfg.IsOwner("carol@example.com"))
+	}
+
+	cfg.Owner = "alice@example.com"
+	if !cfg.IsOwner("ALICE@example.com") || cfg.IsOwner("bob@example.com") {
+		t.Errorf("IsOwner() with owner alice: alice %v, bob %v", cfg.IsOwner("ALICE@example.com"), cfg.IsOwner("bob@example.com"))
+	}
+}
+
+func TestValidateVaultGPGOpts(t *testing.T) {
+	valid := []struct {
+		opts []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"--compress-algo", "none"}, "--compress-algo none"},
+		{[]string{"--cipher-algo AES256", "--s2k-count=65011712"}, "--cipher-algo AES256 --s2k-count=65011712"},
+	}
+	for _, tt := range valid {
+		got, err := ValidateVaultGPGOpts(tt.opts)
+		if err != nil || strings.Join(got, " ") != tt.want {
+			t.Errorf("ValidateVaultGPGOpts(%q) = %q, %v, want %q", tt.opts, got, err, tt.want)
+		}
+	}
+
+	for _, opts := range [][]string{
+		{"--hidden-encrypt-to", "ABCDEF0123456789"},
+		{"--encrypt-to=attacker@example.com"},
+		{"--trust-model", "always"},
+		{"--compress-algo", "none", "--recipient", "x"},
+		{"--output", "/tmp/x"},
+		{"--homedir", "/tmp"},
+		{"--cipher-algo"},
+		{"--cipher-algo", "--recipient"},
+		{"--digest-algo=$(id)"},
+	} {
+		if _, err := ValidateVaultGPGOpts(opts); err == nil {
+			t.Errorf("ValidateVaultGPGOpts(%q) succeeded", opts)
+		}
+	}
+}
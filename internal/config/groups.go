@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Groups maps group names to member emails (.secrets/groups.yaml)
+type Groups map[string][]string
+
+// LoadGroups loads .secrets/groups.yaml. A missing file means no groups.
+func LoadGroups(secretsDir string) (Groups, error) {
+	data, err := os.ReadFile(filepath.Join(secretsDir, "groups.yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Groups{}, nil
+		}
+		return nil, fmt.Errorf("failed to read groups: %w", err)
+	}
+
+	groups := Groups{}
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse groups: %w", err)
+	}
+	return groups, nil
+}
+
+// SaveGroups saves .secrets/groups.yaml
+func SaveGroups(secretsDir string, groups Groups) error {
+	data, err := yaml.Marshal(groups)
+	if err != nil {
+		return fmt.Errorf("failed to serialize groups: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(secretsDir, "groups.yaml"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write groups: %w", err)
+	}
+	return nil
+}
+
+// Names returns the group names in sorted order
+func (g Groups) Names() []string {
+	names := make([]string, 0, len(g))
+	for name := range g {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveMembers expands "@group" entries into the group's members; other
+// entries are kept as-is. Duplicates are dropped case-insensitively, keeping
+// the first spelling.
+func ResolveMembers(groups Groups, entries []string) ([]string, error) {
+	var members []string
+	seen := map[string]bool{}
+	add := func(email string) {
+		if lower := strings.ToLower(email); !seen[lower] {
+			seen[lower] = true
+			members = append(members, email)
+		}
+	}
+
+	for _, entry := range entries {
+		name, isGroup := strings.CutPrefix(entry, "@")
+		if !isGroup {
+			add(entry)
+			continue
+		}
+		groupMembers, ok := groups[name]
+		if !ok {
+			return nil, fmt.Errorf("group not found: %s", name)
+		}
+		for _, email := range groupMembers {
+			add(email)
+		}
+	}
+	return members, nil
+}
+
+// Includes reports whether any group lists email, compared
+// case-insensitively
+func (g Groups) Includes(email string) bool {
+	for _, members := range g {
+		for _, m := range members {
+			if strings.EqualFold(m, email) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SyncGroupMembers applies changes made to the vault's groups since they
+// were last expanded into its members. Emails added to a group become
+// members; emails removed from a group (or from a deleted group) stop being
+// members unless another of the vault's groups still includes them. The
+// vault's owner is never removed. Individual additions and removals of
+// other members are left alone.
+func SyncGroupMembers(cfg *VaultConfig, groups Groups) (added, removed []string) {
+	if len(cfg.Groups) == 0 {
+		return nil, nil
+	}
+
+	contains := func(list []string, email string) bool {
+		for _, e := range list {
+			if strings.EqualFold(e, email) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Emails still granted by any of the vault's groups
+	var granted []string
+	for name := range cfg.Groups {
+		granted = append(granted, groups[name]...)
+	}
+
+	for _, name := range Groups(cfg.Groups).Names() {
+		previous := cfg.Groups[name]
+		current, exists := groups[name]
+
+		for _, email := range current {
+			if !contains(previous, email) && !contains(cfg.Members, email) {
+				cfg.Members = append(cfg.Members, email)
+				added = append(added, email)
+			}
+		}
+		for _, email := range previous {
+			if !contains(current, email) && !contains(granted, email) && contains(cfg.Members, email) && !contains(removed, email) &&
+				!strings.EqualFold(email, cfg.Owner) {
+				removed = append(removed, email)
+			}
+		}
+
+		if exists {
+			cfg.Groups[name] = append([]string{}, current...)
+		} else {
+			delete(cfg.Groups, name)
+		}
+	}
+
+	if len(removed) > 0 {
+		var members []string
+		for _, m := range cfg.Members {
+			if !contains(removed, m) {
+				members = append(members, m)
+			}
+		}
+		cfg.Members = members
+	}
+
+	return added, removed
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveMembers(t *testing.T) {
+	groups := Groups{
+		"backend": {"alice@example.com", "bob@example.com"},
+		"ops":     {"Bob@example.com", "carol@example.com"},
+	}
+
+	got, err := ResolveMembers(groups, []string{"dave@example.com", "@backend", "@ops"})
+	if err != nil {
+		t.Fatalf("ResolveMembers() error = %v", err)
+	}
+	want := []string{"dave@example.com", "alice@example.com", "bob@example.com", "carol@example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveMembers() = %v, want %v", got, want)
+	}
+
+	if _, err := ResolveMembers(groups, []string{"@missing"}); err == nil {
+		t.Error("expected error for an unknown group")
+	}
+}
+
+func TestSyncGroupMembers(t *testing.T) {
+	cfg := &VaultConfig{
+		Owner:   "owner@example.com",
+		Members: []string{"owner@example.com", "alice@example.com", "bob@example.com", "carol@example.com"},
+		Groups: map[string][]string{
+			"backend": {"alice@example.com", "bob@example.com", "owner@example.com"},
+			"ops":     {"carol@example.com", "bob@example.com"},
+		},
+	}
+	groups := Groups{
+		// bob left backend but is still in ops; alice left; dave joined;
+		// the owner left too but stays a member
+		"backend": {"dave@example.com"},
+		"ops":     {"carol@example.com", "bob@example.com"},
+	}
+
+	added, removed := SyncGroupMembers(cfg, groups)
+	if want := []string{"dave@example.com"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	if want := []string{"alice@example.com"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	wantMembers := []string{"owner@example.com", "bob@example.com", "carol@example.com", "dave@example.com"}
+	if !reflect.DeepEqual(cfg.Members, wantMembers) {
+		t.Errorf("Members = %v, want %v", cfg.Members, wantMembers)
+	}
+	if !reflect.DeepEqual(cfg.Groups["backend"], []string{"dave@example.com"}) {
+		t.Errorf("Groups[backend] = %v, want the new expansion", cfg.Groups["backend"])
+	}
+
+	// A deleted group revokes the access it granted
+	added, removed = SyncGroupMembers(cfg, Groups{"ops": groups["ops"]})
+	if len(added) != 0 || !reflect.DeepEqual(removed, []string{"dave@example.com"}) {
+		t.Errorf("after deleting backend: added = %v, removed = %v", added, removed)
+	}
+	if _, ok := cfg.Groups["backend"]; ok {
+		t.Error("deleted group still recorded in vault config")
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// LockFileName is the advisory lock file created inside a vault directory
+const LockFileName = ".lock"
+
+var (
+	// LockTimeout is how long to wait for another process to release a vault lock
+	LockTimeout = 10 * time.Second
+	// StaleLockAge is the age after which a lock file is assumed abandoned
+	StaleLockAge = 10 * time.Minute
+
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// onInterrupt is called when the process receives SIGINT or SIGTERM while
+// holding a vault lock, see SetInterruptHandler
+var onInterrupt = func() {}
+
+// SetInterruptHandler sets what to call when the process is interrupted while
+// holding a vault lock. It should stop the work started inside the lock, such
+// as gpg and pass processes, so that the locked operation returns promptly.
+func SetInterruptHandler(fn func()) {
+	onInterrupt = fn
+}
+
+// InterruptedError is returned by WithVaultLock when the process receives
+// SIGINT or SIGTERM while holding a lock
+type InterruptedError struct {
+	Signal os.Signal
+	// LockPath is set when a second signal arrived before the interrupted
+	// operation stopped, and its lock was left in place
+	LockPath string
+}
+
+func (e *InterruptedError) Error() string {
+	if e.LockPath != "" {
+		return fmt.Sprintf("interrupted by %v before the operation stopped; if no other secrets-cli is running, remove the lock file %s", e.Signal, e.LockPath)
+	}
+	return fmt.Sprintf("interrupted by %v", e.Signal)
+}
+
+// WithVaultLock runs fn while holding an exclusive advisory lock on a vault.
+// The lock's modification time is refreshed while fn runs so that a long
+// operation is never taken for a stale lock. The lock is released when fn
+// returns, including on error.
+//
+// If the process is interrupted while fn is running, the interrupt handler
+// is called and fn is waited for before the lock is released and an
+// *InterruptedError is returned; the caller is expected to exit. A second
+// signal returns at once and leaves the lock in place, since fn may still be
+// writing.
+func WithVaultLock(vaultDir string, fn func() error) error {
+	lockPath := filepath.Join(vaultDir, LockFileName)
+	if err := acquireLock(lockPath); err != nil {
+		return err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	heartbeat := time.NewTicker(StaleLockAge / 3)
+	defer heartbeat.Stop()
+
+	var interrupted *InterruptedError
+	result := make(chan error, 1)
+	go func() { result <- fn() }()
+	for {
+		select {
+		case err := <-result:
+			releaseLock(lockPath)
+			if interrupted != nil {
+				return interrupted
+			}
+			return err
+		case sig := <-sigs:
+			if interrupted != nil {
+				interrupted.LockPath = lockPath
+				return interrupted
+			}
+			interrupted = &InterruptedError{Signal: sig}
+			onInterrupt()
+		case <-heartbeat.C:
+			if ownsLock(lockPath) {
+				now := time.Now()
+				os.Chtimes(lockPath, now, now)
+			}
+		}
+	}
+}
+
+// ownsLock reports whether the lock file still holds this process's PID
+func ownsLock(lockPath string) bool {
+	data, err := os.ReadFile(lockPath)
+	return err == nil && strings.TrimSpace(string(data)) == strconv.Itoa(os.Getpid())
+}
+
+// releaseLock removes the lock file unless another process has taken it over
+func releaseLock(lockPath string) {
+	if ownsLock(lockPath) {
+		os.Remove(lockPath)
+	}
+}
+
+// acquireLock creates the lock file exclusively, waiting up to LockTimeout
+// and breaking locks older than StaleLockAge
+func acquireLock(lockPath string) error {
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			return f.Close()
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > StaleLockAge {
+			breakStaleLock(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			owner := "another process"
+			if data, readErr := os.ReadFile(lockPath); readErr == nil {
+				if pid := strings.TrimSpace(string(data)); pid != "" {
+					owner = "process " + pid
+				}
+			}
+			return fmt.Errorf("vault is locked by %s (%s). If no other secrets-cli is running, remove the lock file", owner, lockPath)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// breakStaleLock removes an abandoned lock file. Another waiter may have
+// broken it and taken a fresh lock since it was found stale, so the file is
+// first renamed aside and checked again; a fresh lock is put back.
+func breakStaleLock(lockPath string) {
+	aside := fmt.Sprintf("%s.stale.%d", lockPath, os.Getpid())
+	if err := os.Rename(lockPath, aside); err != nil {
+		return
+	}
+	if info, err := os.Stat(aside); err == nil && time.Since(info.ModTime()) <= StaleLockAge {
+		// Link fails if yet another process has locked in the meantime
+		os.Link(aside, lockPath)
+	}
+	os.Remove(aside)
+}
+
+// EnsureLockIgnored adds LockFileName to the .gitignore at the top of the
+// secrets directory, so that "git add --all" never stages a lock held by
+// another process
+func EnsureLockIgnored(secretsDir string) error {
+	path := filepath.Join(secretsDir, ".gitignore")
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == LockFileName {
+			return nil
+		}
+	}
+
+	rule := LockFileName + "\n"
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		rule = "\n" + rule
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(rule); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
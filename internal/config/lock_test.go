@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithVaultLock(t *testing.T) {
+	vaultDir := t.TempDir()
+	lockPath := filepath.Join(vaultDir, LockFileName)
+
+	oldTimeout := LockTimeout
+	LockTimeout = 200 * time.Millisecond
+	defer func() { LockTimeout = oldTimeout }()
+
+	t.Run("ReleasedAfterError", func(t *testing.T) {
+		err := WithVaultLock(vaultDir, func() error {
+			if _, err := os.Stat(lockPath); err != nil {
+				t.Errorf("lock file should exist while held: %v", err)
+			}
+			return os.ErrInvalid
+		})
+		if err != os.ErrInvalid {
+			t.Errorf("expected fn error to be returned, got %v", err)
+		}
+		if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+			t.Error("lock file should be removed after fn returns")
+		}
+	})
+
+	t.Run("ConcurrentHolderBlocks", func(t *testing.T) {
+		err := WithVaultLock(vaultDir, func() error {
+			return WithVaultLock(vaultDir, func() error {
+				t.Error("nested lock should not be acquired")
+				return nil
+			})
+		})
+		if err == nil {
+			t.Error("expected lock contention error")
+		}
+	})
+
+	t.Run("StaleLockBroken", func(t *testing.T) {
+		if err := os.WriteFile(lockPath, []byte("99999\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-2 * StaleLockAge)
+		if err := os.Chtimes(lockPath, old, old); err != nil {
+			t.Fatal(err)
+		}
+
+		ran := false
+		if err := WithVaultLock(vaultDir, func() error { ran = true; return nil }); err != nil {
+			t.Fatalf("expected stale lock to be broken, got %v", err)
+		}
+		if !ran {
+			t.Error("fn did not run")
+		}
+	})
+	t.Run("TakenOverLockKept", func(t *testing.T) {
+		err := WithVaultLock(vaultDir, func() error {
+			return os.WriteFile(lockPath, []byte("99999\n"), 0644)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(lockPath); err != nil {
+			t.Error("a lock held by another process should not be removed")
+		}
+		os.Remove(lockPath)
+	})
+
+	t.Run("RefreshedWhileHeld", func(t *testing.T) {
+		oldAge := StaleLockAge
+		StaleLockAge = 300 * time.Millisecond
+		defer func() { StaleLockAge = oldAge }()
+
+		err := WithVaultLock(vaultDir, func() error {
+			old := time.Now().Add(-time.Hour)
+			if err := os.Chtimes(lockPath, old, old); err != nil {
+				return err
+			}
+			time.Sleep(2 * StaleLockAge / 3)
+			info, err := os.Stat(lockPath)
+			if err != nil {
+				return err
+			}
+			if time.Since(info.ModTime()) > StaleLockAge {
+				t.Error("lock should be refreshed while held")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestWithVaultLockInterrupted(t *testing.T) {
+	vaultDir := t.TempDir()
+	lockPath := filepath.Join(vaultDir, LockFileName)
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	SetInterruptHandler(func() { close(stop) })
+	defer SetInterruptHandler(func() {})
+
+	var signalErr error
+	err = WithVaultLock(vaultDir, func() error {
+		if signalErr = self.Signal(os.Interrupt); signalErr != nil {
+			return signalErr
+		}
+		// The lock must be held until the interrupted work has stopped
+		<-stop
+		time.Sleep(50 * time.Millisecond)
+		if _, err := os.Stat(lockPath); err != nil {
+			t.Errorf("lock released before fn returned: %v", err)
+		}
+		return errors.New("stopped")
+	})
+	if signalErr != nil {
+		t.Skipf("cannot signal self: %v", signalErr)
+	}
+
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) || interrupted.Signal != os.Interrupt {
+		t.Fatalf("WithVaultLock() error = %v, want an InterruptedError", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("lock file should be removed once fn has returned")
+	}
+}
+
+func TestBreakStaleLockKeepsFreshLock(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), LockFileName)
+
+	// Another waiter broke the stale lock and locked before we got here
+	if err := os.WriteFile(lockPath, []byte("12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	breakStaleLock(lockPath)
+	if data, err := os.ReadFile(lockPath); err != nil || string(data) != "12345\n" {
+		t.Errorf("fresh lock = %q, %v; want it kept", data, err)
+	}
+
+	old := time.Now().Add(-2 * StaleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	breakStaleLock(lockPath)
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("stale lock should be removed")
+	}
+	if matches, _ := filepath.Glob(lockPath + ".stale.*"); len(matches) > 0 {
+		t.Errorf("left behind %v", matches)
+	}
+}
+
+func TestEnsureLockIgnored(t *testing.T) {
+	secretsDir := t.TempDir()
+	path := filepath.Join(secretsDir, ".gitignore")
+	if err := os.WriteFile(path, []byte("*.tmp"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := EnsureLockIgnored(secretsDir); err != nil {
+			t.Fatalf("EnsureLockIgnored() error = %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "*.tmp\n.lock\n"; got != want {
+		t.Errorf(".gitignore = %q, want %q", got, want)
+	}
+}
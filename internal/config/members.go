@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Member roles. Roles are enforced by the CLI only: every member is a GPG
+// recipient, so a read-only member can still decrypt secrets with gpg or pass
+// directly and nothing stops them from editing the repository by hand.
+const (
+	// RoleRead allows reading secrets but not changing the vault
+	RoleRead = "read"
+	// RoleReadWrite allows reading and changing the vault (default)
+	RoleReadWrite = "read-write"
+)
+
+// ValidateRole checks that role is a known member role
+func ValidateRole(role string) error {
+	if role != RoleRead && role != RoleReadWrite {
+		return fmt.Errorf("unknown role: %s (use %s or %s)", role, RoleRead, RoleReadWrite)
+	}
+	return nil
+}
+
+// MemberRole returns a member's role, RoleReadWrite unless set otherwise
+func (c *VaultConfig) MemberRole(email string) string {
+	if role, ok := c.Roles[strings.ToLower(email)]; ok {
+		return role
+	}
+	return RoleReadWrite
+}
+
+// SetMemberRole records a member's role
+func (c *VaultConfig) SetMemberRole(email, role string) {
+	key := strings.ToLower(email)
+	if role == RoleReadWrite {
+		delete(c.Roles, key)
+		return
+	}
+	if c.Roles == nil {
+		c.Roles = map[string]string{}
+	}
+	c.Roles[key] = role
+}
+
+// IsOwner reports whether email owns the vault. A vault without an owner
+// is owned by all of its members, as before owners existed.
+func (c *VaultConfig) IsOwner(email string) bool {
+	if c.Owner != "" {
+		return strings.EqualFold(c.Owner, email)
+	}
+	for _, member := range c.Members {
+		if strings.EqualFold(member, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// Writers returns the members with the read-write role
+func (c *VaultConfig) Writers() []string {
+	var writers []string
+	for _, member := range c.Members {
+		if c.MemberRole(member) == RoleReadWrite {
+			writers = append(writers, member)
+		}
+	}
+	return writers
+}
+
+// memberEntry is one item of the members list in vault.yaml: a plain email
+// for read-write members, or a mapping with an email and a role
+type memberEntry struct {
+	Email string `yaml:"email"`
+	Role  string `yaml:"role,omitempty"`
+}
+
+func (m *memberEntry) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		m.Email = node.Value
+		return nil
+	}
+	type plain memberEntry
+	return node.Decode((*plain)(m))
+}
+
+func (m memberEntry) MarshalYAML() (interface{}, error) {
+	if m.Role == "" || m.Role == RoleReadWrite {
+		return m.Email, nil
+	}
+	type plain memberEntry
+	return plain(m), nil
+}
+
+// vaultConfigFields has the fields of VaultConfig without its YAML methods
+type vaultConfigFields VaultConfig
+
+// UnmarshalYAML reads the members list, accepting both plain emails (from
+// configs written before roles existed) and {email, role} entries
+func (c *VaultConfig) UnmarshalYAML(node *yaml.Node) error {
+	var raw struct {
+		vaultConfigFields `yaml:",inline"`
+		Members           []memberEntry `yaml:"members"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	*c = VaultConfig(raw.vaultConfigFields)
+	if raw.Members == nil {
+		return nil
+	}
+	c.Members = make([]string, 0, len(raw.Members))
+	for _, m := range raw.Members {
+		if m.Email == "" {
+			return fmt.Errorf("member without an email")
+		}
+		if m.Role != "" {
+			if err := ValidateRole(m.Role); err != nil {
+				return fmt.Errorf("member %s: %w", m.Email, err)
+			}
+			c.SetMemberRole(m.Email, m.Role)
+		}
+		c.Members = append(c.Members, m.Email)
+	}
+	return nil
+}
+
+// MarshalYAML writes read-write members as plain emails, so vaults without
+// roles keep the format older releases read, and other members as
+// {email, role} entries
+func (c VaultConfig) MarshalYAML() (interface{}, error) {
+	var node yaml.Node
+	if err := node.Encode(vaultConfigFields(c)); err != nil {
+		return nil, err
+	}
+
+	entries := make([]memberEntry, 0, len(c.Members))
+	for _, member := range c.Members {
+		entries = append(entries, memberEntry{Email: member, Role: c.MemberRole(member)})
+	}
+	var members yaml.Node
+	if err := members.Encode(entries); err != nil {
+		return nil, err
+	}
+
+	// Keep members after name and description, where they have always been
+	at := 0
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key == "name" || key == "description" {
+			at = i + 2
+		}
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "members"}
+	node.Content = append(node.Content[:at], append([]*yaml.Node{key, &members}, node.Content[at:]...)...)
+	return &node, nil
+}
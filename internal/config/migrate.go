@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// CurrentVaultConfigVersion is the vault.yaml schema version written by this
+// release. Files without a version field are version 0.
+const CurrentVaultConfigVersion = 1
+
+var rewriteMigrated bool
+
+// SetRewriteMigrated makes LoadVaultConfig write upgraded vault configs back
+// to disk. Otherwise older configs are only upgraded in memory.
+func SetRewriteMigrated(v bool) {
+	rewriteMigrated = v
+}
+
+// MigrateVaultConfig upgrades a vault config loaded from an older schema
+// version to CurrentVaultConfigVersion, filling defaults for new fields. It
+// reports whether anything changed. Configs from a newer release are left
+// untouched so their unknown fields are not dropped by a rewrite.
+func MigrateVaultConfig(cfg *VaultConfig) bool {
+	if cfg.Version >= CurrentVaultConfigVersion {
+		return false
+	}
+
+	// v0 -> v1: the version field is introduced and members is always a list
+	if cfg.Version < 1 {
+		if cfg.Members == nil {
+			cfg.Members = []string{}
+		}
+		cfg.Version = 1
+	}
+
+	return true
+}
+
+// migrateOnLoad upgrades a freshly loaded config and, if enabled with
+// SetRewriteMigrated, saves the upgraded version
+func migrateOnLoad(vaultDir string, cfg *VaultConfig) error {
+	from := cfg.Version
+	if !MigrateVaultConfig(cfg) || !rewriteMigrated {
+		return nil
+	}
+	if err := SaveVaultConfig(vaultDir, cfg); err != nil {
+		return fmt.Errorf("failed to rewrite migrated vault config: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Migrated %s/vault.yaml from version %d to %d\n", vaultDir, from, cfg.Version)
+	return nil
+}
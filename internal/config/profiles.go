@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile bundles the identity settings of one user of a machine. It only
+// points at a keyring; keys themselves are never stored in profiles.yaml.
+type Profile struct {
+	Email     string `yaml:"email,omitempty"`
+	GNUPGHome string `yaml:"gnupg_home,omitempty"`
+	GPGBinary string `yaml:"gpg_binary,omitempty"`
+}
+
+// Profiles is the per-user profiles.yaml. Current is the profile used when
+// --profile is not given.
+type Profiles struct {
+	Current  string             `yaml:"current,omitempty"`
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+// ProfilesPath returns the path of profiles.yaml in the user's config
+// directory, e.g. ~/.config/secrets-cli/profiles.yaml
+func ProfilesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "secrets-cli", "profiles.yaml"), nil
+}
+
+// LoadProfiles loads profiles.yaml. A missing file means no profiles.
+func LoadProfiles() (*Profiles, error) {
+	path, err := ProfilesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Profiles{Profiles: map[string]Profile{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	profiles := &Profiles{}
+	if err := yaml.Unmarshal(data, profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if profiles.Profiles == nil {
+		profiles.Profiles = map[string]Profile{}
+	}
+	return profiles, nil
+}
+
+// SaveProfiles saves profiles.yaml, readable only by the user
+func SaveProfiles(profiles *Profiles) error {
+	path, err := ProfilesPath()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(profiles)
+	if err != nil {
+		return fmt.Errorf("failed to serialize profiles: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	return nil
+}
+
+// Names returns the profile names in sorted order
+func (p *Profiles) Names() []string {
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandHome resolves a leading "~/" against the home directory
+func ExpandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
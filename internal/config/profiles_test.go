@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfilesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	profiles, err := LoadProfiles()
+	if err != nil {
+		t.Fatalf("LoadProfiles() without a file error = %v", err)
+	}
+	if len(profiles.Profiles) != 0 || profiles.Current != "" {
+		t.Fatalf("LoadProfiles() without a file = %+v", profiles)
+	}
+
+	profiles.Profiles["work"] = Profile{Email: "alice@company.com", GNUPGHome: "~/.gnupg-work"}
+	profiles.Profiles["personal"] = Profile{Email: "alice@example.com", GPGBinary: "gpg2"}
+	profiles.Current = "work"
+	if err := SaveProfiles(profiles); err != nil {
+		t.Fatalf("SaveProfiles() error = %v", err)
+	}
+
+	path, err := ProfilesPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("profiles.yaml mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, err := LoadProfiles()
+	if err != nil {
+		t.Fatalf("LoadProfiles() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, profiles) {
+		t.Errorf("LoadProfiles() = %+v, want %+v", loaded, profiles)
+	}
+	if got := loaded.Names(); !reflect.DeepEqual(got, []string{"personal", "work"}) {
+		t.Errorf("Names() = %v", got)
+	}
+	if got := ExpandHome(loaded.Profiles["work"].GNUPGHome); got != filepath.Join(dir, ".gnupg-work") {
+		t.Errorf("ExpandHome() = %s", got)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateSubvaultPath applies the secret name rules to a subvault path, so
+// a path read from vault.yaml cannot point outside the password store
+func ValidateSubvaultPath(path string) error {
+	if path == "" ||
+		strings.Contains(path, "..") ||
+		strings.Contains(path, "\\") ||
+		strings.Contains(path, "//") ||
+		strings.HasPrefix(path, "/") ||
+		strings.HasSuffix(path, "/") ||
+		strings.HasPrefix(path, "-") {
+		return fmt.Errorf("invalid subvault path: %q (must not be empty, contain '..', '\\', '//', or start/end with '/')", path)
+	}
+	return nil
+}
+
+// SubvaultFor returns the subvault a secret belongs to: the longest subvault
+// path that contains it, matching the nearest .gpg-id pass would use
+func (c *VaultConfig) SubvaultFor(secret string) (string, bool) {
+	best := ""
+	for path := range c.Subvaults {
+		if strings.HasPrefix(secret, path+"/") && len(path) > len(best) {
+			best = path
+		}
+	}
+	return best, best != ""
+}
+
+// SubvaultPaths returns the subvault paths sorted so parents come before
+// the subvaults nested in them
+func (c *VaultConfig) SubvaultPaths() []string {
+	paths := make([]string, 0, len(c.Subvaults))
+	for path := range c.Subvaults {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// PruneSubvaults drops everyone who is no longer a vault member from the
+// subvaults and returns the paths of subvaults left without members
+func (c *VaultConfig) PruneSubvaults() []string {
+	members := map[string]bool{}
+	for _, member := range c.Members {
+		members[strings.ToLower(member)] = true
+	}
+
+	var emptied []string
+	for _, path := range c.SubvaultPaths() {
+		var kept []string
+		for _, member := range c.Subvaults[path] {
+			if members[strings.ToLower(member)] {
+				kept = append(kept, member)
+			}
+		}
+		c.Subvaults[path] = kept
+		if len(kept) == 0 {
+			emptied = append(emptied, path)
+		}
+	}
+	return emptied
+}
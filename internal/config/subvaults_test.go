@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSubvaultFor(t *testing.T) {
+	cfg := &VaultConfig{Subvaults: map[string][]string{
+		"admin":       {"a@example.com"},
+		"admin/root":  {"b@example.com"},
+		"prod/db":     {"a@example.com"},
+		"prod/db-old": {"b@example.com"},
+	}}
+
+	tests := []struct {
+		secret string
+		want   string
+	}{
+		{"admin/token", "admin"},
+		{"admin/root/key", "admin/root"},
+		{"prod/db/password", "prod/db"},
+		{"prod/db-old/password", "prod/db-old"},
+		{"prod/dbx", ""},
+		{"admin", ""},
+		{"api/key", ""},
+	}
+	for _, tt := range tests {
+		got, ok := cfg.SubvaultFor(tt.secret)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("SubvaultFor(%q) = %q, %v, want %q", tt.secret, got, ok, tt.want)
+		}
+	}
+}
+
+func TestPruneSubvaults(t *testing.T) {
+	cfg := &VaultConfig{
+		Members: []string{"a@example.com", "B@example.com"},
+		Subvaults: map[string][]string{
+			"admin": {"a@example.com", "c@example.com"},
+			"ops":   {"c@example.com"},
+			"team":  {"b@example.com"},
+		},
+	}
+
+	emptied := cfg.PruneSubvaults()
+	if !reflect.DeepEqual(emptied, []string{"ops"}) {
+		t.Errorf("PruneSubvaults() = %v, want [ops]", emptied)
+	}
+	if !reflect.DeepEqual(cfg.Subvaults["admin"], []string{"a@example.com"}) {
+		t.Errorf("admin = %v, want [a@example.com]", cfg.Subvaults["admin"])
+	}
+	if !reflect.DeepEqual(cfg.Subvaults["team"], []string{"b@example.com"}) {
+		t.Errorf("team = %v, want [b@example.com]", cfg.Subvaults["team"])
+	}
+}
+
+func TestLoadVaultConfigRejectsEscapingSubvault(t *testing.T) {
+	for _, path := range []string{"../../x", "admin/../../x", "/etc", "admin/"} {
+		vaultDir := t.TempDir()
+		data := "version: 1\nname: dev\nmembers:\n  - a@example.com\nsubvaults:\n  " + strconv.Quote(path) + ":\n    - a@example.com\n"
+		if err := os.WriteFile(filepath.Join(vaultDir, "vault.yaml"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadVaultConfig(vaultDir); err == nil {
+			t.Errorf("LoadVaultConfig() accepted subvault path %q", path)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of vault inconsistencies reported by ValidateVault
+const (
+	// IssueMissingKey is a member without a keys/<email>.asc file
+	IssueMissingKey = "missing_key"
+	// IssueNotRecipient is a member or recovery key missing from .gpg-id
+	IssueNotRecipient = "not_recipient"
+	// IssueStaleRecipient is a .gpg-id entry that is neither a member nor a recovery key
+	IssueStaleRecipient = "stale_recipient"
+	// IssueMissingGPGID is a vault whose password store has no .gpg-id
+	IssueMissingGPGID = "missing_gpg_id"
+	// IssueWrongRecipients is a secret not encrypted for the vault's recipients
+	IssueWrongRecipients = "wrong_recipients"
+	// IssuePlaintextFile is an unencrypted file inside a password store
+	IssuePlaintextFile = "plaintext_file"
+)
+
+// VaultIssue describes one inconsistency between a vault's config, the
+// stored public keys and the recipients its secrets are encrypted for
+type VaultIssue struct {
+	Vault   string `json:"vault"`
+	Kind    string `json:"kind"`
+	Email   string `json:"email"`
+	Message string `json:"message"`
+}
+
+var fingerprintResolver func(email string) (string, error)
+
+// SetFingerprintResolver sets how ValidateVault looks up a member's key
+// fingerprint when the store writes fingerprints to .gpg-id. Without it,
+// .gpg-id entries are compared as emails.
+func SetFingerprintResolver(resolve func(email string) (string, error)) {
+	fingerprintResolver = resolve
+}
+
+// ValidateVault cross-checks a vault's members against the key files in
+// keys/ and the recipients in the vault's .gpg-id. Emails are compared
+// case-insensitively. An empty result means the vault is consistent.
+func ValidateVault(secretsDir, vaultName string) ([]VaultIssue, error) {
+	vaultDir := GetVaultDir(secretsDir, vaultName)
+	vaultCfg, err := LoadVaultConfig(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []VaultIssue
+	add := func(kind, email, format string, args ...interface{}) {
+		issues = append(issues, VaultIssue{
+			Vault:   vaultName,
+			Kind:    kind,
+			Email:   email,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	keysDir := GetKeysDir(secretsDir)
+	for _, member := range vaultCfg.Members {
+		if _, err := os.Stat(filepath.Join(keysDir, member+".asc")); os.IsNotExist(err) {
+			add(IssueMissingKey, member, "%s is a member of %s but keys/%s.asc does not exist", member, vaultName, member)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(GetStoreDir(secretsDir, vaultName), ".gpg-id"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			add(IssueMissingGPGID, "", "%s's password store has no .gpg-id; run 'secrets-cli sync %s'", vaultName, vaultName)
+			return issues, nil
+		}
+		return nil, fmt.Errorf("failed to read .gpg-id: %w", err)
+	}
+
+	recipients := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if id := strings.TrimSpace(line); id != "" {
+			recipients[strings.ToLower(id)] = true
+		}
+	}
+
+	// With fingerprint recipients, members are matched by their fingerprint
+	recipientID := strings.ToLower
+	if fingerprintResolver != nil && UsesFingerprints(secretsDir) {
+		recipientID = func(email string) string {
+			if fpr, err := fingerprintResolver(email); err == nil {
+				return strings.ToLower(fpr)
+			}
+			return strings.ToLower(email)
+		}
+	}
+
+	// Store-wide backup recipients are expected in every vault
+	wanted := append(append([]string{}, vaultCfg.Members...), vaultCfg.RecoveryKeys...)
+	if cfg, err := LoadConfig(secretsDir); err == nil {
+		wanted = append(wanted, cfg.BackupRecipients...)
+	}
+
+	seen := map[string]bool{}
+	expected := map[string]bool{}
+	for _, email := range wanted {
+		lower := strings.ToLower(email)
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		id := recipientID(email)
+		expected[id] = true
+		if !recipients[id] {
+			add(IssueNotRecipient, email, "%s is not a recipient in %s's .gpg-id; run 'secrets-cli sync %s'", email, vaultName, vaultName)
+		}
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		id := strings.TrimSpace(line)
+		if id != "" && !expected[strings.ToLower(id)] {
+			add(IssueStaleRecipient, id, "%s is a recipient in %s's .gpg-id but not a member; run 'secrets-cli sync %s'", id, vaultName, vaultName)
+		}
+	}
+
+	return issues, nil
+}
+
+// storeMetadataFiles are the unencrypted files a password store may contain
+var storeMetadataFiles = map[string]bool{
+	".gpg-id":        true,
+	".gpg-id.sig":    true,
+	".gitattributes": true,
+}
+
+// FindPlaintextFiles lists the files in a password store, relative to it,
+// that are neither encrypted secrets nor pass metadata. A .git directory
+// inside the store is skipped. A missing store has no files.
+func FindPlaintextFiles(storeDir string) ([]string, error) {
+	var found []string
+	err := filepath.WalkDir(storeDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".gpg") || storeMetadataFiles[d.Name()] {
+			return nil
+		}
+		rel, err := filepath.Rel(storeDir, path)
+		if err != nil {
+			return err
+		}
+		found = append(found, rel)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to scan %s: %w", storeDir, err)
+	}
+	return found, nil
+}
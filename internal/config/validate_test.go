@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateVault(t *testing.T) {
+	secretsDir := t.TempDir()
+	vaultDir := GetVaultDir(secretsDir, "dev")
+	storeDir := filepath.Join(vaultDir, ".password-store")
+	keysDir := GetKeysDir(secretsDir)
+	for _, dir := range []string{storeDir, keysDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &VaultConfig{
+		Name:         "dev",
+		Members:      []string{"alice@example.com", "bob@example.com"},
+		RecoveryKeys: []string{"escrow@example.com"},
+	}
+	if err := SaveVaultConfig(vaultDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"alice@example.com", "escrow@example.com"} {
+		if err := os.WriteFile(filepath.Join(keysDir, key+".asc"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gpgID := "Alice@example.com\nescrow@example.com\nold@example.com\n"
+	if err := os.WriteFile(filepath.Join(storeDir, ".gpg-id"), []byte(gpgID), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := ValidateVault(secretsDir, "dev")
+	if err != nil {
+		t.Fatalf("ValidateVault() error = %v", err)
+	}
+
+	type kindEmail struct{ kind, email string }
+	var got []kindEmail
+	for _, issue := range issues {
+		if issue.Vault != "dev" || issue.Message == "" {
+			t.Errorf("incomplete issue: %+v", issue)
+		}
+		got = append(got, kindEmail{issue.Kind, issue.Email})
+	}
+	want := []kindEmail{
+		{IssueMissingKey, "bob@example.com"},
+		{IssueNotRecipient, "bob@example.com"},
+		{IssueStaleRecipient, "old@example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues = %v, want %v", got, want)
+	}
+}
+
+func TestFindPlaintextFiles(t *testing.T) {
+	storeDir := t.TempDir()
+	files := []string{
+		".gpg-id",
+		"db/password.gpg",
+		"db/.gpg-id",
+		"db/password.txt",
+		"notes",
+		".git/config",
+	}
+	for _, f := range files {
+		path := filepath.Join(storeDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := FindPlaintextFiles(storeDir)
+	if err != nil {
+		t.Fatalf("FindPlaintextFiles() error = %v", err)
+	}
+	want := []string{filepath.Join("db", "password.txt"), "notes"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindPlaintextFiles() = %v, want %v", got, want)
+	}
+
+	if got, err := FindPlaintextFiles(filepath.Join(storeDir, "missing")); err != nil || len(got) != 0 {
+		t.Errorf("FindPlaintextFiles(missing) = %v, %v", got, err)
+	}
+}
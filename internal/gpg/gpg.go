@@ -5,14 +5,32 @@ import (
 	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 // GPG wraps gpg command execution
 type GPG struct {
-	Binary string
+	Binary  string
+	Home    string // GNUPGHOME for child processes; inherited from the environment when empty
+	Retries int    // Extra attempts after transient agent errors
+
+	// Batch makes every invocation non-interactive: gpg never prompts or
+	// opens the terminal and asks for passphrases through loopback pinentry
+	Batch bool
+	// Passphrase, when set, is handed to gpg on file descriptor 3 with
+	// --passphrase-fd, so it never appears in argv or the environment
+	Passphrase string
+
+	// TrustModel is the --trust-model used to encrypt ("always" when empty).
+	// NoForceTrust passes none, leaving it to gpg.conf and
+	// PASSWORD_STORE_GPG_OPTS.
+	TrustModel   string
+	NoForceTrust bool
 }
 
 // New creates a new GPG wrapper with the specified binary path
@@ -29,16 +47,95 @@ type Key struct {
 	Fingerprint string
 	Email       string
 	Name        string
+	UserIDs     []string
+	SubkeyIDs   []string
+	Created     time.Time
+	Expires     time.Time // Zero if the key does not expire
+	Revoked     bool
+	// CanEncrypt is false if no subkey can be encrypted to, e.g. because
+	// they all expired or were revoked
+	CanEncrypt bool
 }
 
-// run executes a gpg command and returns stdout
+// Env returns the environment for gpg child processes, including GNUPGHOME
+// when a home directory is configured. Other tools that spawn gpg themselves,
+// such as pass, should use it too so they share the same keyring.
+// GPG_PASSPHRASE is left out: the passphrase only ever travels on a pipe.
+func (g *GPG) Env() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "GPG_PASSPHRASE=") {
+			env = append(env, kv)
+		}
+	}
+	if g.Home != "" {
+		env = append(env, "GNUPGHOME="+g.Home)
+	}
+	return env
+}
+
+// BatchArgs returns the gpg options for non-interactive use when Batch is
+// set. Other tools that spawn gpg themselves, such as pass, should add them.
+func (g *GPG) BatchArgs() []string {
+	if !g.Batch {
+		return nil
+	}
+	return []string{"--batch", "--pinentry-mode", "loopback", "--no-tty"}
+}
+
+// Command builds a gpg command with the configured binary and environment.
+// The command line is traced to stderr in verbose mode.
+func (g *GPG) Command(args ...string) *Cmd {
+	args = append(g.BatchArgs(), args...)
+	var passphrase *os.File
+	if g.Passphrase != "" {
+		// A failed pipe leaves gpg to report the missing passphrase
+		if r, err := PassphrasePipe(g.Passphrase); err == nil {
+			passphrase = r
+			args = append([]string{"--pinentry-mode", "loopback", "--passphrase-fd", "3"}, args...)
+		}
+	}
+
+	cmd := NewCommand(g.Binary, args...)
+	cmd.Env = g.Env()
+	if passphrase != nil {
+		cmd.ExtraFiles = []*os.File{passphrase}
+		cmd.closers = append(cmd.closers, passphrase)
+	}
+	g.trace(cmd.Args)
+	return cmd
+}
+
+// PassphrasePipe returns the read end of a pipe holding passphrase, to be
+// passed to a child process as an extra file. The caller closes it.
+func PassphrasePipe(passphrase string) (*os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create passphrase pipe: %w", err)
+	}
+	_, werr := w.WriteString(passphrase)
+	w.Close()
+	if werr != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to write passphrase: %w", werr)
+	}
+	return r, nil
+}
+
+// run executes a gpg command and returns stdout. It retries transient agent
+// errors up to g.Retries times.
 func (g *GPG) run(args ...string) (string, error) {
-	cmd := exec.Command(g.Binary, args...)
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
+	err := Retry(g.Retries, func() (string, error) {
+		stdout.Reset()
+		stderr.Reset()
+		cmd := g.Command(args...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		return stderr.String(), err
+	})
+	if err != nil {
 		return "", fmt.Errorf("gpg error: %s: %w", stderr.String(), err)
 	}
 
@@ -47,7 +144,7 @@ func (g *GPG) run(args ...string) (string, error) {
 
 // ExportPublicKey exports a public key for the given email
 func (g *GPG) ExportPublicKey(email string) ([]byte, error) {
-	cmd := exec.Command(g.Binary, "--armor", "--export", "--", email)
+	cmd := g.Command("--armor", "--export", "--", email)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -84,55 +181,115 @@ func (g *GPG) ImportKey(keyPath string) error {
 	return err
 }
 
-// ImportKeyFromDir imports all keys from a directory
-func (g *GPG) ImportKeyFromDir(keysDir string) (int, error) {
-	entries, err := os.ReadDir(keysDir)
-	if err != nil {
-		return 0, fmt.Errorf("failed to read keys directory: %w", err)
+// ImportSecretKey imports a private key from a file
+func (g *GPG) ImportSecretKey(keyPath string) error {
+	if _, err := g.run("--batch", "--import", "--", keyPath); err != nil {
+		return fmt.Errorf("failed to import secret key from %s: %w", keyPath, err)
 	}
+	return nil
+}
 
-	imported := 0
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".asc") {
-			keyPath := filepath.Join(keysDir, entry.Name())
-			if err := g.ImportKey(keyPath); err != nil {
-				// Log but continue - some keys may already be imported
-				continue
+// ImportStatus is the outcome of importing one key file
+type ImportStatus string
+
+const (
+	// ImportNew means the key was not in the keyring before
+	ImportNew ImportStatus = "imported"
+	// ImportUpdated means the key was present and gained new user IDs,
+	// subkeys or signatures
+	ImportUpdated ImportStatus = "updated"
+	// ImportUnchanged means the key was already present as is
+	ImportUnchanged ImportStatus = "already present"
+	// ImportFailed means gpg could not import the key
+	ImportFailed ImportStatus = "failed"
+)
+
+// ImportResult reports what happened to one key file in ImportKeyFromDir
+type ImportResult struct {
+	Email  string // file name without .asc
+	Path   string
+	Status ImportStatus
+	Err    error
+}
+
+// ImportKeyFromDir imports the keys in a directory and reports the outcome
+// per key file. With only set, just the keys of those emails are imported;
+// an email without a key file is reported as failed. Errors importing a key
+// are recorded in its result rather than returned.
+func (g *GPG) ImportKeyFromDir(keysDir string, only ...string) ([]ImportResult, error) {
+	var emails []string
+	if len(only) > 0 {
+		emails = only
+	} else {
+		entries, err := os.ReadDir(keysDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keys directory: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".asc") {
+				emails = append(emails, strings.TrimSuffix(entry.Name(), ".asc"))
 			}
-			imported++
 		}
 	}
 
-	return imported, nil
-}
-
-// GetKeyID returns the key ID for an email address
-func (g *GPG) GetKeyID(email string) (string, error) {
-	output, err := g.run("--list-keys", "--keyid-format", "long", "--", email)
-	if err != nil {
-		return "", fmt.Errorf("no key found for %s", email)
+	results := make([]ImportResult, 0, len(emails))
+	for _, email := range emails {
+		r := ImportResult{Email: email, Path: filepath.Join(keysDir, email+".asc")}
+		if _, err := os.Stat(r.Path); err != nil {
+			r.Status, r.Err = ImportFailed, fmt.Errorf("no key file: %w", err)
+		} else if output, err := g.run("--batch", "--status-fd", "1", "--import", "--", r.Path); err != nil {
+			r.Status, r.Err = ImportFailed, err
+		} else if r.Status = parseImportStatus(output); r.Status == ImportFailed {
+			r.Err = fmt.Errorf("no key found in %s", r.Path)
+		}
+		results = append(results, r)
 	}
 
-	// Parse output to extract key ID
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "pub") {
-			// Format: pub   rsa4096/KEYID 2024-01-01 [SC]
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				keyPart := parts[1]
-				if idx := strings.Index(keyPart, "/"); idx != -1 {
-					return keyPart[idx+1:], nil
-				}
-			}
+	return results, nil
+}
+
+// parseImportStatus derives the outcome of an import from gpg's
+// --status-fd output. Each IMPORT_OK line carries a bit field of reasons:
+// 0 for an unchanged key, bit 1 for a new key and other bits for new user
+// IDs, signatures, subkeys or secret keys.
+func parseImportStatus(output string) ImportStatus {
+	status := ImportFailed
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" || fields[1] != "IMPORT_OK" {
+			continue
+		}
+		reason, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		switch {
+		case reason&1 != 0:
+			return ImportNew
+		case reason != 0:
+			status = ImportUpdated
+		case status == ImportFailed:
+			status = ImportUnchanged
 		}
 	}
+	return status
+}
 
-	return "", fmt.Errorf("could not parse key ID for %s", email)
+// GetKeyID returns the long key ID of the only key for an email address
+func (g *GPG) GetKeyID(email string) (string, error) {
+	fpr, err := g.ResolveUniqueKey(email)
+	if err != nil {
+		return "", err
+	}
+	return fpr[len(fpr)-16:], nil
 }
 
-// GetFingerprint returns the fingerprint for an email address
+// GetFingerprint returns the fingerprint for an email address or key ID.
+// An email shared by several keys is an error, see ResolveUniqueKey.
 func (g *GPG) GetFingerprint(email string) (string, error) {
+	if strings.Contains(email, "@") {
+		return g.ResolveUniqueKey(email)
+	}
 	output, err := g.run("--fingerprint", "--", email)
 	if err != nil {
 		return "", err
@@ -155,79 +312,488 @@ func (g *GPG) GetFingerprint(email string) (string, error) {
 	return "", fmt.Errorf("could not parse fingerprint for %s", email)
 }
 
+// fingerprintPattern matches a full v4 key fingerprint
+var fingerprintPattern = regexp.MustCompile(`^[0-9A-Fa-f]{40}$`)
+
+// IsFingerprint reports whether id is a full 40-character key fingerprint
+func IsFingerprint(id string) bool {
+	return fingerprintPattern.MatchString(id)
+}
+
+// LookupKeys returns the public keys in the keyring matching id, with
+// their subkey IDs. An email can match several keys.
+func (g *GPG) LookupKeys(id string) ([]Key, error) {
+	output, err := g.run("--with-colons", "--list-keys", "--", id)
+	if err != nil {
+		return nil, fmt.Errorf("no key found for %s", id)
+	}
+	keys := parseColonKeys(output)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key found for %s", id)
+	}
+	return keys, nil
+}
+
+// AmbiguousKeyError reports several keys in the keyring for one email
+type AmbiguousKeyError struct {
+	Email        string
+	Fingerprints []string
+}
+
+func (e *AmbiguousKeyError) Error() string {
+	return fmt.Sprintf("several keys found for %s: %s; choose one with --key-id <fingerprint>",
+		e.Email, strings.Join(e.Fingerprints, ", "))
+}
+
+// ResolveUniqueKey returns the fingerprint of the only key in the keyring
+// with a user ID for email. If several keys have one, an
+// *AmbiguousKeyError lists them rather than picking one silently.
+func (g *GPG) ResolveUniqueKey(email string) (string, error) {
+	keys, err := g.LookupKeys(email)
+	if err != nil {
+		return "", err
+	}
+	fprs := EmailFingerprints(keys, email)
+	switch len(fprs) {
+	case 0:
+		return "", fmt.Errorf("no key found for %s", email)
+	case 1:
+		return fprs[0], nil
+	}
+	return "", &AmbiguousKeyError{Email: email, Fingerprints: fprs}
+}
+
+// EmailFingerprints returns the distinct fingerprints of the keys with a
+// user ID for email, in order. gpg matches emails as substrings, so keys
+// for e.g. "malice@example.com" are left out.
+func EmailFingerprints(keys []Key, email string) []string {
+	var fprs []string
+	seen := map[string]bool{}
+	for _, k := range keys {
+		fpr := strings.ToUpper(k.Fingerprint)
+		if k.HasEmail(email) && !seen[fpr] {
+			seen[fpr] = true
+			fprs = append(fprs, fpr)
+		}
+	}
+	return fprs
+}
+
+// ShowKeyFile describes the public keys in an armored key file without
+// importing them into the keyring
+func (g *GPG) ShowKeyFile(path string) ([]Key, error) {
+	output, err := g.run("--with-colons", "--import-options", "show-only", "--import", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+
+	keys := parseColonKeys(output)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public key found in %s", path)
+	}
+	return keys, nil
+}
+
+// ValidatePublicKey checks that data is an armored public key block with a
+// user ID for email. The key is imported into a temporary keyring, so the
+// real one is never touched, and the keys found are returned.
+func (g *GPG) ValidatePublicKey(data []byte, email string) ([]Key, error) {
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, "-----BEGIN PGP PUBLIC KEY BLOCK-----") ||
+		!strings.HasSuffix(text, "-----END PGP PUBLIC KEY BLOCK-----") {
+		return nil, fmt.Errorf("not an ASCII-armored PGP public key block")
+	}
+
+	keys, err := g.InspectPublicKey([]byte(text + "\n"))
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if k.HasEmail(email) {
+			return keys, nil
+		}
+	}
+	return nil, fmt.Errorf("key has no user ID for %s", email)
+}
+
+// InspectPublicKey imports key data, armored or binary, into a temporary
+// keyring and returns its keys as gpg sees them there, including whether
+// they are revoked or expired. The real keyring is never touched.
+func (g *GPG) InspectPublicKey(data []byte) ([]Key, error) {
+	home, err := os.MkdirTemp("", "secrets-cli-gpg-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary keyring: %w", err)
+	}
+	defer os.RemoveAll(home)
+
+	keyPath := filepath.Join(home, "key.asc")
+	if err := os.WriteFile(keyPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write key: %w", err)
+	}
+
+	tmp := *g
+	tmp.Home = home
+	if _, err := tmp.run("--batch", "--import", "--", keyPath); err != nil {
+		return nil, fmt.Errorf("failed to import key: %w", err)
+	}
+	output, err := tmp.run("--with-colons", "--list-keys")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list imported key: %w", err)
+	}
+
+	keys := parseColonKeys(output)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public key found")
+	}
+	return keys, nil
+}
+
+// HasEmail reports whether one of the key's user IDs is for email, compared
+// case-insensitively
+func (k Key) HasEmail(email string) bool {
+	for _, uid := range k.UserIDs {
+		addr := uid
+		if start := strings.LastIndex(uid, "<"); start != -1 {
+			if end := strings.LastIndex(uid, ">"); end > start {
+				addr = uid[start+1 : end]
+			}
+		}
+		if strings.EqualFold(addr, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseColonKeys parses gpg --with-colons output into primary keys. Only the
+// primary key's fingerprint is recorded; subkeys are skipped.
+func parseColonKeys(output string) []Key {
+	var keys []Key
+	var current *Key
+	inSubkey := false
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 10 {
+			continue
+		}
+		switch fields[0] {
+		case "pub", "sec":
+			if current != nil {
+				keys = append(keys, *current)
+			}
+			current = &Key{
+				KeyID:   fields[4],
+				Created: parseColonTime(fields[5]),
+				Expires: parseColonTime(fields[6]),
+				Revoked: fields[1] == "r",
+			}
+			// An upper-case E marks encryption usable by the key as a whole
+			if len(fields) > 11 {
+				current.CanEncrypt = strings.Contains(fields[11], "E")
+			}
+			inSubkey = false
+		case "sub", "ssb":
+			inSubkey = true
+			if current != nil {
+				current.SubkeyIDs = append(current.SubkeyIDs, fields[4])
+			}
+		case "fpr":
+			if current != nil && !inSubkey && current.Fingerprint == "" {
+				current.Fingerprint = fields[9]
+			}
+		case "uid":
+			if current == nil {
+				continue
+			}
+			uid := fields[9]
+			current.UserIDs = append(current.UserIDs, uid)
+			if current.Email == "" {
+				if start := strings.LastIndex(uid, "<"); start != -1 {
+					if end := strings.LastIndex(uid, ">"); end > start {
+						current.Name = strings.TrimSpace(uid[:start])
+						current.Email = uid[start+1 : end]
+					}
+				} else {
+					current.Email = uid
+				}
+			}
+		}
+	}
+
+	if current != nil {
+		keys = append(keys, *current)
+	}
+	return keys
+}
+
+// parseColonTime parses a --with-colons timestamp, which is either seconds
+// since the epoch or an ISO 8601 date
+func parseColonTime(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC()
+	}
+	if t, err := time.Parse("20060102T150405", value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// CountRecipients counts the public-key recipients an encrypted file is
+// encrypted for by listing its packets. --list-only skips the decryption
+// pass, so this works without the secret key and never prompts.
+func (g *GPG) CountRecipients(path string) (int, error) {
+	output, err := g.run("--batch", "--list-only", "--list-packets", "--", path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list packets of %s: %w", path, err)
+	}
+	return countRecipientPackets(output), nil
+}
+
+// pubkeyEncPacket matches one recipient in --list-packets output
+var pubkeyEncPacket = regexp.MustCompile(`(?im)^:pubkey enc packet:`)
+
+// countRecipientPackets counts the ":pubkey enc packet:" lines in
+// --list-packets output; each one is a recipient
+func countRecipientPackets(output string) int {
+	return len(pubkeyEncPacket.FindAllString(output, -1))
+}
+
+// RecipientKeyIDs returns the key IDs an encrypted file is encrypted for,
+// read from its packet headers without decrypting. Hidden recipients show
+// up as all zeros.
+func (g *GPG) RecipientKeyIDs(path string) ([]string, error) {
+	output, err := g.run("--batch", "--list-only", "--list-packets", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list packets of %s: %w", path, err)
+	}
+	return parseRecipientKeyIDs(output), nil
+}
+
+// pubkeyEncKeyID captures the key ID of each ":pubkey enc packet:" line
+var pubkeyEncKeyID = regexp.MustCompile(`(?im)^:pubkey enc packet:.*\bkeyid ([0-9a-f]+)`)
+
+func parseRecipientKeyIDs(output string) []string {
+	var ids []string
+	for _, m := range pubkeyEncKeyID.FindAllStringSubmatch(output, -1) {
+		ids = append(ids, strings.ToUpper(m[1]))
+	}
+	return ids
+}
+
+// CheckPackets reports why an encrypted file is damaged, or nil if its
+// packet structure is intact: gpg must be able to list its packets, it
+// needs a recipient and encrypted data, and the packet lengths must add up
+// to the file size. Nothing is decrypted, so no secret key is needed.
+func (g *GPG) CheckPackets(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("empty file")
+	}
+	output, err := g.run("--batch", "--list-only", "--list-packets", "--", path)
+	if err != nil {
+		// The first line of gpg's complaint is enough for a report
+		return fmt.Errorf("unreadable packets: %s", strings.SplitN(err.Error(), "\n", 2)[0])
+	}
+	return checkPacketStructure(output, info.Size())
+}
+
+// packetHeader matches the "# off=0 ctb=85 tag=1 hlen=3 plen=396" lines of
+// --list-packets output
+var packetHeader = regexp.MustCompile(`(?m)^# off=(\d+) ctb=[0-9a-f]+ tag=\d+ hlen=(\d+) plen=(\d+)( partial)?`)
+
+// encryptedDataPacket matches the packet holding the encrypted message
+var encryptedDataPacket = regexp.MustCompile(`(?im)^:(encrypted data|aead encrypted) packet:`)
+
+// checkPacketStructure checks --list-packets output of a file of the given
+// size. Packet offsets are only compared when gpg prints them; a packet
+// with a partial length runs to the end of the file.
+func checkPacketStructure(output string, size int64) error {
+	if countRecipientPackets(output) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	if !encryptedDataPacket.MatchString(output) {
+		return fmt.Errorf("no encrypted data (truncated?)")
+	}
+
+	headers := packetHeader.FindAllStringSubmatch(output, -1)
+	if len(headers) == 0 {
+		return nil
+	}
+	var end int64
+	for _, m := range headers {
+		off, _ := strconv.ParseInt(m[1], 10, 64)
+		hlen, _ := strconv.ParseInt(m[2], 10, 64)
+		plen, _ := strconv.ParseInt(m[3], 10, 64)
+		if m[4] != "" {
+			end = size
+		} else {
+			end = off + hlen + plen
+		}
+		if end > size {
+			return fmt.Errorf("truncated: a packet ends at byte %d of %d", end, size)
+		}
+	}
+	if end < size {
+		return fmt.Errorf("%d trailing byte(s) after the last packet", size-end)
+	}
+	return nil
+}
+
+// HasKeyID reports whether id is the long ID of the key or one of its subkeys
+func (k Key) HasKeyID(id string) bool {
+	if strings.EqualFold(k.KeyID, id) {
+		return true
+	}
+	for _, sub := range k.SubkeyIDs {
+		if strings.EqualFold(sub, id) {
+			return true
+		}
+	}
+	return false
+}
+
 // KeyExists checks if a key exists for the given email
 func (g *GPG) KeyExists(email string) bool {
 	_, err := g.run("--list-keys", "--", email)
 	return err == nil
 }
 
+// SecretKeyExists checks if a secret key exists for the given email or fingerprint
+func (g *GPG) SecretKeyExists(id string) bool {
+	_, err := g.run("--list-secret-keys", "--", id)
+	return err == nil
+}
+
 // ListSecretKeys lists all secret (private) keys
 func (g *GPG) ListSecretKeys() ([]Key, error) {
-	output, err := g.run("--list-secret-keys", "--keyid-format", "long")
+	output, err := g.run("--list-secret-keys", "--with-colons", "--fixed-list-mode")
 	if err != nil {
 		return nil, err
 	}
 
-	return parseKeyList(output), nil
+	return parseColonKeys(output), nil
 }
 
-// ListPublicKeys lists all public keys
+// ListPublicKeys lists all public keys in the keyring
 func (g *GPG) ListPublicKeys() ([]Key, error) {
-	output, err := g.run("--list-keys", "--keyid-format", "long")
+	output, err := g.run("--list-keys", "--with-colons", "--fixed-list-mode")
 	if err != nil {
 		return nil, err
 	}
 
-	return parseKeyList(output), nil
+	return parseColonKeys(output), nil
 }
 
-// parseKeyList parses gpg --list-keys output
-func parseKeyList(output string) []Key {
-	var keys []Key
-	var currentKey *Key
+// TrustArgs returns the gpg options selecting the trust model to encrypt with
+func (g *GPG) TrustArgs() []string {
+	if g.NoForceTrust {
+		return nil
+	}
+	model := g.TrustModel
+	if model == "" {
+		model = "always"
+	}
+	return []string{"--trust-model", model}
+}
 
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "pub") || strings.HasPrefix(line, "sec") {
-			if currentKey != nil {
-				keys = append(keys, *currentKey)
-			}
-			currentKey = &Key{}
-
-			// Extract key ID
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				keyPart := parts[1]
-				if idx := strings.Index(keyPart, "/"); idx != -1 {
-					currentKey.KeyID = keyPart[idx+1:]
-				}
-			}
-		} else if strings.HasPrefix(line, "uid") && currentKey != nil {
-			// Extract name and email from uid line
-			// Format: uid           [ultimate] Name <email@example.com>
-			line = strings.TrimPrefix(line, "uid")
-			line = strings.TrimSpace(line)
-
-			// Remove trust level in brackets
-			if idx := strings.Index(line, "]"); idx != -1 {
-				line = strings.TrimSpace(line[idx+1:])
-			}
+// EncryptToFile encrypts data for the given recipients and writes it to outPath
+func (g *GPG) EncryptToFile(data []byte, outPath string, recipients []string) error {
+	if len(recipients) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
 
-			// Parse name and email
-			if emailStart := strings.LastIndex(line, "<"); emailStart != -1 {
-				currentKey.Name = strings.TrimSpace(line[:emailStart])
-				if emailEnd := strings.LastIndex(line, ">"); emailEnd > emailStart {
-					currentKey.Email = line[emailStart+1 : emailEnd]
-				}
-			} else {
-				currentKey.Name = line
-			}
-		}
+	args := append([]string{"--batch", "--yes"}, g.TrustArgs()...)
+	args = append(args, "--encrypt", "--output", outPath)
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
 	}
 
-	if currentKey != nil {
-		keys = append(keys, *currentKey)
+	cmd := g.Command(args...)
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to encrypt to %s: %s: %w", outPath, strings.TrimSpace(stderr.String()), err)
 	}
 
-	return keys
+	return nil
+}
+
+// Encrypt encrypts data for the given recipients and returns the ciphertext.
+// Plaintext is passed on stdin and never written to disk.
+func (g *GPG) Encrypt(data []byte, recipients []string) ([]byte, error) {
+	return g.EncryptWithOptions(data, recipients, g.TrustArgs())
+}
+
+// EncryptWithOptions is Encrypt with opts in place of the trust model, for
+// callers that compose their gpg options themselves, as pass does
+func (g *GPG) EncryptWithOptions(data []byte, recipients []string, opts []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	args := append([]string{"--batch", "--yes"}, opts...)
+	args = append(args, "--encrypt", "--output", "-")
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
+	}
+
+	cmd := g.Command(args...)
+	cmd.Stdin = bytes.NewReader(data)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// Decrypt decrypts a GPG-encrypted file and returns the plaintext bytes
+func (g *GPG) Decrypt(path string) ([]byte, error) {
+	cmd := g.Command("--quiet", "--decrypt", "--", path)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %s: %w", path, strings.TrimSpace(stderr.String()), err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// DecryptBytes decrypts GPG-encrypted data passed on stdin
+func (g *GPG) DecryptBytes(ciphertext []byte) ([]byte, error) {
+	cmd := g.Command("--quiet", "--decrypt")
+	cmd.Stdin = bytes.NewReader(ciphertext)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// DecryptFile decrypts a .gpg file and returns its contents as text,
+// trimmed the same way pass trims its output
+func (g *GPG) DecryptFile(path string) (string, error) {
+	data, err := g.Decrypt(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRightFunc(string(data), unicode.IsSpace), nil
 }
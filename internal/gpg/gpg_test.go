@@ -0,0 +1,427 @@
+package gpg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBinary writes an executable shell script that prints $GNUPGHOME
+func fakeBinary(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	script := "#!/bin/sh\nprintf '%s' \"$GNUPGHOME\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake binary: %v", err)
+	}
+	return path
+}
+
+func TestGNUPGHomeIsPassedToChild(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GNUPGHOME", "/from/environment")
+
+	g := New(fakeBinary(t, dir, "gpg"))
+
+	out, err := g.run("--version")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if out != "/from/environment" {
+		t.Errorf("without Home, child GNUPGHOME = %q, want inherited value", out)
+	}
+
+	g.Home = filepath.Join(dir, "gnupg")
+	out, err = g.run("--version")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if out != g.Home {
+		t.Errorf("child GNUPGHOME = %q, want %q", out, g.Home)
+	}
+
+	cmd := g.Command("--version")
+	if !strings.HasPrefix(cmd.Env[len(cmd.Env)-1], "GNUPGHOME=") {
+		t.Errorf("Command() env does not end with GNUPGHOME override: %v", cmd.Env[len(cmd.Env)-1])
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	got := FormatCommand(
+		[]string{"GNUPGHOME=/tmp/my home"},
+		[]string{"gpg", "--list-keys", "--", "alice@example.com"},
+	)
+	want := `GNUPGHOME="/tmp/my home" gpg --list-keys -- alice@example.com`
+	if got != want {
+		t.Errorf("FormatCommand() = %q, want %q", got, want)
+	}
+}
+
+// flakyBinary writes a script that fails with stderrMsg until it has been
+// run failures times, then prints "ok". It returns the script path and a
+// function reporting how often it ran.
+func flakyBinary(t *testing.T, failures int, stderrMsg string) (string, func() int) {
+	t.Helper()
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "count")
+	script := fmt.Sprintf(`#!/bin/sh
+n=$(cat %[1]q 2>/dev/null || echo 0)
+n=$((n+1))
+echo $n > %[1]q
+if [ $n -le %[2]d ]; then
+  echo %[3]q >&2
+  exit 2
+fi
+echo ok
+`, counter, failures, stderrMsg)
+	path := filepath.Join(dir, "gpg")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake binary: %v", err)
+	}
+	return path, func() int {
+		data, _ := os.ReadFile(counter)
+		n := 0
+		fmt.Sscanf(string(data), "%d", &n)
+		return n
+	}
+}
+
+func TestRunRetriesTransientErrors(t *testing.T) {
+	oldBackoff := RetryBackoff
+	RetryBackoff = time.Millisecond
+	defer func() { RetryBackoff = oldBackoff }()
+
+	t.Run("TransientThenSuccess", func(t *testing.T) {
+		bin, runs := flakyBinary(t, 2, "gpg: signing failed: Inappropriate ioctl for device")
+		g := New(bin)
+		g.Retries = 2
+
+		out, err := g.run("--sign")
+		if err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if strings.TrimSpace(out) != "ok" {
+			t.Errorf("run() = %q, want ok", out)
+		}
+		if runs() != 3 {
+			t.Errorf("binary ran %d times, want 3", runs())
+		}
+	})
+
+	t.Run("GivesUpAfterRetries", func(t *testing.T) {
+		bin, runs := flakyBinary(t, 5, "gpg-agent: agent refused operation")
+		g := New(bin)
+		g.Retries = 1
+
+		if _, err := g.run("--sign"); err == nil {
+			t.Fatal("expected error after exhausting retries")
+		}
+		if runs() != 2 {
+			t.Errorf("binary ran %d times, want 2", runs())
+		}
+	})
+
+	t.Run("NonTransientFailsFast", func(t *testing.T) {
+		bin, runs := flakyBinary(t, 1, "gpg: decryption failed: No secret key")
+		g := New(bin)
+		g.Retries = 3
+
+		if _, err := g.run("--decrypt"); err == nil {
+			t.Fatal("expected error")
+		}
+		if runs() != 1 {
+			t.Errorf("binary ran %d times, want 1", runs())
+		}
+	})
+}
+
+func TestParseColonKeys(t *testing.T) {
+	output := `pub:u:3072:1:1092A3C3F9339B23:1792154079:1823690079::u:::scESC::::::23::0:
+fpr:::::::::EC6B9FD623641F0CB8BAB5441092A3C3F9339B23:
+uid:u::::1792154079::D4794DFE2DF022B9F5AE5C75AB0B130F261B9B49::Alice Example <alice@example.com>::::::::::0:
+sub:u:3072:1:5518626961FE31D9:1792154079::::::e::::::23:
+fpr:::::::::2F0D7AF5D87C73FB81B2D38C5518626961FE31D9:
+`
+	keys := parseColonKeys(output)
+	if len(keys) != 1 {
+		t.Fatalf("parseColonKeys() returned %d keys, want 1", len(keys))
+	}
+	k := keys[0]
+	if k.KeyID != "1092A3C3F9339B23" {
+		t.Errorf("KeyID = %q", k.KeyID)
+	}
+	if k.Fingerprint != "EC6B9FD623641F0CB8BAB5441092A3C3F9339B23" {
+		t.Errorf("Fingerprint = %q, want the primary key's fingerprint", k.Fingerprint)
+	}
+	if k.Email != "alice@example.com" || k.Name != "Alice Example" {
+		t.Errorf("Email/Name = %q/%q", k.Email, k.Name)
+	}
+	if !k.Created.Equal(time.Unix(1792154079, 0)) {
+		t.Errorf("Created = %v", k.Created)
+	}
+	if !k.Expires.Equal(time.Unix(1823690079, 0)) {
+		t.Errorf("Expires = %v", k.Expires)
+	}
+	if k.Revoked || !k.CanEncrypt {
+		t.Errorf("Revoked/CanEncrypt = %v/%v, want false/true", k.Revoked, k.CanEncrypt)
+	}
+
+	revoked := parseColonKeys("pub:r:3072:1:1092A3C3F9339B23:1792154079:::-:::sc::::::23::0:\n")
+	if len(revoked) != 1 || !revoked[0].Revoked || revoked[0].CanEncrypt {
+		t.Errorf("revoked key parsed as %+v", revoked)
+	}
+}
+
+func TestCountRecipientPackets(t *testing.T) {
+	output := `# off=0 ctb=85 tag=1 hlen=3 plen=396
+:pubkey enc packet: version 3, algo 1, keyid 5518626961FE31D9
+	data: [3072 bits]
+# off=399 ctb=85 tag=1 hlen=3 plen=396
+:pubkey enc packet: version 3, algo 1, keyid 0B3C2E1F9A8D7C6B
+	data: [3072 bits]
+# off=798 ctb=d2 tag=18 hlen=2 plen=66 new-ctb
+:encrypted data packet:
+	length: 66
+`
+	if got := countRecipientPackets(output); got != 2 {
+		t.Errorf("countRecipientPackets() = %d, want 2", got)
+	}
+	if got := countRecipientPackets(""); got != 0 {
+		t.Errorf("countRecipientPackets(\"\") = %d, want 0", got)
+	}
+}
+
+func TestParseImportStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   ImportStatus
+	}{
+		{"new key", "[GNUPG:] IMPORT_OK 1 ABCD\n[GNUPG:] IMPORT_RES 1 0 1 0 0 0 0 0 0 0 0 0 0 0\n", ImportNew},
+		{"unchanged", "[GNUPG:] IMPORT_OK 0 ABCD\n", ImportUnchanged},
+		{"new signatures", "[GNUPG:] IMPORT_OK 4 ABCD\n", ImportUpdated},
+		{"new wins over unchanged", "[GNUPG:] IMPORT_OK 0 ABCD\n[GNUPG:] IMPORT_OK 1 EF01\n", ImportNew},
+		{"nothing imported", "[GNUPG:] IMPORT_RES 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n", ImportFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseImportStatus(tt.output); got != tt.want {
+				t.Errorf("parseImportStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchPassesPassphraseOnFD(t *testing.T) {
+	// The fake gpg prints its arguments, then what it reads from fd 3
+	bin := filepath.Join(t.TempDir(), "gpg")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\necho \"$@\"\ncat <&3\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake gpg: %v", err)
+	}
+
+	g := New(bin)
+	g.Batch = true
+	g.Passphrase = "hunter2"
+
+	out, err := g.run("--decrypt", "--", "secret.gpg")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	argv, fd3, _ := strings.Cut(out, "\n")
+	for _, want := range []string{"--batch", "--pinentry-mode loopback", "--no-tty", "--passphrase-fd 3", "--decrypt -- secret.gpg"} {
+		if !strings.Contains(argv, want) {
+			t.Errorf("arguments %q do not contain %q", argv, want)
+		}
+	}
+	if strings.Contains(argv, "hunter2") {
+		t.Errorf("passphrase leaked into arguments: %q", argv)
+	}
+	if fd3 != "hunter2" {
+		t.Errorf("fd 3 = %q, want the passphrase", fd3)
+	}
+}
+
+func TestParseRecipientKeyIDs(t *testing.T) {
+	output := `# off=0 ctb=85 tag=1 hlen=3 plen=396
+:pubkey enc packet: version 3, algo 1, keyid 5518626961FE31D9
+	data: [3072 bits]
+# off=399 ctb=85 tag=1 hlen=3 plen=396
+:pubkey enc packet: version 3, algo 1, keyid 0000000000000000
+	data: [3072 bits]
+:encrypted data packet:
+`
+	got := parseRecipientKeyIDs(output)
+	want := []string{"5518626961FE31D9", "0000000000000000"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("parseRecipientKeyIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestParseKeygrips(t *testing.T) {
+	output := `sec:u:255:22:AAAA1111BBBB2222:1700000000:::u:::scESC:::+::ed25519:::0:
+fpr:::::::::0123456789ABCDEF0123456789ABCDEFAAAA1111:
+grp:::::::::1111111111111111111111111111111111111111:
+uid:u::::1700000000::HASH::Alice <alice@example.com>::::::::::0:
+ssb:u:255:18:CCCC3333DDDD4444:1700000000::::::e:::+::cv25519::
+fpr:::::::::FEDCBA9876543210FEDCBA9876543210CCCC3333:
+grp:::::::::2222222222222222222222222222222222222222:
+`
+	got := parseKeygrips(output)
+	want := []string{
+		"1111111111111111111111111111111111111111",
+		"2222222222222222222222222222222222222222",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseKeygrips() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseKeygrips()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestKeyHasEmail(t *testing.T) {
+	k := Key{UserIDs: []string{"Alice Example <Alice@Example.com>", "alice@work.example"}}
+	for _, email := range []string{"alice@example.com", "ALICE@WORK.EXAMPLE"} {
+		if !k.HasEmail(email) {
+			t.Errorf("HasEmail(%q) = false, want true", email)
+		}
+	}
+	for _, email := range []string{"bob@example.com", "example.com", ""} {
+		if k.HasEmail(email) {
+			t.Errorf("HasEmail(%q) = true, want false", email)
+		}
+	}
+}
+
+func TestEmailFingerprints(t *testing.T) {
+	keys := []Key{
+		{Fingerprint: "AAAA", UserIDs: []string{"Alice <alice@example.com>"}},
+		{Fingerprint: "BBBB", UserIDs: []string{"Mallory <malice@example.com>"}},
+		{Fingerprint: "cccc", UserIDs: []string{"Alice (new) <Alice@example.com>"}},
+		{Fingerprint: "AAAA", UserIDs: []string{"Alice <alice@example.com>"}},
+	}
+	got := EmailFingerprints(keys, "alice@example.com")
+	if want := []string{"AAAA", "CCCC"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EmailFingerprints() = %v, want %v", got, want)
+	}
+
+	err := &AmbiguousKeyError{Email: "alice@example.com", Fingerprints: got}
+	if !strings.Contains(err.Error(), "AAAA, CCCC") || !strings.Contains(err.Error(), "--key-id") {
+		t.Errorf("AmbiguousKeyError = %q", err)
+	}
+}
+
+func TestIsFingerprint(t *testing.T) {
+	for id, want := range map[string]bool{
+		"EC6B9FD623641F0CB8BAB5441092A3C3F9339B23": true,
+		"ec6b9fd623641f0cb8bab5441092a3c3f9339b23": true,
+		"1092A3C3F9339B23":                         false,
+		"alice@example.com":                        false,
+		"EC6B9FD623641F0CB8BAB5441092A3C3F9339B2Z": false,
+	} {
+		if got := IsFingerprint(id); got != want {
+			t.Errorf("IsFingerprint(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestCheckPacketStructure(t *testing.T) {
+	const complete = `# off=0 ctb=85 tag=1 hlen=3 plen=396
+:pubkey enc packet: version 3, algo 1, keyid 5518626961FE31D9
+	data: [3072 bits]
+# off=399 ctb=d2 tag=18 hlen=2 plen=66 new-ctb
+:encrypted data packet:
+	length: 66
+	mdc_method: 2
+`
+	const partial = `# off=0 ctb=85 tag=1 hlen=3 plen=396
+:pubkey enc packet: version 3, algo 1, keyid 5518626961FE31D9
+# off=399 ctb=d2 tag=18 hlen=2 plen=0 partial new-ctb
+:encrypted data packet:
+	length: unknown
+`
+	const headerOnly = `# off=0 ctb=85 tag=1 hlen=3 plen=396
+:pubkey enc packet: version 3, algo 1, keyid 5518626961FE31D9
+`
+	tests := []struct {
+		name    string
+		output  string
+		size    int64
+		wantErr string
+	}{
+		{"Complete", complete, 467, ""},
+		{"Truncated", complete, 420, "truncated"},
+		{"Trailing", complete, 500, "trailing"},
+		{"Partial", partial, 3469, ""},
+		{"NoData", headerOnly, 100, "no encrypted data"},
+		{"NoRecipients", ":encrypted data packet:\n", 100, "no recipients"},
+		{"NoOffsets", ":pubkey enc packet: keyid 1\n:encrypted data packet:\n", 100, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPacketStructure(tt.output, tt.size)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkPacketStructure() error = %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkPacketStructure() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPassphrase(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available in PATH")
+	}
+	if _, err := exec.LookPath("gpgconf"); err != nil {
+		t.Skip("gpgconf not available in PATH")
+	}
+	home, err := os.MkdirTemp("", "gpg-check-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	g := New("")
+	g.Home = home
+	defer func() {
+		kill := exec.Command("gpgconf", "--kill", "gpg-agent")
+		kill.Env = g.Env()
+		_ = kill.Run()
+	}()
+
+	const email = "check@example.com"
+	gen := g.Command("--batch", "--pinentry-mode", "loopback", "--passphrase", "right",
+		"--quick-generate-key", email, "default", "default", "never")
+	if out, err := gen.CombinedOutput(); err != nil {
+		t.Fatalf("failed to generate key: %v\n%s", err, out)
+	}
+
+	// Unlock the key in the agent, which then accepts any passphrase
+	ciphertext, err := g.Encrypt([]byte("x"), []string{email})
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	unlock := *g
+	unlock.Batch = true
+	unlock.Passphrase = "right"
+	if _, err := unlock.DecryptBytes(ciphertext); err != nil {
+		t.Fatalf("DecryptBytes() error = %v", err)
+	}
+
+	if err := g.CheckPassphrase(email, "wrong"); err == nil {
+		t.Error("CheckPassphrase() accepted a wrong passphrase for a key the agent had unlocked")
+	}
+	if err := g.CheckPassphrase(email, "right"); err != nil {
+		t.Errorf("CheckPassphrase() error = %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package gpg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPresetUnavailable is returned when gpg-preset-passphrase is not installed
+var ErrPresetUnavailable = errors.New("gpg-preset-passphrase not found")
+
+// presetBinary locates gpg-preset-passphrase, which is usually installed in
+// gpg's libexec directory rather than on PATH
+func presetBinary() (string, error) {
+	if path, err := exec.LookPath("gpg-preset-passphrase"); err == nil {
+		return path, nil
+	}
+	out, err := exec.Command("gpgconf", "--list-dirs", "libexecdir").Output()
+	if err != nil {
+		return "", ErrPresetUnavailable
+	}
+	path := filepath.Join(strings.TrimSpace(string(out)), "gpg-preset-passphrase")
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", ErrPresetUnavailable
+	}
+	return path, nil
+}
+
+// Keygrips returns the keygrips of a secret key and its subkeys. gpg-agent
+// caches passphrases per keygrip.
+func (g *GPG) Keygrips(id string) ([]string, error) {
+	output, err := g.run("--with-keygrip", "--with-colons", "--list-secret-keys", "--", id)
+	if err != nil {
+		return nil, fmt.Errorf("no secret key found for %s", id)
+	}
+	return parseKeygrips(output), nil
+}
+
+func parseKeygrips(output string) []string {
+	var grips []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) >= 10 && fields[0] == "grp" && fields[9] != "" {
+			grips = append(grips, fields[9])
+		}
+	}
+	return grips
+}
+
+// PresetPassphrase caches passphrase in gpg-agent for keygrip, so later
+// operations in the session do not prompt. The agent must be configured with
+// allow-preset-passphrase. The passphrase is passed on stdin.
+func (g *GPG) PresetPassphrase(keygrip, passphrase string) error {
+	bin, err := presetBinary()
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCommand(bin, "--preset", keygrip)
+	cmd.Env = g.Env()
+	cmd.Stdin = strings.NewReader(passphrase)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	g.trace(cmd.Args)
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(strings.ToLower(msg), "not supported") {
+			return fmt.Errorf("gpg-agent refused the preset: add allow-preset-passphrase to gpg-agent.conf and run 'gpgconf --reload gpg-agent': %w", err)
+		}
+		return fmt.Errorf("failed to preset passphrase: %s: %w", msg, err)
+	}
+	return nil
+}
+
+// CheckPassphrase checks that passphrase unlocks the secret key id. A
+// running gpg-agent that already unlocked the key accepts any passphrase,
+// so the check copies the key into a temporary home with a fresh agent,
+// which has nothing cached, and decrypts a test message there.
+func (g *GPG) CheckPassphrase(id, passphrase string) error {
+	grips, err := g.Keygrips(id)
+	if err != nil {
+		return err
+	}
+	publicKey, err := g.ExportPublicKey(id)
+	if err != nil {
+		return err
+	}
+	srcHome, err := g.homeDir()
+	if err != nil {
+		return err
+	}
+
+	home, err := os.MkdirTemp("", "secrets-cli-gpg-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary keyring: %w", err)
+	}
+	defer os.RemoveAll(home)
+	tmp := *g
+	tmp.Home = home
+	defer func() {
+		kill := exec.Command("gpgconf", "--kill", "gpg-agent")
+		kill.Env = tmp.Env()
+		_ = kill.Run()
+	}()
+
+	keysDir := filepath.Join(home, "private-keys-v1.d")
+	if err := os.Mkdir(keysDir, 0700); err != nil {
+		return fmt.Errorf("failed to create temporary keyring: %w", err)
+	}
+	for _, grip := range grips {
+		data, err := os.ReadFile(filepath.Join(srcHome, "private-keys-v1.d", grip+".key"))
+		if os.IsNotExist(err) {
+			continue // a subkey whose secret part is not on this machine
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read secret key: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(keysDir, grip+".key"), data, 0600); err != nil {
+			return fmt.Errorf("failed to copy secret key: %w", err)
+		}
+	}
+	keyPath := filepath.Join(home, "key.asc")
+	if err := os.WriteFile(keyPath, publicKey, 0600); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+	if _, err := tmp.run("--batch", "--import", "--", keyPath); err != nil {
+		return fmt.Errorf("failed to import key: %w", err)
+	}
+
+	tmp.TrustModel, tmp.NoForceTrust = "always", false
+	ciphertext, err := tmp.Encrypt([]byte("secrets-cli passphrase check\n"), []string{id})
+	if err != nil {
+		return err
+	}
+	tmp.Batch = true
+	tmp.Passphrase = passphrase
+	if _, err := tmp.DecryptBytes(ciphertext); err != nil {
+		return fmt.Errorf("the passphrase does not unlock the secret key of %s", id)
+	}
+	return nil
+}
+
+// homeDir returns the GnuPG home directory gpg uses
+func (g *GPG) homeDir() (string, error) {
+	if g.Home != "" {
+		return g.Home, nil
+	}
+	cmd := exec.Command("gpgconf", "--list-dirs", "homedir")
+	cmd.Env = g.Env()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the GnuPG home directory: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,48 @@
+package gpg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// RetryBackoff is the delay before the first retry; it doubles on each retry
+var RetryBackoff = 250 * time.Millisecond
+
+// transientPatterns are lowercase stderr fragments of gpg-agent failures
+// that usually succeed when simply retried
+var transientPatterns = []string{
+	"inappropriate ioctl for device",
+	"agent refused",
+	"device or resource busy",
+	"no pinentry",
+}
+
+// IsTransient reports whether gpg's stderr indicates a transient agent error
+func IsTransient(stderr string) bool {
+	lower := strings.ToLower(stderr)
+	for _, pattern := range transientPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Retry calls attempt until it succeeds, fails with a non-transient error, or
+// has been retried retries times, backing off exponentially in between.
+// attempt returns the command's stderr so the error can be classified.
+func Retry(retries int, attempt func() (stderr string, err error)) error {
+	for i := 0; ; i++ {
+		stderr, err := attempt()
+		if err == nil || i >= retries || !IsTransient(stderr) {
+			return err
+		}
+		delay := RetryBackoff << i
+		if verbose {
+			fmt.Fprintf(os.Stderr, "  transient gpg error, retrying in %s (%d/%d)\n", delay, i+1, retries)
+		}
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,113 @@
+package gpg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// ErrTimeout is returned when a gpg or pass process runs past the timeout
+// set with SetTimeout
+var ErrTimeout = errors.New("timed out")
+
+// ErrInterrupted is returned by gpg and pass processes stopped by Interrupt
+var ErrInterrupted = errors.New("interrupted")
+
+var (
+	timeout time.Duration
+
+	// interrupted is cancelled by Interrupt and bounds every command
+	interrupted, interrupt = context.WithCancel(context.Background())
+)
+
+// SetTimeout bounds every gpg and pass process. When a process runs longer
+// its whole process group is killed, which also stops a gpg-agent pinentry
+// it is waiting on. Zero, the default, disables the timeout.
+func SetTimeout(d time.Duration) {
+	timeout = d
+}
+
+// Interrupt kills every running gpg and pass process and makes any started
+// later fail at once with ErrInterrupted. It is meant for a process that
+// received SIGINT or SIGTERM and is about to exit.
+func Interrupt() {
+	interrupt()
+}
+
+// Cmd is an exec.Cmd that is killed, together with its children, when the
+// timeout set with SetTimeout expires
+type Cmd struct {
+	*exec.Cmd
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closers []io.Closer // Released once the command has run
+}
+
+// NewCommand builds a command bounded by the timeout. The deadline starts
+// when the command is created, so create it right before running it.
+func NewCommand(name string, args ...string) *Cmd {
+	if timeout <= 0 {
+		cmd := exec.CommandContext(interrupted, name, args...)
+		cmd.WaitDelay = time.Second
+		return &Cmd{Cmd: cmd, ctx: interrupted, cancel: func() {}}
+	}
+
+	ctx, cancel := context.WithTimeout(interrupted, timeout)
+	cmd := exec.CommandContext(ctx, name, args...)
+	// A separate process group lets the kill reach the children as well.
+	// It is only used with a timeout: a background process group cannot
+	// read the terminal, which an interactive pinentry needs.
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessGroup(cmd) }
+	// Do not wait forever for children that keep stdout or stderr open
+	cmd.WaitDelay = time.Second
+	return &Cmd{Cmd: cmd, ctx: ctx, cancel: cancel}
+}
+
+// Run starts the command and waits for it, reporting an expired timeout as
+// ErrTimeout
+func (c *Cmd) Run() error {
+	defer c.release()
+	return c.wrap(c.Cmd.Run())
+}
+
+// Output runs the command and returns its stdout, reporting an expired
+// timeout as ErrTimeout
+func (c *Cmd) Output() ([]byte, error) {
+	defer c.release()
+	out, err := c.Cmd.Output()
+	return out, c.wrap(err)
+}
+
+func (c *Cmd) release() {
+	c.cancel()
+	for _, closer := range c.closers {
+		closer.Close()
+	}
+}
+
+func (c *Cmd) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case interrupted.Err() != nil:
+		err = fmt.Errorf("%s %w", filepath.Base(c.Path), ErrInterrupted)
+	case errors.Is(c.ctx.Err(), context.DeadlineExceeded):
+		err = fmt.Errorf("%s %w after %s (is gpg-agent waiting for a passphrase?)", filepath.Base(c.Path), ErrTimeout, timeout)
+	}
+	return &CommandError{Err: err}
+}
+
+// CommandError marks the failure of a gpg or pass process, as opposed to
+// errors of the caller, so it can be reported as such
+type CommandError struct {
+	Err error
+}
+
+func (e *CommandError) Error() string { return e.Err.Error() }
+func (e *CommandError) Unwrap() error { return e.Err }
@@ -0,0 +1,47 @@
+package gpg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunTimeout(t *testing.T) {
+	// The fake gpg leaves a child holding stdout open, like gpg waiting on
+	// gpg-agent. Only killing the whole process group ends it promptly.
+	bin := filepath.Join(t.TempDir(), "gpg")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\nsleep 30 &\nwait\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake gpg: %v", err)
+	}
+
+	SetTimeout(100 * time.Millisecond)
+	defer SetTimeout(0)
+
+	start := time.Now()
+	_, err := New(bin).run("--decrypt")
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("run() error = %v, want ErrTimeout", err)
+	}
+	if elapsed > 900*time.Millisecond {
+		t.Errorf("run() returned after %s, want the process group killed right at the timeout", elapsed)
+	}
+}
+
+func TestNoTimeoutByDefault(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "gpg")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\nsleep 0.2\necho ok\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake gpg: %v", err)
+	}
+
+	out, err := New(bin).run("--version")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if out != "ok\n" {
+		t.Errorf("run() = %q, want ok", out)
+	}
+}
@@ -0,0 +1,49 @@
+package gpg
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var verbose bool
+
+// SetVerbose enables tracing of every gpg command line to stderr
+func SetVerbose(v bool) {
+	verbose = v
+}
+
+// FormatCommand renders environment overrides and an argument vector as a
+// shell-like command line for tracing. Only pass it values that are safe to
+// print; secret values must never be part of argv or the overrides.
+func FormatCommand(envOverrides, argv []string) string {
+	parts := make([]string, 0, len(envOverrides)+len(argv))
+	for _, kv := range envOverrides {
+		k, v, _ := strings.Cut(kv, "=")
+		parts = append(parts, k+"="+shellQuote(v))
+	}
+	for _, arg := range argv {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"$`\\!#&|;<>(){}[]*?") {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// trace prints a command line to stderr when tracing is enabled
+func (g *GPG) trace(argv []string) {
+	if !verbose {
+		return
+	}
+	var overrides []string
+	if g.Home != "" {
+		overrides = append(overrides, "GNUPGHOME="+g.Home)
+	}
+	fmt.Fprintf(os.Stderr, "+ %s\n", FormatCommand(overrides, argv))
+}
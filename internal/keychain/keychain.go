@@ -0,0 +1,106 @@
+// Package keychain stores and retrieves secrets in the operating system keychain.
+//
+// It shells out to the platform tools rather than linking against native
+// libraries: security(1) on macOS and secret-tool(1) (libsecret) on Linux.
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Service is the keychain service name used for all secrets-cli entries
+const Service = "secrets-cli"
+
+// ErrNotFound is returned when no keychain entry exists for an account
+var ErrNotFound = errors.New("keychain entry not found")
+
+// Get retrieves the secret stored for the given account
+func Get(account string) (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := run("", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+		if err != nil {
+			return "", ErrNotFound
+		}
+		return strings.TrimSuffix(out, "\n"), nil
+	case "linux", "freebsd", "openbsd":
+		out, err := run("", "secret-tool", "lookup", "service", Service, "account", account)
+		if err != nil || out == "" {
+			return "", ErrNotFound
+		}
+		return out, nil
+	default:
+		return "", unsupported()
+	}
+}
+
+// Set stores a secret for the given account, replacing any existing entry.
+// The secret is always passed on stdin so it never appears in the process list.
+func Set(account, secret string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		// security(1) only accepts the password as an argument, so drive it
+		// through its interactive mode to keep the value off the command line
+		script := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			quoteSecurityArg(Service), quoteSecurityArg(account), quoteSecurityArg(secret))
+		_, err := run(script, "security", "-i")
+		return err
+	case "linux", "freebsd", "openbsd":
+		label := fmt.Sprintf("%s GPG passphrase for %s", Service, account)
+		_, err := run(secret, "secret-tool", "store", "--label", label, "service", Service, "account", account)
+		return err
+	default:
+		return unsupported()
+	}
+}
+
+// Delete removes the entry stored for the given account
+func Delete(account string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := run("", "security", "delete-generic-password", "-s", Service, "-a", account)
+		return err
+	case "linux", "freebsd", "openbsd":
+		_, err := run("", "secret-tool", "clear", "service", Service, "account", account)
+		return err
+	default:
+		return unsupported()
+	}
+}
+
+// run executes a keychain tool, optionally feeding it stdin
+func run(stdin string, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("keychain tool %s not found in PATH", name)
+	}
+
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s error: %s: %w", name, strings.TrimSpace(stderr.String()), err)
+	}
+
+	return stdout.String(), nil
+}
+
+// quoteSecurityArg quotes an argument for security(1) interactive mode
+func quoteSecurityArg(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func unsupported() error {
+	return fmt.Errorf("system keychain is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,24 @@
+package keychain
+
+import "testing"
+
+func TestQuoteSecurityArg(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "secret", `"secret"`},
+		{"spaces", "my secret", `"my secret"`},
+		{"double quote", `a"b`, `"a\"b"`},
+		{"backslash", `a\b`, `"a\\b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteSecurityArg(tt.input); got != tt.want {
+				t.Errorf("quoteSecurityArg(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
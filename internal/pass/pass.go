@@ -3,137 +3,588 @@ package pass
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
 )
 
 // Pass wraps pass command execution
 type Pass struct {
-	StoreDir string // PASSWORD_STORE_DIR
+	StoreDir string   // PASSWORD_STORE_DIR
+	GPG      *gpg.GPG // Used to read secrets directly when pass is unavailable
+
+	// Passphrase, when set, is fed to gpg through loopback pinentry on a
+	// dedicated file descriptor instead of prompting via the agent
+	Passphrase string
+
+	// Fingerprints makes Init and ReInit write each recipient's full key
+	// fingerprint to .gpg-id instead of the email it was given as
+	Fingerprints bool
+
+	// FollowSymlinks makes listings descend into symlinked directories
+	// that resolve inside the store, see listDir
+	FollowSymlinks bool
+
+	// VaultGPGOpts are the vault's algorithm options from vault.yaml. They
+	// come before the trust model in PASSWORD_STORE_GPG_OPTS, so they can
+	// never override it.
+	VaultGPGOpts []string
+
+	// GPGOpts are the user's own extra gpg options (--gpg-opts), appended
+	// after the trust model so they win over it
+	GPGOpts []string
+
+	cache *valueCache // Set by EnableCache
+
+	listMu  sync.Mutex
+	listing []string // The last List, until a write through p
+}
+
+// valueCache holds decrypted values for the lifetime of a Pass
+type valueCache struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// EnableCache keeps decrypted values in memory for the lifetime of p, so a
+// secret read more than once in a run is decrypted once. Writes through p
+// drop the values they affect. Nothing is ever written to disk.
+func (p *Pass) EnableCache() {
+	p.cache = &valueCache{values: map[string]string{}}
+}
+
+// forget drops the cached values of names and of any secrets below them,
+// and the cached listing, after a write
+func (p *Pass) forget(names ...string) {
+	p.listMu.Lock()
+	p.listing = nil
+	p.listMu.Unlock()
+
+	if p.cache == nil {
+		return
+	}
+	p.cache.mu.Lock()
+	defer p.cache.mu.Unlock()
+	for cached := range p.cache.values {
+		for _, name := range names {
+			if cached == name || strings.HasPrefix(cached, name+"/") {
+				delete(p.cache.values, cached)
+			}
+		}
+	}
+}
+
+var (
+	verbose     bool
+	looseVerify bool
+)
+
+// SetVerbose enables tracing of every pass command line to stderr. Values
+// passed on stdin are never printed.
+func SetVerbose(v bool) {
+	verbose = v
+}
+
+// SetLooseVerify makes VerifyEncryption only count recipients instead of
+// matching their key IDs, for gpg versions whose packet listing cannot be
+// matched against the keyring
+func SetLooseVerify(v bool) {
+	looseVerify = v
 }
 
 // New creates a new Pass wrapper for a specific store directory
 func New(storeDir string) *Pass {
-	return &Pass{StoreDir: storeDir}
+	return &Pass{StoreDir: storeDir, GPG: gpg.New("")}
+}
+
+// gpgTool returns the gpg wrapper, falling back to the default binary for a
+// zero-value Pass
+func (p *Pass) gpgTool() *gpg.GPG {
+	if p.GPG == nil {
+		return gpg.New("")
+	}
+	return p.GPG
 }
 
 // run executes a pass command with PASSWORD_STORE_DIR set
 func (p *Pass) run(args ...string) (string, error) {
-	cmd := exec.Command("pass", args...)
-	// Preserve existing PASSWORD_STORE_GPG_OPTS and append --trust-model always
-	existingOpts := os.Getenv("PASSWORD_STORE_GPG_OPTS")
-	gpgOpts := "--trust-model always"
-	if existingOpts != "" {
-		gpgOpts = existingOpts + " " + gpgOpts
-	}
-	cmd.Env = append(os.Environ(),
-		"PASSWORD_STORE_DIR="+p.StoreDir,
-		"PASSWORD_STORE_GPG_OPTS="+gpgOpts,
-	)
+	return p.exec(nil, args...)
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// runWithStdin executes a pass command with stdin input
+func (p *Pass) runWithStdin(input string, args ...string) (string, error) {
+	return p.exec(strings.NewReader(input), args...)
+}
 
-	if err := cmd.Run(); err != nil {
-		errMsg := strings.TrimSpace(stderr.String())
-		if errMsg == "" {
-			errMsg = err.Error()
+// exec runs pass with the store environment applied, retrying transient
+// gpg-agent errors up to GPG.Retries times
+func (p *Pass) exec(stdin io.Reader, args ...string) (string, error) {
+	// Buffer stdin so every attempt gets the full input
+	var input []byte
+	if stdin != nil {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
 		}
-		return "", fmt.Errorf("pass error: %s", errMsg)
+		input = data
 	}
 
-	return strings.TrimSpace(stdout.String()), nil
+	var stdout string
+	var runErr error
+	err := gpg.Retry(p.gpgTool().Retries, func() (string, error) {
+		out, stderr, err := p.execOnce(input, stdin != nil, args...)
+		stdout, runErr = out, err
+		return stderr, err
+	})
+	if err != nil {
+		return "", runErr
+	}
+	return stdout, nil
 }
 
-// runWithStdin executes a pass command with stdin input
-func (p *Pass) runWithStdin(input string, args ...string) (string, error) {
-	cmd := exec.Command("pass", args...)
-	// Preserve existing PASSWORD_STORE_GPG_OPTS and append --trust-model always
-	existingOpts := os.Getenv("PASSWORD_STORE_GPG_OPTS")
-	gpgOpts := "--trust-model always"
-	if existingOpts != "" {
-		gpgOpts = existingOpts + " " + gpgOpts
-	}
-	cmd.Env = append(os.Environ(),
-		"PASSWORD_STORE_DIR="+p.StoreDir,
-		"PASSWORD_STORE_GPG_OPTS="+gpgOpts,
-	)
-	cmd.Stdin = strings.NewReader(input)
+// gpgOptsEnv composes PASSWORD_STORE_GPG_OPTS from gpgOptions
+func (p *Pass) gpgOptsEnv(existing string) string {
+	return strings.Join(p.gpgOptions(existing), " ")
+}
+
+// gpgOptions returns the gpg options pass runs with: the existing
+// PASSWORD_STORE_GPG_OPTS, p.VaultGPGOpts, the trust model, the batch
+// options, then p.GPGOpts. gpg honors the last of repeated options, so
+// later ones take precedence. pass splits the variable into words.
+func (p *Pass) gpgOptions(existing string) []string {
+	opts := strings.Fields(existing)
+	opts = append(opts, p.VaultGPGOpts...)
+	opts = append(opts, p.gpgTool().TrustArgs()...)
+	opts = append(opts, p.gpgTool().BatchArgs()...)
+	return append(opts, p.GPGOpts...)
+}
+
+// passphraseCommands are the pass commands given the passphrase. Each runs
+// at most one gpg that decrypts. pass init instead pipes a decrypting gpg
+// into an encrypting one for every secret, all sharing the one passphrase
+// pipe, so with a passphrase ReInit and InitPath re-encrypt with gpg
+// directly.
+var passphraseCommands = map[string]bool{"show": true, "mv": true, "cp": true}
+
+// passphrase returns the passphrase to feed gpg, if any
+func (p *Pass) passphrase() string {
+	if p.Passphrase != "" {
+		return p.Passphrase
+	}
+	return p.gpgTool().Passphrase
+}
+
+// execOnce runs a single pass invocation and returns its trimmed stdout and
+// raw stderr
+func (p *Pass) execOnce(input []byte, hasStdin bool, args ...string) (string, string, error) {
+	cmd := gpg.NewCommand("pass", args...)
+	gpgOpts := p.gpgOptsEnv(os.Getenv("PASSWORD_STORE_GPG_OPTS"))
+
+	if passphrase := p.passphrase(); passphrase != "" && passphraseCommands[args[0]] {
+		// Hand the passphrase over on fd 3 so it never appears in argv or env
+		r, err := gpg.PassphrasePipe(passphrase)
+		if err != nil {
+			return "", "", err
+		}
+		defer r.Close()
+		cmd.ExtraFiles = []*os.File{r}
+		gpgOpts += " --pinentry-mode loopback --passphrase-fd 3"
+	}
+
+	overrides := []string{
+		"PASSWORD_STORE_DIR=" + p.StoreDir,
+		"PASSWORD_STORE_GPG_OPTS=" + gpgOpts,
+	}
+	cmd.Env = append(p.gpgTool().Env(), overrides...)
+	if hasStdin {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+
+	if verbose {
+		if home := p.gpgTool().Home; home != "" {
+			overrides = append(overrides, "GNUPGHOME="+home)
+		}
+		line := gpg.FormatCommand(overrides, cmd.Args)
+		if hasStdin {
+			line += " <<< [stdin redacted]"
+		}
+		fmt.Fprintf(os.Stderr, "+ %s\n", line)
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		errMsg := strings.TrimSpace(stderr.String())
-		if errMsg == "" {
-			errMsg = err.Error()
+		if errors.Is(err, gpg.ErrTimeout) {
+			return "", stderr.String(), fmt.Errorf("pass error: %w", err)
+		}
+		if errMsg := strings.TrimSpace(stderr.String()); errMsg != "" {
+			return "", stderr.String(), fmt.Errorf("pass error: %s: %w", errMsg, err)
+		}
+		return "", stderr.String(), fmt.Errorf("pass error: %w", err)
+	}
+
+	// Only trailing whitespace is trimmed: a multi-field secret without a
+	// password starts with an empty line that must survive
+	return strings.TrimRightFunc(stdout.String(), unicode.IsSpace), stderr.String(), nil
+}
+
+// ResolveGPGIDs returns the recipients to write to .gpg-id for gpgIDs. With
+// Fingerprints set, each one is replaced by its key's full fingerprint.
+func (p *Pass) ResolveGPGIDs(gpgIDs []string) ([]string, error) {
+	if !p.Fingerprints {
+		return gpgIDs, nil
+	}
+	resolved := make([]string, 0, len(gpgIDs))
+	for _, id := range gpgIDs {
+		if gpg.IsFingerprint(id) {
+			resolved = append(resolved, strings.ToUpper(id))
+			continue
 		}
-		return "", fmt.Errorf("pass error: %s", errMsg)
+		fpr, err := p.gpgTool().GetFingerprint(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s to a fingerprint: %w", id, err)
+		}
+		resolved = append(resolved, fpr)
 	}
+	return resolved, nil
+}
 
-	return strings.TrimSpace(stdout.String()), nil
+// WriteGPGIDs replaces the store's .gpg-id without re-encrypting anything.
+// Use it only when the new IDs name the same keys as the old ones.
+func (p *Pass) WriteGPGIDs(gpgIDs []string) error {
+	gpgIDPath := filepath.Join(p.StoreDir, ".gpg-id")
+	content := strings.Join(gpgIDs, "\n") + "\n"
+	if err := os.WriteFile(gpgIDPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write .gpg-id: %w", err)
+	}
+	return nil
+}
+
+// SetRecipients writes gpgIDs to .gpg-id of a store without secrets, where
+// there is nothing to re-encrypt. It fails if the store holds any secret;
+// use ReInit for those.
+func (p *Pass) SetRecipients(gpgIDs []string) error {
+	secrets, err := p.ListUnsorted()
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	if len(secrets) > 0 {
+		return fmt.Errorf("store holds %d secret(s) that must be re-encrypted", len(secrets))
+	}
+	if gpgIDs, err = p.ResolveGPGIDs(gpgIDs); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(p.StoreDir, 0700); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return p.WriteGPGIDs(gpgIDs)
 }
 
 // Init initializes the password store with GPG IDs
 func (p *Pass) Init(gpgIDs []string) error {
+	gpgIDs, err := p.ResolveGPGIDs(gpgIDs)
+	if err != nil {
+		return err
+	}
 	args := append([]string{"init", "--"}, gpgIDs...)
-	_, err := p.run(args...)
+	_, err = p.run(args...)
 	return err
 }
 
 // Insert adds or updates a secret (overwrites if exists)
 func (p *Pass) Insert(name, value string) error {
+	if err := p.CheckShadow(name); err != nil {
+		return err
+	}
 	// Use insert with multiline and force to overwrite
+	defer p.forget(name)
 	_, err := p.runWithStdin(value, "insert", "--multiline", "--force", "--", name)
 	return err
 }
 
-// Show retrieves a secret value
+// InsertField sets one "key: value" field of a multi-field secret, keeping
+// the first-line password and every other line. An existing field with the
+// same key is updated in place, otherwise the field is appended. A secret
+// that does not exist yet is created with an empty password line.
+func (p *Pass) InsertField(name, key, value string) error {
+	if key == "" || strings.ContainsAny(key, ": \t\n") {
+		return fmt.Errorf("invalid field name: %q", key)
+	}
+	if strings.Contains(value, "\n") {
+		return fmt.Errorf("field value cannot contain a newline")
+	}
+
+	content := ""
+	if p.Exists(name) {
+		existing, err := p.Show(name)
+		if err != nil {
+			return err
+		}
+		content = existing
+	}
+	return p.Insert(name, setField(content, key, value))
+}
+
+// setField returns content with the field key set to value, see InsertField
+func setField(content, key, value string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	line := key + ": " + value
+	for i := 1; i < len(lines); i++ {
+		k, _, ok := strings.Cut(lines[i], ":")
+		if ok && strings.TrimSpace(k) == key {
+			lines[i] = line
+			return strings.Join(lines, "\n")
+		}
+	}
+	return strings.Join(append(lines, line), "\n")
+}
+
+// CheckShadow reports an error if writing name would collide with the store's
+// directory namespace: either name is already a directory of secrets (api vs
+// api/key), or one of its parent paths is already a secret (api/key vs api)
+func (p *Pass) CheckShadow(name string) error {
+	if info, err := os.Stat(filepath.Join(p.StoreDir, name)); err == nil && info.IsDir() {
+		children, _ := p.listDir(name)
+		return fmt.Errorf("cannot create secret %s: it is already a directory containing %s. Choose a different name, e.g. %s/value",
+			name, strings.Join(children, ", "), name)
+	}
+
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		parent := strings.Join(parts[:i], "/")
+		if _, err := os.Stat(filepath.Join(p.StoreDir, parent+".gpg")); err == nil {
+			return fmt.Errorf("cannot create secret %s: %s is already a secret, so it cannot also be a directory. Rename %s first",
+				name, parent, parent)
+		}
+	}
+	return nil
+}
+
+// Show retrieves a secret value.
+// If the pass binary is not installed, the secret file is decrypted
+// directly with gpg so reads still work in minimal environments.
 func (p *Pass) Show(name string) (string, error) {
+	if p.cache == nil {
+		return p.show(name)
+	}
+
+	p.cache.mu.Lock()
+	value, ok := p.cache.values[name]
+	p.cache.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := p.show(name)
+	if err != nil {
+		return "", err
+	}
+	p.cache.mu.Lock()
+	p.cache.values[name] = value
+	p.cache.mu.Unlock()
+	return value, nil
+}
+
+func (p *Pass) show(name string) (string, error) {
+	if _, err := exec.LookPath("pass"); err != nil {
+		secretPath := filepath.Join(p.StoreDir, name+".gpg")
+		if _, statErr := os.Stat(secretPath); statErr == nil {
+			return p.gpgTool().DecryptFile(secretPath)
+		}
+	}
 	return p.run("show", "--", name)
 }
 
-// Exists checks if a secret exists
+// ShowRaw returns the exact decrypted bytes of a secret. Show trims
+// trailing whitespace, which corrupts binary and whitespace-significant
+// values; ShowRaw decrypts the file with gpg directly, as pass show does,
+// and bypasses the cache.
+func (p *Pass) ShowRaw(name string) ([]byte, error) {
+	secretPath := filepath.Join(p.StoreDir, filepath.FromSlash(name)+".gpg")
+	if _, err := os.Stat(secretPath); err != nil {
+		return nil, fmt.Errorf("%s is not in the password store", name)
+	}
+	return p.gpgTool().Decrypt(secretPath)
+}
+
+// ShowBatchWorkers is how many secrets ShowBatch decrypts concurrently
+var ShowBatchWorkers = 4
+
+// ShowBatch decrypts several secrets concurrently and returns their values
+// keyed by name. It fails on the first secret in names that cannot be
+// decrypted.
+func (p *Pass) ShowBatch(names []string) (map[string]string, error) {
+	values, errs := p.ShowEach(names)
+	for _, name := range names {
+		if err, ok := errs[name]; ok {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+	}
+	return values, nil
+}
+
+// ShowEach decrypts several secrets concurrently like ShowBatch, but keeps
+// going past failures. It returns the values and the errors keyed by name.
+func (p *Pass) ShowEach(names []string) (map[string]string, map[string]error) {
+	type result struct {
+		name, value string
+		err         error
+	}
+
+	jobs := make(chan string)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for i := 0; i < min(ShowBatchWorkers, len(names)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				value, err := p.Show(name)
+				results <- result{name, value, err}
+			}
+		}()
+	}
+	go func() {
+		for _, name := range names {
+			jobs <- name
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	values := make(map[string]string, len(names))
+	errs := map[string]error{}
+	for r := range results {
+		if r.err != nil {
+			errs[r.name] = r.err
+			continue
+		}
+		values[r.name] = r.value
+	}
+	return values, errs
+}
+
+// Exists checks if a secret exists by looking for its .gpg file. It never
+// decrypts, so it works even if the secret cannot be decrypted.
 func (p *Pass) Exists(name string) bool {
-	_, err := p.run("show", "--", name)
-	return err == nil
+	info, err := os.Stat(filepath.Join(p.StoreDir, name+".gpg"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Field is a "key: value" line in a multi-field secret
+type Field struct {
+	Key   string
+	Value string
+}
+
+// ParseFields splits a secret following the pass multi-field convention into
+// its first-line password and the "key: value" fields on the lines after it.
+// Lines without a colon are free-form notes and are ignored.
+func ParseFields(content string) (string, []Field) {
+	lines := strings.Split(content, "\n")
+	password := lines[0]
+
+	var fields []Field
+	for _, line := range lines[1:] {
+		key, value, ok := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			continue
+		}
+		fields = append(fields, Field{Key: key, Value: strings.TrimSpace(value)})
+	}
+
+	return password, fields
 }
 
 // Remove deletes a secret
 func (p *Pass) Remove(name string) error {
+	defer p.forget(name)
 	_, err := p.run("rm", "--force", "--", name)
 	return err
 }
 
 // Move renames a secret
 func (p *Pass) Move(oldName, newName string) error {
+	defer p.forget(oldName, newName)
 	_, err := p.run("mv", "--force", "--", oldName, newName)
 	return err
 }
 
 // Copy copies a secret
 func (p *Pass) Copy(srcName, dstName string) error {
+	defer p.forget(dstName)
 	_, err := p.run("cp", "--force", "--", srcName, dstName)
 	return err
 }
 
-// List returns all secret names in the store
+// List returns all secret names in the store, sorted lexicographically so
+// output is the same on every platform
 func (p *Pass) List() ([]string, error) {
+	secrets, err := p.listDir("")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(secrets)
+
+	p.listMu.Lock()
+	p.listing = secrets
+	p.listMu.Unlock()
+	return append([]string(nil), secrets...), nil
+}
+
+// ListCached is List without walking the store again if p listed it
+// before. Inserts, removals, moves and copies through p drop the cached
+// listing, but changes made to the store by other means are not seen: use
+// List where they matter.
+func (p *Pass) ListCached() ([]string, error) {
+	p.listMu.Lock()
+	listing := p.listing
+	p.listMu.Unlock()
+	if listing == nil {
+		return p.List()
+	}
+	return append([]string(nil), listing...), nil
+}
+
+// ListUnsorted returns all secret names in filesystem walk order
+func (p *Pass) ListUnsorted() ([]string, error) {
 	return p.listDir("")
 }
 
-// listDir lists secrets recursively from a directory
+// listDir lists secrets recursively from a directory. Symlinks never lead
+// out of the store: symlinked .gpg files are listed only if they resolve
+// inside it, and symlinked directories are skipped unless FollowSymlinks is
+// set, and then too if they resolve outside the store or would loop.
 func (p *Pass) listDir(prefix string) ([]string, error) {
-	dir := p.StoreDir
-	if prefix != "" {
-		dir = filepath.Join(dir, prefix)
+	root, err := filepath.EvalSymlinks(p.StoreDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %w", err)
 	}
+	current, err := filepath.EvalSymlinks(filepath.Join(p.StoreDir, prefix))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	return p.walkDir(root, prefix, map[string]bool{current: true})
+}
+
+// walkDir lists the secrets below prefix. seen holds the resolved
+// directories being walked, from the top down to prefix: reaching one of
+// them again through a symlink would loop, so that link is skipped.
+func (p *Pass) walkDir(root, prefix string, seen map[string]bool) ([]string, error) {
+	dir := filepath.Join(p.StoreDir, prefix)
 
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -154,9 +605,35 @@ func (p *Pass) listDir(prefix string) ([]string, error) {
 			fullPath = filepath.Join(prefix, name)
 		}
 
-		if entry.IsDir() {
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(filepath.Join(dir, name))
+			if err != nil || !within(target, root) {
+				continue
+			}
+			info, err := os.Stat(target)
+			if err != nil {
+				continue
+			}
+			if info.IsDir() {
+				// A link to its own directory or one above it would loop
+				current, err := filepath.EvalSymlinks(dir)
+				if !p.FollowSymlinks || err != nil || within(current, target) {
+					continue
+				}
+				isDir = true
+			}
+		}
+
+		if isDir {
 			// Recurse into subdirectories
-			subSecrets, err := p.listDir(fullPath)
+			resolved, err := filepath.EvalSymlinks(filepath.Join(dir, name))
+			if err != nil || seen[resolved] {
+				continue
+			}
+			seen[resolved] = true
+			subSecrets, err := p.walkDir(root, fullPath, seen)
+			delete(seen, resolved)
 			if err != nil {
 				continue
 			}
@@ -171,13 +648,25 @@ func (p *Pass) listDir(prefix string) ([]string, error) {
 	return secrets, nil
 }
 
-// ReInit re-initializes the store with new GPG IDs (re-encrypts all secrets)
+// within reports whether path is dir or below it; both must be clean
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// ReInit re-initializes the store with new GPG IDs (re-encrypts all secrets).
+// With a passphrase it re-encrypts with gpg directly, see passphraseCommands.
 func (p *Pass) ReInit(gpgIDs []string) error {
+	if p.passphrase() != "" {
+		return p.ReInitParallel(gpgIDs, 1)
+	}
+	gpgIDs, err := p.ResolveGPGIDs(gpgIDs)
+	if err != nil {
+		return err
+	}
+
 	// Write new .gpg-id file
-	gpgIDPath := filepath.Join(p.StoreDir, ".gpg-id")
-	content := strings.Join(gpgIDs, "\n") + "\n"
-	if err := os.WriteFile(gpgIDPath, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write .gpg-id: %w", err)
+	if err := p.WriteGPGIDs(gpgIDs); err != nil {
+		return err
 	}
 
 	// Re-init to re-encrypt all secrets
@@ -186,15 +675,21 @@ func (p *Pass) ReInit(gpgIDs []string) error {
 		return err
 	}
 
-	// Verify re-encryption succeeded for at least one secret
-	secrets, err := p.List()
+	// Verify re-encryption succeeded for at least one secret. pass init
+	// changes no names, so an earlier listing is still good.
+	secrets, err := p.ListCached()
 	if err != nil {
 		return fmt.Errorf("failed to list secrets after re-init: %w", err)
 	}
 
-	// If there are secrets, verify at least the first one is encrypted correctly
+	// If there are secrets, verify at least the first one is encrypted
+	// correctly. It may sit below a subdirectory with its own .gpg-id.
 	if len(secrets) > 0 {
-		if err := p.VerifyEncryption(secrets[0], gpgIDs); err != nil {
+		expected, err := p.GPGIDsFor(secrets[0])
+		if err != nil {
+			return err
+		}
+		if err := p.VerifyEncryption(secrets[0], expected); err != nil {
 			return fmt.Errorf("re-encryption verification failed: %w", err)
 		}
 	}
@@ -202,52 +697,303 @@ func (p *Pass) ReInit(gpgIDs []string) error {
 	return nil
 }
 
-// VerifyEncryption checks if a secret is encrypted for the expected GPG IDs.
-// It uses a count-based approach which is more robust across GPG versions than
-// trying to match exact key IDs (which can vary in format).
-func (p *Pass) VerifyEncryption(secretName string, expectedGPGIDs []string) error {
-secretPath := filepath.Join(p.StoreDir, secretName+".gpg")
+// ReInitParallel is ReInit without pass: gpg re-encrypts workers secrets
+// at a time, each for the recipients of its own directory and with the
+// options pass would use, replacing every file atomically. The new .gpg-id
+// is written only once every secret was re-encrypted. A failed secret does
+// not stop the others; the errors of all failures are returned.
+func (p *Pass) ReInitParallel(gpgIDs []string, workers int) error {
+	gpgIDs, err := p.ResolveGPGIDs(gpgIDs)
+	if err != nil {
+		return err
+	}
 
-// First, verify all expected GPG IDs exist in the keyring
-for _, gpgID := range expectedGPGIDs {
-cmd := exec.Command("gpg", "--list-keys", "--", gpgID)
-if err := cmd.Run(); err != nil {
-return fmt.Errorf("GPG ID %s not found in keyring: %w", gpgID, err)
+	secrets, err := p.ListCached()
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	if err := p.reencryptAll(secrets, workers, map[string][]string{".": gpgIDs}); err != nil {
+		return err
+	}
+	if err := p.WriteGPGIDs(gpgIDs); err != nil {
+		return err
+	}
+
+	if len(secrets) > 0 {
+		expected, err := p.GPGIDsFor(secrets[0])
+		if err != nil {
+			return err
+		}
+		if err := p.VerifyEncryption(secrets[0], expected); err != nil {
+			return fmt.Errorf("re-encryption verification failed: %w", err)
+		}
+	}
+	return nil
 }
+
+// reencryptAll re-encrypts secrets, workers at a time, for the recipients
+// pending holds for their directories, see gpgIDsFor. A failed secret does
+// not stop the others; the errors of all failures are returned.
+func (p *Pass) reencryptAll(secrets []string, workers int, pending map[string][]string) error {
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var failed []error
+	var wg sync.WaitGroup
+	for i := 0; i < min(max(workers, 1), len(secrets)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				if err := p.reencrypt(name, pending); err != nil {
+					mu.Lock()
+					failed = append(failed, fmt.Errorf("%s: %w", name, err))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, name := range secrets {
+		jobs <- name
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sort.Slice(failed, func(i, j int) bool { return failed[i].Error() < failed[j].Error() })
+		return fmt.Errorf("failed to re-encrypt %d of %d secret(s): %w", len(failed), len(secrets), errors.Join(failed...))
+	}
+	return nil
 }
 
-// Count recipients in the encrypted file
-cmd := exec.Command("gpg", "--list-packets", "--", secretPath)
-var stdout bytes.Buffer
-cmd.Stdout = &stdout
+// reencrypt decrypts one secret and encrypts it again for the recipients of
+// its directory, see gpgIDsFor. The plaintext never touches the disk, and
+// the new file replaces the old one only once it is complete.
+func (p *Pass) reencrypt(name string, pending map[string][]string) error {
+	recipients, err := p.gpgIDsFor(name, pending)
+	if err != nil {
+		return err
+	}
+	secretPath := filepath.Join(p.StoreDir, filepath.FromSlash(name)+".gpg")
+	info, err := os.Stat(secretPath)
+	if err != nil {
+		return err
+	}
+	g := p.gpgTool()
+	plaintext, err := g.Decrypt(secretPath)
+	if err != nil {
+		return err
+	}
+	// The same options pass would encrypt with
+	ciphertext, err := g.EncryptWithOptions(plaintext, recipients, p.gpgOptions(os.Getenv("PASSWORD_STORE_GPG_OPTS")))
+	if err != nil {
+		return err
+	}
 
-if err := cmd.Run(); err != nil {
-return fmt.Errorf("failed to list packets: %w", err)
+	tmp, err := os.CreateTemp(filepath.Dir(secretPath), ".reencrypt-*")
+	if err != nil {
+		return fmt.Errorf("failed to write re-encrypted secret: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(ciphertext); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write re-encrypted secret: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write re-encrypted secret: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), secretPath)
 }
 
-// Count how many encryption recipients are in the file
-// Each ":pubkey enc packet:" line represents one recipient
-keyIDRegex := regexp.MustCompile(`(?i):pubkey enc packet:`)
-matches := keyIDRegex.FindAllString(stdout.String(), -1)
-recipientCount := len(matches)
+// InitPath sets the recipients of the subdirectory path, relative to the
+// store, and re-encrypts only the secrets below it. Empty gpgIDs remove the
+// subdirectory's .gpg-id, so its secrets fall back to the recipients of the
+// nearest parent. Paths leading outside the store are refused.
+func (p *Pass) InitPath(path string, gpgIDs []string) error {
+	if !filepath.IsLocal(filepath.FromSlash(path)) {
+		return fmt.Errorf("path %q is not inside the password store", path)
+	}
+
+	gpgIDs, err := p.ResolveGPGIDs(gpgIDs)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(p.StoreDir, filepath.FromSlash(path))
+	secrets, err := p.listDir(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// Nothing to re-encrypt, so pass is not needed
+	if len(secrets) == 0 {
+		return writePathGPGIDs(dir, gpgIDs)
+	}
+
+	if p.passphrase() != "" {
+		// pass init cannot share the passphrase, see passphraseCommands
+		pending := map[string][]string{filepath.Clean(filepath.FromSlash(path)): gpgIDs}
+		if err := p.reencryptAll(secrets, 1, pending); err != nil {
+			return err
+		}
+		if err := writePathGPGIDs(dir, gpgIDs); err != nil {
+			return err
+		}
+	} else {
+		args := append([]string{"init", "--path=" + path, "--"}, gpgIDs...)
+		if len(gpgIDs) == 0 {
+			args = append(args, "")
+		}
+		if _, err := p.run(args...); err != nil {
+			return err
+		}
+	}
+
+	expected, err := p.GPGIDsFor(secrets[0])
+	if err != nil {
+		return err
+	}
+	if err := p.VerifyEncryption(secrets[0], expected); err != nil {
+		return fmt.Errorf("re-encryption verification failed: %w", err)
+	}
+	return nil
+}
 
-if recipientCount == 0 {
-return fmt.Errorf("no encryption recipients found in %s", secretName)
+// writePathGPGIDs writes gpgIDs to the .gpg-id of the store subdirectory
+// dir, or removes it if gpgIDs is empty
+func writePathGPGIDs(dir string, gpgIDs []string) error {
+	gpgIDPath := filepath.Join(dir, ".gpg-id")
+	if len(gpgIDs) == 0 {
+		if err := os.Remove(gpgIDPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove .gpg-id: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	content := strings.Join(gpgIDs, "\n") + "\n"
+	if err := os.WriteFile(gpgIDPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write .gpg-id: %w", err)
+	}
+	return nil
 }
 
-// Verify the count matches
-// Since we know pass was asked to encrypt to exactly these GPG IDs,
-// if the recipient count matches, encryption was successful
-if recipientCount != len(expectedGPGIDs) {
-return fmt.Errorf("secret %s is encrypted for %d recipients, but expected %d (GPG IDs: %v)",
-secretName, recipientCount, len(expectedGPGIDs), expectedGPGIDs)
+// VerifyEncryption checks that a secret is encrypted for exactly the expected
+// GPG IDs. The recipient key IDs listed in the file's packets are matched as
+// a set against the keys, and their subkeys, that the IDs name. With
+// SetLooseVerify only the number of recipients is compared.
+func (p *Pass) VerifyEncryption(secretName string, expectedGPGIDs []string) error {
+	secretPath := filepath.Join(p.StoreDir, secretName+".gpg")
+
+	// First, verify all expected GPG IDs exist in the keyring
+	keys := make([][]gpg.Key, 0, len(expectedGPGIDs))
+	for _, gpgID := range expectedGPGIDs {
+		found, err := p.gpgTool().LookupKeys(gpgID)
+		if err != nil {
+			return fmt.Errorf("GPG ID %s not found in keyring: %w", gpgID, err)
+		}
+		keys = append(keys, found)
+	}
+
+	// Count recipients in the encrypted file
+	recipientCount, err := p.gpgTool().CountRecipients(secretPath)
+	if err != nil {
+		return err
+	}
+
+	if recipientCount == 0 {
+		return fmt.Errorf("no encryption recipients found in %s", secretName)
+	}
+
+	if recipientCount != len(expectedGPGIDs) {
+		return fmt.Errorf("secret %s is encrypted for %d recipients, but expected %d (GPG IDs: %v)",
+			secretName, recipientCount, len(expectedGPGIDs), expectedGPGIDs)
+	}
+
+	if looseVerify {
+		return nil
+	}
+
+	recipients, err := p.gpgTool().RecipientKeyIDs(secretPath)
+	if err != nil {
+		return err
+	}
+	return matchRecipients(secretName, recipients, expectedGPGIDs, keys)
 }
 
-return nil
+// matchRecipients checks that every recipient key ID belongs to one of the
+// expected GPG IDs' keys and that every expected GPG ID is a recipient.
+// keys[i] holds the keys gpgIDs[i] resolves to.
+func matchRecipients(secretName string, recipients, gpgIDs []string, keys [][]gpg.Key) error {
+	matched := make([]bool, len(gpgIDs))
+	for _, recipient := range recipients {
+		found := false
+		for i := range gpgIDs {
+			for _, key := range keys[i] {
+				if key.HasKeyID(recipient) {
+					matched[i], found = true, true
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("secret %s is encrypted for key %s, which none of %v name (use --loose to verify by count only)",
+				secretName, recipient, gpgIDs)
+		}
+	}
+	for i, ok := range matched {
+		if !ok {
+			return fmt.Errorf("secret %s is not encrypted for %s (use --loose to verify by count only)", secretName, gpgIDs[i])
+		}
+	}
+	return nil
 }
 
 func (p *Pass) GetGPGIDs() ([]string, error) {
-	gpgIDPath := filepath.Join(p.StoreDir, ".gpg-id")
+	return readGPGIDs(filepath.Join(p.StoreDir, ".gpg-id"))
+}
+
+// PathGPGIDs returns the IDs in the .gpg-id of the subdirectory path itself,
+// without falling back to a parent's
+func (p *Pass) PathGPGIDs(path string) ([]string, error) {
+	return readGPGIDs(filepath.Join(p.StoreDir, filepath.FromSlash(path), ".gpg-id"))
+}
+
+// GPGIDsFor returns the recipients pass encrypts a secret for: those in the
+// .gpg-id of the secret's directory or its nearest parent, like pass does
+func (p *Pass) GPGIDsFor(name string) ([]string, error) {
+	return p.gpgIDsFor(name, nil)
+}
+
+// gpgIDsFor is GPGIDsFor with the .gpg-id files of the directories in
+// pending, keyed by path relative to the store ("." for its root), taken
+// to hold the IDs given there. Empty IDs stand for a removed .gpg-id.
+func (p *Pass) gpgIDsFor(name string, pending map[string][]string) ([]string, error) {
+	dir := filepath.Dir(filepath.FromSlash(name))
+	for dir != "." && dir != string(filepath.Separator) {
+		if ids, ok := pending[dir]; ok {
+			if len(ids) > 0 {
+				return ids, nil
+			}
+		} else {
+			gpgIDPath := filepath.Join(p.StoreDir, dir, ".gpg-id")
+			if _, err := os.Stat(gpgIDPath); err == nil {
+				return readGPGIDs(gpgIDPath)
+			}
+		}
+		dir = filepath.Dir(dir)
+	}
+	if ids, ok := pending["."]; ok {
+		return ids, nil
+	}
+	return p.GetGPGIDs()
+}
+
+// readGPGIDs reads the IDs in a .gpg-id file, one per line
+func readGPGIDs(gpgIDPath string) ([]string, error) {
 	data, err := os.ReadFile(gpgIDPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read .gpg-id: %w", err)
@@ -2,11 +2,15 @@ package pass
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/NuevaNext/secrets-cli/internal/gpg"
 )
 
 // TestVerifyEncryption tests the VerifyEncryption function with real GPG files
@@ -64,6 +68,64 @@ func TestVerifyEncryption(t *testing.T) {
 			t.Error("Expected verification to fail when not all recipients are present")
 		}
 	})
+
+	// Test 4: Same recipient count, but a different key
+	t.Run("SameCountWrongKey", func(t *testing.T) {
+		otherEmail := "other-recipient@example.com"
+		generateTestKey(t, otherEmail)
+		if err := p.VerifyEncryption(secretName, []string{otherEmail}); err == nil {
+			t.Error("Expected verification to fail for a different key with the same recipient count")
+		}
+
+		SetLooseVerify(true)
+		defer SetLooseVerify(false)
+		if err := p.VerifyEncryption(secretName, []string{otherEmail}); err != nil {
+			t.Errorf("Expected loose verification to only compare counts, got error: %v", err)
+		}
+	})
+
+	// Test 5: Fingerprints are matched against the actual recipient key IDs
+	t.Run("Fingerprint", func(t *testing.T) {
+		fpr, err := p.gpgTool().GetFingerprint(keyEmail)
+		if err != nil {
+			t.Fatalf("GetFingerprint() error = %v", err)
+		}
+		if err := p.VerifyEncryption(secretName, []string{fpr}); err != nil {
+			t.Errorf("Expected verification by fingerprint to succeed, got error: %v", err)
+		}
+
+		resolved, err := (&Pass{StoreDir: tmpDir, Fingerprints: true}).ResolveGPGIDs([]string{keyEmail})
+		if err != nil || len(resolved) != 1 || resolved[0] != fpr {
+			t.Errorf("ResolveGPGIDs() = %v, %v, want [%s]", resolved, err, fpr)
+		}
+	})
+}
+
+func TestParseFields(t *testing.T) {
+	content := "s3cret\nusername: admin\nurl: https://db.example.com:5432\nfree-form note\nempty:"
+
+	password, fields := ParseFields(content)
+	if password != "s3cret" {
+		t.Errorf("password = %q, want %q", password, "s3cret")
+	}
+
+	want := []Field{
+		{"username", "admin"},
+		{"url", "https://db.example.com:5432"},
+		{"empty", ""},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("got %d fields, want %d: %v", len(fields), len(want), fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("field %d = %+v, want %+v", i, fields[i], want[i])
+		}
+	}
+
+	if _, fields := ParseFields("just-a-password"); len(fields) != 0 {
+		t.Errorf("expected no fields for single-line secret, got %v", fields)
+	}
 }
 
 // generateTestKey generates a GPG key for testing
@@ -97,3 +159,807 @@ Expire-Date: 0
 		t.Fatalf("Failed to generate test key: %v\nStderr: %s", err, stderr.String())
 	}
 }
+
+func TestGNUPGHomeIsPassedToPass(t *testing.T) {
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s' \"$GNUPGHOME\"\n"
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	p := New(t.TempDir())
+	p.GPG.Home = "/tmp/isolated-gnupg"
+
+	out, err := p.run("ls")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if out != p.GPG.Home {
+		t.Errorf("pass child GNUPGHOME = %q, want %q", out, p.GPG.Home)
+	}
+}
+
+func TestTrustModelIsPassedToPass(t *testing.T) {
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s' \"$PASSWORD_STORE_GPG_OPTS\"\n"
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("PASSWORD_STORE_GPG_OPTS", "--armor")
+
+	tests := []struct {
+		name  string
+		model string
+		force bool
+		want  string
+	}{
+		{"Default", "", true, "--armor --trust-model always"},
+		{"Configured", "pgp", true, "--armor --trust-model pgp"},
+		{"NoForceTrust", "pgp", false, "--armor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(t.TempDir())
+			p.GPG.TrustModel = tt.model
+			p.GPG.NoForceTrust = !tt.force
+
+			out, err := p.run("ls")
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("PASSWORD_STORE_GPG_OPTS = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestGPGOptsArePassedToPass(t *testing.T) {
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s' \"$PASSWORD_STORE_GPG_OPTS\"\n"
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("PASSWORD_STORE_GPG_OPTS", "--armor")
+
+	p := New(t.TempDir())
+	p.GPG.TrustModel = "pgp"
+	p.GPG.Batch = true
+	p.VaultGPGOpts = []string{"--compress-algo", "none"}
+	p.GPGOpts = []string{"--trust-model", "always"}
+
+	out, err := p.run("ls")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "--armor --compress-algo none --trust-model pgp " + strings.Join(p.GPG.BatchArgs(), " ") + " --trust-model always"
+	if out != want {
+		t.Errorf("PASSWORD_STORE_GPG_OPTS = %q, want %q", out, want)
+	}
+}
+
+func TestPassphraseOnlyForDecryptingCommands(t *testing.T) {
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s|%s|' \"$PASSWORD_STORE_GPG_OPTS\" \"${GPG_PASSPHRASE-unset}\"\n[ -e /dev/fd/3 ] && printf fd3\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("PASSWORD_STORE_GPG_OPTS", "")
+	t.Setenv("GPG_PASSPHRASE", "secret")
+
+	p := New(t.TempDir())
+	p.Passphrase = "secret"
+
+	out, err := p.run("show", "--", "db/password")
+	if err != nil {
+		t.Fatalf("run(show) error = %v", err)
+	}
+	if want := "--pinentry-mode loopback --passphrase-fd 3|unset|fd3"; !strings.HasSuffix(out, want) {
+		t.Errorf("show got %q, want the passphrase on fd 3 and no GPG_PASSPHRASE", out)
+	}
+
+	out, err = p.run("init", "--", "alice@example.com")
+	if err != nil {
+		t.Fatalf("run(init) error = %v", err)
+	}
+	if strings.Contains(out, "passphrase-fd") || strings.HasSuffix(out, "fd3") {
+		t.Errorf("init got %q, want no passphrase", out)
+	}
+}
+
+func TestCheckShadow(t *testing.T) {
+	storeDir := t.TempDir()
+	for _, f := range []string{"api/key.gpg", "api/token.gpg", "database.gpg"} {
+		path := filepath.Join(storeDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &Pass{StoreDir: storeDir}
+
+	t.Run("SecretShadowsDirectory", func(t *testing.T) {
+		err := p.CheckShadow("api")
+		if err == nil {
+			t.Fatal("expected error when a secret would shadow a directory")
+		}
+		for _, child := range []string{"api/key", "api/token"} {
+			if !strings.Contains(err.Error(), child) {
+				t.Errorf("error %q does not list conflicting path %s", err, child)
+			}
+		}
+	})
+
+	t.Run("DirectoryUnderSecret", func(t *testing.T) {
+		err := p.CheckShadow("database/password")
+		if err == nil {
+			t.Fatal("expected error when a parent path is already a secret")
+		}
+		if !strings.Contains(err.Error(), "database is already a secret") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("NoConflict", func(t *testing.T) {
+		for _, name := range []string{"api/new", "database", "other/path"} {
+			if err := p.CheckShadow(name); err != nil {
+				t.Errorf("CheckShadow(%q) = %v, want nil", name, err)
+			}
+		}
+	})
+}
+
+func TestVerboseTraceRedactsStdin(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte("#!/bin/sh\ncat >/dev/null\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStderr := os.Stderr
+	os.Stderr = w
+	SetVerbose(true)
+	defer func() {
+		os.Stderr = oldStderr
+		SetVerbose(false)
+	}()
+
+	p := New(t.TempDir())
+	_, runErr := p.runWithStdin("s3cret-value", "insert", "--multiline", "--force", "--", "db/password")
+	w.Close()
+	os.Stderr = oldStderr
+	if runErr != nil {
+		t.Fatalf("runWithStdin() error = %v", runErr)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, "s3cret-value") {
+		t.Errorf("trace leaked the secret value: %q", out)
+	}
+	for _, want := range []string{"PASSWORD_STORE_DIR=", "pass insert --multiline --force -- db/password", "[stdin redacted]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trace %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestExecRetriesWithFullStdin(t *testing.T) {
+	oldBackoff := gpg.RetryBackoff
+	gpg.RetryBackoff = time.Millisecond
+	defer func() { gpg.RetryBackoff = oldBackoff }()
+
+	binDir := t.TempDir()
+	counter := filepath.Join(binDir, "count")
+	script := `#!/bin/sh
+input=$(cat)
+n=$(cat "` + counter + `" 2>/dev/null || echo 0)
+n=$((n+1))
+echo $n > "` + counter + `"
+if [ $n -eq 1 ]; then
+  echo "gpg: agent refused operation" >&2
+  exit 2
+fi
+printf '%s' "$input"
+`
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	p := New(t.TempDir())
+	p.GPG.Retries = 1
+
+	out, err := p.runWithStdin("value", "insert", "--", "x")
+	if err != nil {
+		t.Fatalf("runWithStdin() error = %v", err)
+	}
+	if out != "value" {
+		t.Errorf("retry got stdin %q, want %q", out, "value")
+	}
+}
+
+func TestListIsSorted(t *testing.T) {
+	storeDir := t.TempDir()
+	// Created out of order; "db-x" sorts before "db/..." by byte value
+	for _, name := range []string{"zeta", "db/user", "alpha", "db-x", "db/password", "api/key"} {
+		path := filepath.Join(storeDir, name+".gpg")
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &Pass{StoreDir: storeDir}
+	got, err := p.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := []string{"alpha", "api/key", "db-x", "db/password", "db/user", "zeta"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestSetRecipients(t *testing.T) {
+	storeDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(storeDir, ".gpg-id"), []byte("alice@example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A gpg binary that does not exist proves nothing is encrypted
+	p := &Pass{StoreDir: storeDir, GPG: gpg.New(filepath.Join(storeDir, "no-such-gpg"))}
+	if err := p.SetRecipients([]string{"alice@example.com", "bob@example.com"}); err != nil {
+		t.Fatalf("SetRecipients() error = %v", err)
+	}
+	got, err := p.GetGPGIDs()
+	if err != nil {
+		t.Fatalf("GetGPGIDs() error = %v", err)
+	}
+	if strings.Join(got, ",") != "alice@example.com,bob@example.com" {
+		t.Errorf(".gpg-id = %v, want alice@example.com and bob@example.com", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(storeDir, "db.gpg"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetRecipients([]string{"bob@example.com"}); err == nil {
+		t.Error("expected error for a store with secrets")
+	}
+	if got, _ := p.GetGPGIDs(); len(got) != 2 {
+		t.Errorf(".gpg-id was changed for a store with secrets: %v", got)
+	}
+}
+
+func TestGPGIDsFor(t *testing.T) {
+	storeDir := t.TempDir()
+	files := map[string]string{
+		".gpg-id":               "alice@example.com\n",
+		"admin/.gpg-id":         "bob@example.com\n",
+		"admin/root/token.gpg":  "",
+		"admin/key.gpg":         "",
+		"db/password.gpg":       "",
+		"administrator/key.gpg": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(storeDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &Pass{StoreDir: storeDir}
+	tests := map[string]string{
+		"admin/root/token":  "bob@example.com",
+		"admin/key":         "bob@example.com",
+		"db/password":       "alice@example.com",
+		"administrator/key": "alice@example.com",
+		"top":               "alice@example.com",
+	}
+	for secret, want := range tests {
+		got, err := p.GPGIDsFor(secret)
+		if err != nil {
+			t.Fatalf("GPGIDsFor(%s) error = %v", secret, err)
+		}
+		if strings.Join(got, ",") != want {
+			t.Errorf("GPGIDsFor(%s) = %v, want %s", secret, got, want)
+		}
+	}
+}
+
+func TestInitPathEmptySubtree(t *testing.T) {
+	storeDir := t.TempDir()
+
+	// No secrets below the path, so neither pass nor gpg is needed
+	p := &Pass{StoreDir: storeDir, GPG: gpg.New(filepath.Join(storeDir, "no-such-gpg"))}
+	if err := p.InitPath("admin", []string{"bob@example.com"}); err != nil {
+		t.Fatalf("InitPath() error = %v", err)
+	}
+	got, err := p.PathGPGIDs("admin")
+	if err != nil || strings.Join(got, ",") != "bob@example.com" {
+		t.Errorf("admin/.gpg-id = %v, %v, want bob@example.com", got, err)
+	}
+
+	if err := p.InitPath("admin", nil); err != nil {
+		t.Fatalf("InitPath() to remove error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, "admin", ".gpg-id")); !os.IsNotExist(err) {
+		t.Errorf("admin/.gpg-id still exists after removing it: %v", err)
+	}
+}
+
+func TestInitPathOutsideStore(t *testing.T) {
+	root := t.TempDir()
+	storeDir := filepath.Join(root, "vaults", "dev", ".password-store")
+	if err := os.MkdirAll(storeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Pass{StoreDir: storeDir, GPG: gpg.New(filepath.Join(storeDir, "no-such-gpg"))}
+	for _, path := range []string{"../../x", "admin/../../../x", "/tmp/x"} {
+		if err := p.InitPath(path, []string{"mallory@example.com"}); err == nil {
+			t.Errorf("InitPath(%q) should be refused", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "vaults", "x", ".gpg-id")); !os.IsNotExist(err) {
+		t.Errorf(".gpg-id written outside the store: %v", err)
+	}
+}
+
+func TestExistsDoesNotDecrypt(t *testing.T) {
+	storeDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(storeDir, "db"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "db", "password.gpg"), []byte("not a gpg message"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A gpg binary that does not exist makes any decryption attempt fail
+	p := &Pass{StoreDir: storeDir, GPG: gpg.New(filepath.Join(storeDir, "no-such-gpg"))}
+	if !p.Exists("db/password") {
+		t.Error("Exists(db/password) = false for an undecryptable secret, want true")
+	}
+	if p.Exists("db") {
+		t.Error("Exists(db) = true for a directory, want false")
+	}
+	if p.Exists("db/missing") {
+		t.Error("Exists(db/missing) = true, want false")
+	}
+}
+
+func TestShowBatch(t *testing.T) {
+	binDir := t.TempDir()
+	// Fake pass: "pass show -- <name>" prints "value of <name>"
+	script := "#!/bin/sh\nprintf 'value of %s' \"$3\"\n"
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	names := []string{"a", "b/c", "d", "e/f/g", "h", "i"}
+	p := New(t.TempDir())
+	got, err := p.ShowBatch(names)
+	if err != nil {
+		t.Fatalf("ShowBatch() error = %v", err)
+	}
+	if len(got) != len(names) {
+		t.Fatalf("ShowBatch() returned %d values, want %d", len(got), len(names))
+	}
+	for _, name := range names {
+		if got[name] != "value of "+name {
+			t.Errorf("ShowBatch()[%q] = %q", name, got[name])
+		}
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte("#!/bin/sh\nsleep 30\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	gpg.SetTimeout(100 * time.Millisecond)
+	defer gpg.SetTimeout(0)
+
+	_, err := New(t.TempDir()).Show("db/password")
+	if !errors.Is(err, gpg.ErrTimeout) {
+		t.Fatalf("Show() error = %v, want gpg.ErrTimeout", err)
+	}
+}
+
+func TestSetField(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"new secret", "", "\nusername: admin"},
+		{"password only", "hunter2", "hunter2\nusername: admin"},
+		{"update in place", "hunter2\nusername: root\nhost: db", "hunter2\nusername: admin\nhost: db"},
+		{"append after notes", "hunter2\nsome notes\nhost: db\n", "hunter2\nsome notes\nhost: db\nusername: admin"},
+		{"field-only secret", "\nhost: db", "\nhost: db\nusername: admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := setField(tt.content, "username", "admin")
+			if got != tt.want {
+				t.Errorf("setField() = %q, want %q", got, tt.want)
+			}
+			if password, _ := ParseFields(got); tt.content != "" && password != strings.Split(tt.content, "\n")[0] {
+				t.Errorf("password changed to %q", password)
+			}
+		})
+	}
+}
+
+func TestEnableCache(t *testing.T) {
+	binDir := t.TempDir()
+	countFile := filepath.Join(binDir, "count")
+	// Fake pass: counts "show" calls and prints a value for the name
+	script := "#!/bin/sh\nif [ \"$1\" = show ]; then echo x >> " + countFile + "; printf 'value of %s' \"$3\"; fi\n"
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	shows := func() int {
+		data, _ := os.ReadFile(countFile)
+		return strings.Count(string(data), "x")
+	}
+
+	p := New(t.TempDir())
+	p.EnableCache()
+	for i := 0; i < 3; i++ {
+		if value, err := p.Show("db/password"); err != nil || value != "value of db/password" {
+			t.Fatalf("Show() = %q, %v", value, err)
+		}
+	}
+	if shows() != 1 {
+		t.Errorf("pass show ran %d times, want 1", shows())
+	}
+
+	if err := p.Remove("db"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := p.Show("db/password"); err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	if shows() != 2 {
+		t.Errorf("pass show ran %d times after a write, want 2", shows())
+	}
+}
+
+func TestListCached(t *testing.T) {
+	binDir := t.TempDir()
+	// Fake pass: rm deletes the secret's file
+	script := "#!/bin/sh\nif [ \"$1\" = rm ]; then rm -f \"$PASSWORD_STORE_DIR/$4.gpg\"; fi\n"
+	if err := os.WriteFile(filepath.Join(binDir, "pass"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pass: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	storeDir := t.TempDir()
+	write := func(name string) {
+		if err := os.WriteFile(filepath.Join(storeDir, name+".gpg"), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a")
+	write("b")
+
+	p := New(storeDir)
+	if got, _ := p.ListCached(); strings.Join(got, ",") != "a,b" {
+		t.Fatalf("ListCached() = %v", got)
+	}
+
+	// Changes behind p's back are not seen until List walks again
+	write("c")
+	if got, _ := p.ListCached(); strings.Join(got, ",") != "a,b" {
+		t.Errorf("ListCached() after an outside change = %v, want the cached a,b", got)
+	}
+	if got, _ := p.List(); strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("List() = %v, want a,b,c", got)
+	}
+
+	// Writes through p are seen
+	if err := p.Remove("a"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if got, _ := p.ListCached(); strings.Join(got, ",") != "b,c" {
+		t.Errorf("ListCached() after Remove = %v, want b,c", got)
+	}
+
+	// Callers may modify what they get
+	got, _ := p.ListCached()
+	got[0] = "changed"
+	if again, _ := p.ListCached(); again[0] != "b" {
+		t.Errorf("ListCached() returned the cache itself: %v", again)
+	}
+}
+
+func TestReInitParallel(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available in PATH")
+	}
+	oldEmail, newEmail, subEmail := "test@example.com", "other-recipient@example.com", "sub-recipient@example.com"
+	for _, email := range []string{oldEmail, newEmail, subEmail} {
+		generateTestKey(t, email)
+	}
+
+	storeDir := t.TempDir()
+	p := &Pass{StoreDir: storeDir}
+	if err := p.WriteGPGIDs([]string{oldEmail}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(storeDir, "admin"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "admin", ".gpg-id"), []byte(subEmail+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{
+		"a":           "one",
+		"b":           "two\nlines",
+		"db/password": "no newline at the end",
+		"admin/token": "subvault",
+	}
+	for name, value := range values {
+		recipient := oldEmail
+		if strings.HasPrefix(name, "admin/") {
+			recipient = subEmail
+		}
+		path := filepath.Join(storeDir, name+".gpg")
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command("gpg", "--batch", "--yes", "--encrypt", "--recipient", recipient, "--output", path)
+		cmd.Stdin = strings.NewReader(value)
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("Failed to create encrypted file: %v", err)
+		}
+	}
+
+	if err := p.ReInitParallel([]string{oldEmail, newEmail}, 3); err != nil {
+		t.Fatalf("ReInitParallel() error = %v", err)
+	}
+	if ids, err := p.GPGIDsFor("a"); err != nil || strings.Join(ids, ",") != oldEmail+","+newEmail {
+		t.Errorf(".gpg-id = %v, %v", ids, err)
+	}
+	for name, value := range values {
+		want := []string{oldEmail, newEmail}
+		if strings.HasPrefix(name, "admin/") {
+			want = []string{subEmail}
+		}
+		if err := p.VerifyEncryption(name, want); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		got, err := p.gpgTool().Decrypt(filepath.Join(storeDir, name+".gpg"))
+		if err != nil || string(got) != value {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, value)
+		}
+	}
+
+	// A secret that cannot be decrypted fails the whole operation, but
+	// only after the others were re-encrypted
+	if err := os.WriteFile(filepath.Join(storeDir, "broken.gpg"), []byte("not encrypted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p = &Pass{StoreDir: storeDir}
+	err := p.ReInitParallel([]string{oldEmail}, 2)
+	if err == nil || !strings.Contains(err.Error(), "1 of 5") || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("ReInitParallel() with a broken secret error = %v", err)
+	}
+	if err := p.VerifyEncryption("a", []string{oldEmail}); err != nil {
+		t.Errorf("a was not re-encrypted: %v", err)
+	}
+	// The old .gpg-id stays until every secret has the new recipients
+	if ids, err := p.GetGPGIDs(); err != nil || strings.Join(ids, ",") != oldEmail+","+newEmail {
+		t.Errorf(".gpg-id after a failure = %v, %v, want it unchanged", ids, err)
+	}
+	entries, _ := os.ReadDir(storeDir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".reencrypt-") {
+			t.Errorf("temporary file left behind: %s", e.Name())
+		}
+	}
+
+	// The options pass would encrypt with reach gpg
+	if err := os.Remove(filepath.Join(storeDir, "broken.gpg")); err != nil {
+		t.Fatal(err)
+	}
+	p = &Pass{StoreDir: storeDir, VaultGPGOpts: []string{"--cipher-algo", "NOT-A-CIPHER"}}
+	if err := p.ReInitParallel([]string{oldEmail}, 2); err == nil {
+		t.Error("ReInitParallel() ignored the vault's gpg options")
+	}
+	p.VaultGPGOpts = []string{"--compress-algo", "none"}
+	if err := p.ReInitParallel([]string{oldEmail}, 2); err != nil {
+		t.Errorf("ReInitParallel() with valid gpg options error = %v", err)
+	}
+
+	// With a passphrase ReInit and InitPath re-encrypt with gpg, never
+	// through pass init
+	p = &Pass{StoreDir: storeDir, Passphrase: "unused"}
+	if err := p.ReInit([]string{newEmail}); err != nil {
+		t.Fatalf("ReInit() with a passphrase error = %v", err)
+	}
+	if err := p.VerifyEncryption("a", []string{newEmail}); err != nil {
+		t.Errorf("a after ReInit(): %v", err)
+	}
+	if err := p.InitPath("admin", []string{newEmail}); err != nil {
+		t.Fatalf("InitPath() with a passphrase error = %v", err)
+	}
+	if err := p.VerifyEncryption("admin/token", []string{newEmail}); err != nil {
+		t.Errorf("admin/token after InitPath(): %v", err)
+	}
+
+	// Neither does InitPath change a subdirectory's .gpg-id on failure
+	if err := os.WriteFile(filepath.Join(storeDir, "admin", "broken.gpg"), []byte("not encrypted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.InitPath("admin", []string{subEmail}); err == nil {
+		t.Fatal("InitPath() with a broken secret: expected an error")
+	}
+	if ids, err := p.PathGPGIDs("admin"); err != nil || strings.Join(ids, ",") != newEmail {
+		t.Errorf("admin/.gpg-id after a failure = %v, %v, want it unchanged", ids, err)
+	}
+}
+
+func TestShowWithoutPass(t *testing.T) {
+	gpgPath, err := exec.LookPath("gpg")
+	if err != nil {
+		t.Skip("gpg not available in PATH")
+	}
+	keyEmail := "test@example.com"
+	generateTestKey(t, keyEmail)
+
+	storeDir := t.TempDir()
+	cmd := exec.Command("gpg", "--batch", "--yes", "--encrypt", "--recipient", keyEmail, "--output", filepath.Join(storeDir, "db", "password.gpg"))
+	if err := os.MkdirAll(filepath.Join(storeDir, "db"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	cmd.Stdin = strings.NewReader("hunter2\n\n")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to create encrypted file: %v", err)
+	}
+
+	// Only gpg is on PATH, so Show decrypts the file itself
+	binDir := t.TempDir()
+	if err := os.Symlink(gpgPath, filepath.Join(binDir, "gpg")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+	if _, err := exec.LookPath("pass"); err == nil {
+		t.Fatal("pass is still on PATH")
+	}
+
+	p := &Pass{StoreDir: storeDir}
+	got, err := p.Show("db/password")
+	if err != nil || got != "hunter2" {
+		t.Errorf("Show() = %q, %v, want %q trimmed like pass output", got, err, "hunter2")
+	}
+	if _, err := p.Show("db/missing"); err == nil {
+		t.Error("Show() of a missing secret: expected an error")
+	}
+}
+
+func TestShowRaw(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available in PATH")
+	}
+	keyEmail := "test@example.com"
+	generateTestKey(t, keyEmail)
+
+	storeDir := t.TempDir()
+	value := "  keystore\x00data \n\n"
+	cmd := exec.Command("gpg", "--batch", "--yes", "--encrypt", "--recipient", keyEmail, "--output", filepath.Join(storeDir, "tls.gpg"))
+	cmd.Stdin = strings.NewReader(value)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to create encrypted file: %v", err)
+	}
+
+	p := &Pass{StoreDir: storeDir}
+	got, err := p.ShowRaw("tls")
+	if err != nil || string(got) != value {
+		t.Errorf("ShowRaw() = %q, %v, want %q", got, err, value)
+	}
+	if _, err := p.ShowRaw("missing"); err == nil {
+		t.Error("ShowRaw() of a missing secret succeeded")
+	}
+}
+
+func TestListSymlinks(t *testing.T) {
+	outside := t.TempDir()
+	storeDir := t.TempDir()
+	for _, file := range []string{
+		filepath.Join(outside, "stolen.gpg"),
+		filepath.Join(outside, "dir", "stolen.gpg"),
+		filepath.Join(storeDir, "db", "password.gpg"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"escape":      filepath.Join(outside, "dir"),
+		"escape.gpg":  filepath.Join(outside, "stolen.gpg"),
+		"alias":       filepath.Join(storeDir, "db"),
+		"inner.gpg":   filepath.Join("db", "password.gpg"),
+		"db/loop":     filepath.Join(storeDir, "db"),
+		"db/dangling": filepath.Join(storeDir, "missing"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(storeDir, link)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	p := &Pass{StoreDir: storeDir}
+	got, err := p.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if want := "db/password,inner"; strings.Join(got, ",") != want {
+		t.Errorf("List() = %v, want %s", got, want)
+	}
+
+	p = &Pass{StoreDir: storeDir, FollowSymlinks: true}
+	got, err = p.List()
+	if err != nil {
+		t.Fatalf("List() with FollowSymlinks error = %v", err)
+	}
+	if want := "alias/password,db/password,inner"; strings.Join(got, ",") != want {
+		t.Errorf("List() with FollowSymlinks = %v, want %s", got, want)
+	}
+}
+
+func TestListSymlinkCycle(t *testing.T) {
+	storeDir := t.TempDir()
+	for _, file := range []string{"a/one.gpg", "b/two.gpg"} {
+		path := filepath.Join(storeDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Neither link points above its own directory, but together they loop
+	for link, target := range map[string]string{"a/l": "../b", "b/l": "../a"} {
+		if err := os.Symlink(target, filepath.Join(storeDir, link)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	p := &Pass{StoreDir: storeDir, FollowSymlinks: true}
+	got, err := p.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if want := "a/l/two,a/one,b/l/one,b/two"; strings.Join(got, ",") != want {
+		t.Errorf("List() = %v, want %s", got, want)
+	}
+}
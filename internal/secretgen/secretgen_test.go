@@ -0,0 +1,53 @@
+package secretgen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		policy  string
+		pattern string
+	}{
+		{"pin", `^[0-9]{6}$`},
+		{"token", `^[0-9a-f]{40}$`},
+		{"strong", `^[A-Za-z0-9!#$%&*+\-=?@^_~]{24}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			re := regexp.MustCompile(tt.pattern)
+			seen := map[string]bool{}
+			for i := 0; i < 20; i++ {
+				value, err := Generate(tt.policy)
+				if err != nil {
+					t.Fatalf("Generate(%q) error = %v", tt.policy, err)
+				}
+				if !re.MatchString(value) {
+					t.Errorf("Generate(%q) = %q, does not match %s", tt.policy, value, tt.pattern)
+				}
+				if !hasEveryClass(value, Policies[tt.policy].Classes) {
+					t.Errorf("Generate(%q) = %q, missing a character class", tt.policy, value)
+				}
+				seen[value] = true
+			}
+			if tt.policy != "pin" && len(seen) < 20 {
+				t.Errorf("Generate(%q) produced duplicate values", tt.policy)
+			}
+		})
+	}
+}
+
+func TestGenerateUnknownPolicy(t *testing.T) {
+	_, err := Generate("weak")
+	if err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+	for _, name := range Names() {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not list policy %s", err, name)
+		}
+	}
+}